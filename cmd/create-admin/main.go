@@ -0,0 +1,56 @@
+// Command create-admin membuat user admin pertama agar role tidak perlu
+// diubah manual di MongoDB. Hanya berjalan jika belum ada admin:
+//
+//	go run ./cmd/create-admin -username admin -email admin@example.com
+//
+// Password dibaca dari env BOOTSTRAP_ADMIN_PASSWORD agar tidak tersimpan di
+// riwayat shell. Jika username sudah terdaftar, user tersebut dijadikan admin.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"transport-app/config"
+	"transport-app/mailer"
+	"transport-app/repository"
+	"transport-app/store"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	username := flag.String("username", "", "username admin")
+	email := flag.String("email", "", "email admin, wajib jika user belum terdaftar")
+	flag.Parse()
+
+	if os.Getenv("RAILWAY_ENVIRONMENT") == "" {
+		if err := godotenv.Load(); err != nil {
+			log.Println("Gagal memuat file .env")
+		}
+	}
+
+	if *username == "" {
+		log.Fatal("❌ -username wajib diisi")
+	}
+
+	config.ConnectDB()
+	if config.DB == nil {
+		log.Fatal("❌ Tidak dapat terhubung ke MongoDB")
+	}
+
+	ctx := context.Background()
+	// Command ini tidak menerbitkan token sehingga tidak butuh kunci JWT
+	handler := repository.NewHandler(store.NewMongoStores(config.DB), mailer.FromEnv(), nil)
+	if err := handler.SeedRoles(ctx); err != nil {
+		log.Fatal("❌ Gagal membuat role bawaan: ", err)
+	}
+
+	user, err := handler.BootstrapAdmin(ctx, *username, *email, os.Getenv("BOOTSTRAP_ADMIN_PASSWORD"))
+	if err != nil {
+		log.Fatal("❌ Gagal membuat admin: ", err)
+	}
+	log.Printf("✅ %s (%s) sekarang admin", user.Username, user.ID.Hex())
+}
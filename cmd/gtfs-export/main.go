@@ -0,0 +1,75 @@
+// Command gtfs-export menulis feed GTFS static dari data di MongoDB ke file
+// zip. Feed divalidasi dulu; jika tidak valid, daftar masalahnya dicetak
+// dan tidak ada file yang ditulis:
+//
+//	go run ./cmd/gtfs-export -out gtfs.zip -dari 2025-01-01 -hari 90
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"transport-app/config"
+	"transport-app/mailer"
+	"transport-app/repository"
+	"transport-app/store"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	out := flag.String("out", "gtfs.zip", "file zip tujuan")
+	dari := flag.String("dari", "", "tanggal awal jadwal YYYY-MM-DD, default hari ini")
+	hari := flag.Int("hari", 60, "jumlah hari jadwal yang diekspor")
+	flag.Parse()
+
+	if os.Getenv("RAILWAY_ENVIRONMENT") == "" {
+		if err := godotenv.Load(); err != nil {
+			log.Println("Gagal memuat file .env")
+		}
+	}
+
+	var start time.Time
+	if *dari != "" {
+		t, err := time.Parse("2006-01-02", *dari)
+		if err != nil {
+			log.Fatal("❌ -dari harus berformat YYYY-MM-DD")
+		}
+		start = t
+	}
+	if *hari <= 0 {
+		log.Fatal("❌ -hari harus lebih dari 0")
+	}
+
+	config.ConnectDB()
+	if config.DB == nil {
+		log.Fatal("❌ Tidak dapat terhubung ke MongoDB")
+	}
+
+	// Command ini tidak menerbitkan token sehingga tidak butuh kunci JWT
+	handler := repository.NewHandler(store.NewMongoStores(config.DB), mailer.FromEnv(), nil)
+
+	var buf bytes.Buffer
+	warnings, issues, err := handler.ExportGTFS(context.Background(), &buf, start, *hari)
+	if err != nil {
+		log.Fatal("❌ Gagal membuat feed GTFS: ", err)
+	}
+	for _, w := range warnings {
+		log.Println("⚠️", w)
+	}
+	if len(issues) > 0 {
+		for _, issue := range issues {
+			log.Println("   -", issue.Error())
+		}
+		log.Fatalf("❌ Feed GTFS tidak valid: %d masalah", len(issues))
+	}
+
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		log.Fatal("❌ Gagal menulis file: ", err)
+	}
+	log.Printf("✅ Feed GTFS ditulis ke %s (%d byte)", *out, buf.Len())
+}
@@ -0,0 +1,103 @@
+// Command gtfs-import membuat atau memperbarui halte, rute dan jadwal di
+// MongoDB dari feed GTFS static. Gunakan -dry-run untuk melihat perubahan
+// tanpa menyimpan; feed dengan masalah tidak disimpan sama sekali:
+//
+//	go run ./cmd/gtfs-import -file gtfs.zip -dry-run -nomor-polisi "B 1234 CD"
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"transport-app/config"
+	"transport-app/gtfs"
+	"transport-app/mailer"
+	"transport-app/repository"
+	"transport-app/store"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	file := flag.String("file", "gtfs.zip", "file zip GTFS")
+	dryRun := flag.Bool("dry-run", false, "hanya laporkan perubahan tanpa menyimpan")
+	dari := flag.String("dari", "", "tanggal awal jadwal YYYY-MM-DD, default hari ini")
+	hari := flag.Int("hari", 60, "jumlah hari jadwal yang dibuat")
+	nomorPolisi := flag.String("nomor-polisi", "", "kendaraan untuk trip tanpa block_id yang cocok")
+	flag.Parse()
+
+	if os.Getenv("RAILWAY_ENVIRONMENT") == "" {
+		if err := godotenv.Load(); err != nil {
+			log.Println("Gagal memuat file .env")
+		}
+	}
+
+	opts := repository.GTFSImportOptions{Hari: *hari, DryRun: *dryRun, NomorPolisi: *nomorPolisi}
+	if *dari != "" {
+		t, err := time.Parse("2006-01-02", *dari)
+		if err != nil {
+			log.Fatal("❌ -dari harus berformat YYYY-MM-DD")
+		}
+		opts.Dari = t
+	}
+	if *hari <= 0 {
+		log.Fatal("❌ -hari harus lebih dari 0")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal("❌ Gagal membuka file: ", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		log.Fatal("❌ Gagal membaca file: ", err)
+	}
+
+	config.ConnectDB()
+	if config.DB == nil {
+		log.Fatal("❌ Tidak dapat terhubung ke MongoDB")
+	}
+
+	// Command ini tidak menerbitkan token sehingga tidak butuh kunci JWT
+	handler := repository.NewHandler(store.NewMongoStores(config.DB), mailer.FromEnv(), nil)
+
+	result, err := handler.ImportGTFS(context.Background(), f, info.Size(), opts)
+	if errors.Is(err, gtfs.ErrNotZip) {
+		log.Fatal("❌ ", err)
+	}
+	for _, w := range result.Warnings {
+		log.Println("⚠️", w)
+	}
+	for _, c := range result.Perubahan {
+		log.Printf("   %s %s %s", c.Jenis, c.Kode, c.Aksi)
+	}
+	if err != nil {
+		// Perubahan yang sudah tersimpan dibatalkan kembali; jika pembatalan
+		// juga gagal, impor ulang feed yang sama akan melanjutkan karena data
+		// dicocokkan berdasarkan kode
+		log.Fatal("❌ Gagal menyimpan impor GTFS: ", err)
+	}
+	if len(result.Issues) > 0 {
+		for _, issue := range result.Issues {
+			log.Println("   -", issue.Error())
+		}
+		log.Fatalf("❌ Feed GTFS tidak valid: %d masalah, tidak ada yang disimpan", len(result.Issues))
+	}
+
+	summary := "Halte %d dibuat, %d diubah; rute %d dibuat, %d diubah; jadwal %d dibuat, %d diubah"
+	args := []interface{}{
+		result.Halte.Dibuat, result.Halte.Diubah,
+		result.Rute.Dibuat, result.Rute.Diubah,
+		result.Jadwal.Dibuat, result.Jadwal.Diubah,
+	}
+	if result.DryRun {
+		log.Printf("🔎 Dry run, tidak ada yang disimpan. "+summary, args...)
+		return
+	}
+	log.Printf("✅ "+summary, args...)
+}
@@ -0,0 +1,43 @@
+// Command migrate-jadwal-waktu mengonversi tanggal dan waktu jadwal lama
+// yang tersimpan sebagai string menjadi BSON date. Jalankan sekali setelah
+// deploy: go run ./cmd/migrate-jadwal-waktu
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"transport-app/config"
+	"transport-app/mailer"
+	"transport-app/repository"
+	"transport-app/store"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if os.Getenv("RAILWAY_ENVIRONMENT") == "" {
+		if err := godotenv.Load(); err != nil {
+			log.Println("Gagal memuat file .env")
+		}
+	}
+
+	config.ConnectDB()
+	if config.DB == nil {
+		log.Fatal("❌ Tidak dapat terhubung ke MongoDB")
+	}
+
+	// Command ini tidak menerbitkan token sehingga tidak butuh kunci JWT
+	handler := repository.NewHandler(store.NewMongoStores(config.DB), mailer.FromEnv(), nil)
+
+	migrated, failed, err := handler.MigrateJadwalWaktu(context.Background())
+	if err != nil {
+		log.Fatal("❌ Migrasi gagal: ", err)
+	}
+
+	log.Printf("✅ %d jadwal dimigrasi, %d dilewati", migrated, len(failed))
+	for _, id := range failed {
+		log.Println("   -", id)
+	}
+}
@@ -22,31 +22,48 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/jadwals": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Kunci publik (RS256/EdDSA) untuk memverifikasi access token, dikenali lewat kid di header token. Kunci pengganti dipublikasikan sebelum dipakai. Kosong jika server memakai HS256",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "Daftar kunci publik",
+                        "schema": {
+                            "$ref": "#/definitions/jwtkeys.JWKS"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/api-keys": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengambil semua data jadwal beserta detail rutenya",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mengambil semua API key termasuk yang sudah dicabut. Key lengkap tidak pernah dikembalikan, hanya prefix-nya",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Jadwal"
+                    "APIKey"
                 ],
-                "summary": "Get all jadwal",
+                "summary": "List API keys",
                 "responses": {
                     "200": {
-                        "description": "Daftar semua jadwal",
+                        "description": "Daftar API key",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/repository.JadwalWithRute"
+                                "$ref": "#/definitions/models.APIKey"
                             }
                         }
                     },
@@ -56,6 +73,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -70,7 +93,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Membuat data jadwal baru (Admin Only)",
+                "description": "Membuat API key untuk agen tiket atau kios. Key dikirim di header X-API-Key dan hanya berlaku untuk endpoint baca sesuai scope. Simpan key dari respons ini karena tidak bisa ditampilkan lagi",
                 "consumes": [
                     "application/json"
                 ],
@@ -78,29 +101,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Jadwal"
+                    "APIKey"
                 ],
-                "summary": "Create a new jadwal",
+                "summary": "Create an API key",
                 "parameters": [
                     {
-                        "description": "Data jadwal baru",
-                        "name": "jadwal",
+                        "description": "Data API key",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Jadwal"
+                            "$ref": "#/definitions/repository.CreateAPIKeyRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Jadwal berhasil dibuat",
+                        "description": "API key dibuat",
                         "schema": {
-                            "$ref": "#/definitions/models.Jadwal"
+                            "$ref": "#/definitions/repository.APIKeyCreatedResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request - data tidak valid",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -112,13 +135,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden - Admin access required",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Rute or Kendaraan not found",
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -132,44 +149,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/jadwals/{id}": {
+        "/api/admin/api-keys/scopes": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengambil data jadwal berdasarkan ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mengambil daftar scope yang bisa diberikan ke API key",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Jadwal"
-                ],
-                "summary": "Get a jadwal by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Jadwal ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "APIKey"
                 ],
+                "summary": "List API key scopes",
                 "responses": {
                     "200": {
-                        "description": "Data jadwal yang ditemukan",
-                        "schema": {
-                            "$ref": "#/definitions/models.Jadwal"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid ID",
+                        "description": "Daftar scope",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
                         }
                     },
                     "401": {
@@ -178,58 +180,48 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Jadwal not found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/admin/api-keys/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Memperbarui data jadwal yang ada (Admin Only)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mencabut API key sehingga langsung tidak bisa dipakai lagi",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Jadwal"
+                    "APIKey"
                 ],
-                "summary": "Update an existing jadwal",
+                "summary": "Revoke an API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Jadwal ID",
+                        "description": "API key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Data jadwal yang akan diupdate",
-                        "name": "jadwal",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Jadwal"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Data jadwal diupdate",
+                        "description": "API key dicabut",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID atau Bad Request",
+                        "description": "Invalid ID",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -241,13 +233,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden - Admin access required",
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Rute not found",
+                        "description": "API key tidak ditemukan atau sudah dicabut",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -259,46 +251,39 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/admin/lockouts/ip/{ip}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Menghapus data jadwal berdasarkan ID (Admin Only)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Menghapus hitungan login gagal dan kunci login untuk satu alamat IP",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Jadwal"
+                    "User"
                 ],
-                "summary": "Delete a jadwal",
+                "summary": "Unlock an IP address",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Jadwal ID",
-                        "name": "id",
+                        "description": "Alamat IP",
+                        "name": "ip",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Jadwal berhasil dihapus",
+                        "description": "Kunci login dibuka",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid ID",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
-                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
@@ -306,7 +291,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden - Admin access required",
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -320,31 +305,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/kendaraans": {
+        "/api/admin/permissions": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengambil semua data kendaraan",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mengambil daftar permission yang bisa diberikan ke role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Kendaraan"
+                    "Role"
                 ],
-                "summary": "Get all kendaraan",
+                "summary": "List permissions",
                 "responses": {
                     "200": {
-                        "description": "Daftar semua kendaraan",
+                        "description": "Daftar permission",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Kendaraan"
+                                "type": "string"
                             }
                         }
                     },
@@ -354,53 +336,38 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/api/admin/roles": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Membuat data kendaraan baru (Admin Only)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mengambil semua role beserta permission-nya",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Kendaraan"
-                ],
-                "summary": "Create a new kendaraan",
-                "parameters": [
-                    {
-                        "description": "Data kendaraan baru",
-                        "name": "kendaraan",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Kendaraan"
-                        }
-                    }
+                    "Role"
                 ],
+                "summary": "List roles",
                 "responses": {
-                    "201": {
-                        "description": "Kendaraan berhasil dibuat",
-                        "schema": {
-                            "$ref": "#/definitions/models.Kendaraan"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request - data tidak valid",
+                    "200": {
+                        "description": "Daftar role",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Role"
+                            }
                         }
                     },
                     "401": {
@@ -410,7 +377,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden - Admin access required",
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -422,16 +389,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/kendaraans/{id}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengambil data kendaraan berdasarkan ID",
+                "description": "Membuat role baru dengan kumpulan permission. Hanya permission yang dimiliki role pemanggil yang bisa diberikan",
                 "consumes": [
                     "application/json"
                 ],
@@ -439,27 +404,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Kendaraan"
+                    "Role"
                 ],
-                "summary": "Get a kendaraan by ID",
+                "summary": "Create a role",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Kendaraan ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Data role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.RoleRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Data kendaraan yang ditemukan",
+                    "201": {
+                        "description": "Role berhasil dibuat",
                         "schema": {
-                            "$ref": "#/definitions/models.Kendaraan"
+                            "$ref": "#/definitions/models.Role"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -470,21 +437,35 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Kendaraan not found",
+                    "403": {
+                        "description": "Forbidden atau permission tidak dimiliki",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Role sudah ada",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/admin/roles/{name}": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Memperbarui data kendaraan yang ada (Admin Only)",
+                "description": "Mengubah deskripsi dan permission role. Role admin tidak bisa diubah, dan role pemanggil harus memiliki semua permission lama dan baru",
                 "consumes": [
                     "application/json"
                 ],
@@ -492,36 +473,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Kendaraan"
+                    "Role"
                 ],
-                "summary": "Update an existing kendaraan",
+                "summary": "Update a role",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Kendaraan ID",
-                        "name": "id",
+                        "description": "Nama role",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Data kendaraan yang akan diupdate",
-                        "name": "kendaraan",
+                        "description": "Data role",
+                        "name": "role",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Kendaraan"
+                            "$ref": "#/definitions/repository.RoleRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Data kendaraan diupdate",
+                        "description": "Role diupdate",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.Role"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID atau Bad Request",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -533,7 +514,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden - Admin access required",
+                        "description": "Forbidden atau permission tidak dimiliki",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -552,35 +539,32 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Menghapus data kendaraan berdasarkan ID (Admin Only)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Menghapus role buatan admin yang tidak sedang dipakai user. Role bawaan tidak bisa dihapus",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Kendaraan"
+                    "Role"
                 ],
-                "summary": "Delete a kendaraan",
+                "summary": "Delete a role",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Kendaraan ID",
-                        "name": "id",
+                        "description": "Nama role",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Data kendaraan dihapus",
+                        "description": "Role dihapus",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Role bawaan",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -592,7 +576,19 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden - Admin access required",
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Role masih dipakai user",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -606,45 +602,86 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/login": {
-            "post": {
-                "description": "Login menggunakan username dan password untuk mendapatkan token",
-                "consumes": [
-                    "application/json"
+        "/api/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Mengambil data user dengan pencarian, filter, sort dan pagination. Hash password tidak pernah dikembalikan",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "User"
                 ],
-                "summary": "Login a user",
+                "summary": "List users",
                 "parameters": [
                     {
-                        "description": "User credentials",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/repository.AuthRequest"
-                        }
+                        "type": "string",
+                        "description": "Cari pada username atau email",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Nomor halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah data per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor dari next_cursor halaman sebelumnya",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Urutan, mis. role,-username",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter akun nonaktif",
+                        "name": "disabled",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login successful, token returned",
+                        "description": "Daftar user",
                         "schema": {
-                            "$ref": "#/definitions/repository.LoginResponse"
+                            "$ref": "#/definitions/query.Page-models_PublicUser"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Query tidak valid",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Invalid username or password",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -658,45 +695,113 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/register": {
-            "post": {
-                "description": "Mendaftarkan pengguna baru dengan username dan email",
-                "consumes": [
-                    "application/json"
+        "/api/admin/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Mengambil data satu user tanpa hash password",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "User"
                 ],
-                "summary": "Register a new user",
+                "summary": "Get a user by ID",
                 "parameters": [
                     {
-                        "description": "User credentials",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data user",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.PublicUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus user beserta session dan token miliknya. Booking user tetap disimpan",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "User created successfully",
+                    "200": {
+                        "description": "User dihapus",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid ID atau akun sendiri",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Username or email already exists",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden atau user dengan role *",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -710,32 +815,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/rutes": {
-            "get": {
+        "/api/admin/users/{id}/2fa": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengambil semua data rute",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Menonaktifkan 2FA user yang kehilangan perangkat dan recovery code-nya. Semua session user dicabut",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rute"
+                    "User"
+                ],
+                "summary": "Reset a user's two-factor authentication",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all rutes",
                 "responses": {
                     "200": {
-                        "description": "Daftar semua rute",
+                        "description": "2FA user direset",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Rute"
-                            }
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID atau akun sendiri",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "401": {
@@ -744,6 +858,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden atau user dengan role *",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -751,44 +877,41 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/admin/users/{id}/disable": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Membuat data rute baru (Admin Only)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Menonaktifkan akun user. Semua session user langsung dicabut dan user tidak bisa login sampai diaktifkan lagi",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rute"
+                    "User"
                 ],
-                "summary": "Create a new rute",
+                "summary": "Disable a user",
                 "parameters": [
                     {
-                        "description": "Data rute baru",
-                        "name": "rute",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Rute"
-                        }
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Rute berhasil dibuat",
+                    "200": {
+                        "description": "User dinonaktifkan",
                         "schema": {
-                            "$ref": "#/definitions/models.Rute"
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request - data tidak valid",
+                        "description": "Invalid ID atau akun sendiri",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -800,7 +923,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden - Admin access required",
+                        "description": "Forbidden atau user dengan role *",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -814,28 +943,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/rutes/{id}": {
-            "get": {
+        "/api/admin/users/{id}/enable": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengambil data rute berdasarkan ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mengaktifkan kembali akun user yang dinonaktifkan",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rute"
+                    "User"
                 ],
-                "summary": "Get a rute by ID",
+                "summary": "Enable a user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Rute ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -843,13 +969,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Data rute yang ditemukan",
+                        "description": "User diaktifkan",
                         "schema": {
-                            "$ref": "#/definitions/models.Rute"
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid ID atau akun sendiri",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -860,58 +986,60 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden atau user dengan role *",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "404": {
-                        "description": "Rute not found",
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/admin/users/{id}/reset-password": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Memperbarui data rute yang sudah ada berdasarkan ID (Admin Only)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Memaksa user mengganti password. Semua session user dicabut, login ditolak sampai password direset, dan link reset dikirim ke email user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rute"
+                    "User"
                 ],
-                "summary": "Update an existing rute",
+                "summary": "Force a password reset",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Rute ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Data rute yang akan diupdate",
-                        "name": "rute",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Rute"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Data berhasil diupdate",
+                        "description": "Reset password dipaksa",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID atau Bad Request",
+                        "description": "Invalid ID atau akun sendiri",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -923,7 +1051,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden - Admin access required",
+                        "description": "Forbidden atau user dengan role *",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -935,14 +1069,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/admin/users/{id}/role": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Menghapus data rute berdasarkan ID (Admin Only)",
+                "description": "Mengganti role user. Role baru berlaku setelah user login ulang atau memperbarui token. Role pemanggil harus memiliki semua permission role lama dan role baru user",
                 "consumes": [
                     "application/json"
                 ],
@@ -950,27 +1086,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Rute"
+                    "Role"
                 ],
-                "summary": "Delete a rute",
+                "summary": "Assign a role to a user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Rute ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Nama role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.AssignRoleRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Data berhasil dihapus",
+                        "description": "Role user diubah",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -982,159 +1127,5283 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden - Admin access required",
+                        "description": "Forbidden atau permission tidak dimiliki",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "User or Role not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus hitungan login gagal dan kunci login untuk username user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Unlock a user's login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Kunci login dibuka",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/email/resend": {
+            "post": {
+                "description": "Mengirim ulang email verifikasi. Response selalu sama, terdaftar atau tidak emailnya. Email hanya dikirim sekali per menit untuk setiap akun, dan permintaan dibatasi per IP",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Resend the verification email",
+                "parameters": [
+                    {
+                        "description": "Email akun",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.ResendVerificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email verifikasi dikirim jika akun belum terverifikasi",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak permintaan email verifikasi dari IP ini",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/email/verify": {
+            "post": {
+                "description": "Menandai email user sebagai terverifikasi memakai token dari email pendaftaran",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Verify an email address",
+                "parameters": [
+                    {
+                        "description": "Token verifikasi",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email terverifikasi",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Token tidak valid atau kedaluwarsa",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/gtfs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mengunduh jaringan rute, halte dan jadwal sebagai feed GTFS static (zip berisi agency, routes, stops, trips, stop_times dan calendar). Rute tanpa halte tidak diekspor. Waktu dinyatakan dalam zona GTFS_TIMEZONE (default Asia/Jakarta)",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "GTFS"
+                ],
+                "summary": "Export GTFS feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tanggal awal jadwal (YYYY-MM-DD, default hari ini)",
+                        "name": "dari",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah hari jadwal (default 60, maks 366)",
+                        "name": "hari",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Feed GTFS",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Data tidak menghasilkan feed GTFS yang valid",
+                        "schema": {
+                            "$ref": "#/definitions/repository.GTFSIssueResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/gtfs/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat atau memperbarui halte (stop_id), rute (route_id sebagai kode_rute) dan jadwal dari feed GTFS static. stop_desc dibaca sebagai kota halte, block_id sebagai nomor polisi kendaraan, dan shape_dist_traveled dalam kilometer atau meter (ditentukan dari jarak garis lurus antar halte; jika tidak sebanding, jarak garis lurus dipakai). Satu route hanya boleh memiliki satu urutan halte. Jadwal dibuat dari calendar.txt untuk rentang dari/hari dan dicocokkan dengan impor sebelumnya berdasarkan trip_id dan tanggal layanan; jadwal impor sebelumnya yang trip-nya tidak ada lagi di feed dihapus kecuali sudah dipesan. calendar_dates.txt tidak dibaca. Feed hanya disimpan jika tidak ada issue. Ukuran file maksimal 4 MB, gunakan cmd/gtfs-import untuk feed yang lebih besar",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GTFS"
+                ],
+                "summary": "Import GTFS feed",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Zip GTFS",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Hanya laporkan perubahan tanpa menyimpan",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Kendaraan untuk trip tanpa block_id yang cocok",
+                        "name": "nomor_polisi",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal awal jadwal (YYYY-MM-DD, default hari ini)",
+                        "name": "dari",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah hari jadwal (default 60, maks 366)",
+                        "name": "hari",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Laporan impor",
+                        "schema": {
+                            "$ref": "#/definitions/repository.GTFSImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Kendaraan atau pengemudi sedang dijadwalkan oleh request lain",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Feed tidak valid, tidak ada yang disimpan",
+                        "schema": {
+                            "$ref": "#/definitions/repository.GTFSImportResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/haltes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mengambil data halte dengan filter, sort dan pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Halte"
+                ],
+                "summary": "Get all halte",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cari pada kode atau nama",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Nomor halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah data per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor dari next_cursor halaman sebelumnya",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Urutan, mis. kota,nama",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter kota",
+                        "name": "kota",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar halte",
+                        "schema": {
+                            "$ref": "#/definitions/query.Page-models_Halte"
+                        }
+                    },
+                    "400": {
+                        "description": "Query tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat halte baru. Kode halte harus unik",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Halte"
+                ],
+                "summary": "Create a halte",
+                "parameters": [
+                    {
+                        "description": "Data halte",
+                        "name": "halte",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.HalteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Halte dibuat",
+                        "schema": {
+                            "$ref": "#/definitions/models.Halte"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Kode halte sudah dipakai",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/haltes/nearby": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mencari halte dalam radius tertentu dari sebuah titik, diurutkan dari yang terdekat. Jarak dalam meter",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Halte"
+                ],
+                "summary": "Find nearby halte",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Lintang titik (derajat desimal)",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Bujur titik (derajat desimal)",
+                        "name": "lng",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Radius dalam meter (default 1000, maks 50000)",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah hasil maksimal (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Halte terdekat",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.HalteTerdekat"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/haltes/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mengambil data halte berdasarkan ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Halte"
+                ],
+                "summary": "Get a halte by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Halte ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data halte",
+                        "schema": {
+                            "$ref": "#/definitions/models.Halte"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Halte not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengubah data halte",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Halte"
+                ],
+                "summary": "Update a halte",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Halte ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data halte",
+                        "name": "halte",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.HalteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Halte diupdate",
+                        "schema": {
+                            "$ref": "#/definitions/models.Halte"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Halte not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Kode halte sudah dipakai",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus halte. Halte yang masih dipakai rute tidak bisa dihapus",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Halte"
+                ],
+                "summary": "Delete a halte",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Halte ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Halte dihapus",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Halte not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Halte masih dipakai rute",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/jadwal-templates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil semua template jadwal berulang (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "JadwalTemplate"
+                ],
+                "summary": "Get all jadwal templates",
+                "responses": {
+                    "200": {
+                        "description": "Daftar template jadwal",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.JadwalTemplate"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat template jadwal berulang (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "JadwalTemplate"
+                ],
+                "summary": "Create a jadwal template",
+                "parameters": [
+                    {
+                        "description": "Data template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.JadwalTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Template berhasil dibuat",
+                        "schema": {
+                            "$ref": "#/definitions/models.JadwalTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - data tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Rute or Kendaraan not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/jadwal-templates/generate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat jadwal dari semua template aktif untuk beberapa hari ke depan (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "JadwalTemplate"
+                ],
+                "summary": "Generate jadwal from all active templates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Jumlah hari ke depan (default 14, maks 90)",
+                        "name": "hari",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Hasil generate per template",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/repository.GenerateResult"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/jadwal-templates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil template jadwal berdasarkan ID (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "JadwalTemplate"
+                ],
+                "summary": "Get a jadwal template by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data template",
+                        "schema": {
+                            "$ref": "#/definitions/models.JadwalTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Memperbarui template jadwal. Jadwal yang sudah dibuat tidak ikut berubah (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "JadwalTemplate"
+                ],
+                "summary": "Update a jadwal template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.JadwalTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Template diupdate",
+                        "schema": {
+                            "$ref": "#/definitions/models.JadwalTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID atau Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Template, Rute or Kendaraan not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus template jadwal. Jadwal yang sudah dibuat tetap ada (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "JadwalTemplate"
+                ],
+                "summary": "Delete a jadwal template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Template dihapus",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/jadwal-templates/{id}/generate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat jadwal dari template untuk beberapa hari ke depan. Aman dijalankan berulang kali (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "JadwalTemplate"
+                ],
+                "summary": "Generate jadwal from a template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah hari ke depan (default 14, maks 90)",
+                        "name": "hari",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Hasil generate",
+                        "schema": {
+                            "$ref": "#/definitions/repository.GenerateResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Kendaraan sedang dijadwalkan oleh request lain",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/jadwals": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mengambil data jadwal beserta detail rute dan kendaraannya dengan filter, sort dan pagination. Filter: field=nilai, field[op]=nilai (eq, ne, gt, gte, lt, lte, in) atau tanggal\u003e=2025-01-01. Sort: sort=field,-field",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jadwal"
+                ],
+                "summary": "Get all jadwal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Nomor halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah data per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor dari next_cursor halaman sebelumnya",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Urutan, mis. -waktu_berangkat",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter rute",
+                        "name": "rute_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter kendaraan",
+                        "name": "kendaraan_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar jadwal",
+                        "schema": {
+                            "$ref": "#/definitions/query.Page-models_JadwalWithRute"
+                        }
+                    },
+                    "400": {
+                        "description": "Query tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat data jadwal baru (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jadwal"
+                ],
+                "summary": "Create a new jadwal",
+                "parameters": [
+                    {
+                        "description": "Data jadwal baru",
+                        "name": "jadwal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Jadwal"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Jadwal berhasil dibuat",
+                        "schema": {
+                            "$ref": "#/definitions/models.Jadwal"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - data tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Rute or Kendaraan not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Kendaraan atau pengemudi bentrok dengan jadwal lain",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/jadwals/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mengambil data jadwal berdasarkan ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jadwal"
+                ],
+                "summary": "Get a jadwal by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Jadwal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data jadwal yang ditemukan",
+                        "schema": {
+                            "$ref": "#/definitions/models.Jadwal"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Jadwal not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Memperbarui data jadwal yang ada (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jadwal"
+                ],
+                "summary": "Update an existing jadwal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Jadwal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data jadwal yang akan diupdate",
+                        "name": "jadwal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Jadwal"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data jadwal diupdate",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID atau Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Jadwal or Rute not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Kendaraan atau pengemudi bentrok dengan jadwal lain",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus data jadwal berdasarkan ID. Jadwal yang masih memiliki booking tidak bisa dihapus sebelum booking-nya dibatalkan (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jadwal"
+                ],
+                "summary": "Delete a jadwal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Jadwal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Jadwal berhasil dihapus",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Jadwal not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Jadwal masih memiliki booking",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/jadwals/{id}/bookings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil booking pada jadwal tertentu. Role dengan permission booking:read melihat semua booking, user lain hanya melihat booking miliknya",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Booking"
+                ],
+                "summary": "Get bookings of a jadwal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Jadwal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar booking",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Booking"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Memesan kursi pada jadwal tertentu untuk user yang sedang login. Untuk rute dengan halte, penumpang bisa naik dan turun di halte antara lewat naik_halte dan turun_halte (kode halte)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Booking"
+                ],
+                "summary": "Book seats on a jadwal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Jadwal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Jumlah kursi dan halte naik/turun",
+                        "name": "booking",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.BookingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Booking berhasil dibuat",
+                        "schema": {
+                            "$ref": "#/definitions/models.Booking"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Email belum diverifikasi",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Jadwal or Kendaraan not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Kursi tidak mencukupi atau jadwal sudah berangkat",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/jadwals/{id}/bookings/{bookingId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membatalkan booking dan mengembalikan kursinya. Booking tidak bisa dibatalkan setelah jadwal berangkat. Tanpa permission booking:manage, user hanya bisa membatalkan booking miliknya",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Booking"
+                ],
+                "summary": "Cancel a booking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Jadwal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Booking ID",
+                        "name": "bookingId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Booking dibatalkan",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Jadwal or Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Jadwal sudah berangkat",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/jadwals/{id}/stops": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mengambil waktu tiba dan berangkat jadwal di setiap halte rute, dihitung dari jarak kumulatif dan dwell halte. Rute tanpa halte menghasilkan daftar kosong",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jadwal"
+                ],
+                "summary": "Get stop times of a jadwal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Jadwal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Waktu di setiap halte",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.JadwalStop"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Jadwal or Rute not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/kendaraans": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mengambil data kendaraan dengan filter, sort dan pagination. Filter: field=nilai, field[op]=nilai (eq, ne, gt, gte, lt, lte, in). Sort: sort=field,-field",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kendaraan"
+                ],
+                "summary": "Get all kendaraan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Nomor halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah data per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor dari next_cursor halaman sebelumnya",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Urutan, mis. jenis,-kapasitas",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter jenis kendaraan",
+                        "name": "jenis",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter status kendaraan",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar kendaraan",
+                        "schema": {
+                            "$ref": "#/definitions/query.Page-models_Kendaraan"
+                        }
+                    },
+                    "400": {
+                        "description": "Query tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat data kendaraan baru (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kendaraan"
+                ],
+                "summary": "Create a new kendaraan",
+                "parameters": [
+                    {
+                        "description": "Data kendaraan baru",
+                        "name": "kendaraan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Kendaraan"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Kendaraan berhasil dibuat",
+                        "schema": {
+                            "$ref": "#/definitions/models.Kendaraan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - data tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/kendaraans/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mengambil data kendaraan berdasarkan ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kendaraan"
+                ],
+                "summary": "Get a kendaraan by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Kendaraan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data kendaraan yang ditemukan",
+                        "schema": {
+                            "$ref": "#/definitions/models.Kendaraan"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Kendaraan not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Memperbarui data kendaraan yang ada (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kendaraan"
+                ],
+                "summary": "Update an existing kendaraan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Kendaraan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data kendaraan yang akan diupdate",
+                        "name": "kendaraan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Kendaraan"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data kendaraan diupdate",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID atau Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Kendaraan not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus data kendaraan berdasarkan ID (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kendaraan"
+                ],
+                "summary": "Delete a kendaraan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Kendaraan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data kendaraan dihapus",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Kendaraan not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/login": {
+            "post": {
+                "description": "Login menggunakan username dan password untuk mendapatkan access token dan refresh token. Jika user mengaktifkan 2FA, response berisi challenge_token (TwoFactorChallengeResponse) yang diselesaikan lewat /api/login/2fa. Login gagal berulang per username dan per IP dikunci sementara dengan jeda yang makin lama",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login a user",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.AuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful, token returned",
+                        "schema": {
+                            "$ref": "#/definitions/repository.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid username or password",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Email belum diverifikasi, akun dinonaktifkan atau password harus direset",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak percobaan login gagal",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/login/2fa": {
+            "post": {
+                "description": "Langkah kedua login untuk user dengan 2FA. Tukar challenge_token dari /api/login dan kode TOTP (atau recovery code) dengan access token dan refresh token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Complete a two-factor login",
+                "parameters": [
+                    {
+                        "description": "Challenge token dan kode",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.TwoFactorLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login berhasil",
+                        "schema": {
+                            "$ref": "#/definitions/repository.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Challenge token atau kode tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak percobaan login gagal",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/login/oidc": {
+            "get": {
+                "description": "Mengarahkan browser ke halaman login identity provider (authorization code flow dengan PKCE). State, nonce dan code verifier disimpan di cookie oidc_flow sampai callback",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Start an OpenID Connect login",
+                "responses": {
+                    "302": {
+                        "description": "Redirect ke identity provider"
+                    },
+                    "404": {
+                        "description": "Login OIDC tidak dikonfigurasi",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Identity provider tidak bisa dihubungi",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/login/oidc/callback": {
+            "get": {
+                "description": "Callback dari identity provider. Authorization code ditukar dengan ID token lalu identitas dipetakan ke user (dibuat saat login pertama dengan role OIDC_DEFAULT_ROLE). Pengecekan akun sama dengan /api/login: user dengan 2FA mendapat challenge token untuk /api/login/2fa, selain itu access token dan refresh token dikembalikan. Akun lokal dengan email yang sama tidak dihubungkan otomatis; hubungkan lewat /api/me/oidc. Jika flow dimulai dari /api/me/oidc, identitas dihubungkan ke akun tersebut",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Complete an OpenID Connect login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State dari /api/login/oidc",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login berhasil",
+                        "schema": {
+                            "$ref": "#/definitions/repository.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Login ditolak identity provider atau state tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Email belum diverifikasi, akun dinonaktifkan atau password harus direset",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Login OIDC tidak dikonfigurasi",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email atau identitas sudah dipakai akun lain",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mencabut session token yang sedang dipakai. Access token dan refresh token session ini langsung tidak berlaku",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "200": {
+                        "description": "Logout berhasil",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengambil data akun user yang sedang login",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Get my profile",
+                "responses": {
+                    "200": {
+                        "description": "Data akun",
+                        "schema": {
+                            "$ref": "#/definitions/models.PublicUser"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus akun user yang sedang login setelah konfirmasi password. Semua session dicabut. Admin terakhir tidak bisa menghapus akunnya",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Delete my account",
+                "parameters": [
+                    {
+                        "description": "Konfirmasi password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.DeleteAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Akun dihapus",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized atau password salah",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Admin terakhir tidak bisa dihapus",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak password salah",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengganti username dan/atau email. Mengganti email membutuhkan current_password, dan email baru harus diverifikasi ulang lewat link yang dikirim ke email tersebut",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Update my profile",
+                "parameters": [
+                    {
+                        "description": "Field yang diubah",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data akun setelah diubah",
+                        "schema": {
+                            "$ref": "#/definitions/models.PublicUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized atau password saat ini salah",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Username atau email sudah terdaftar",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak password salah",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menonaktifkan 2FA dengan konfirmasi password dan kode TOTP atau recovery code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Password dan kode",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.TwoFactorDisableRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "2FA dinonaktifkan",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, password atau kode salah",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak percobaan gagal",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/2fa/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengaktifkan 2FA dengan kode pertama dari aplikasi authenticator. Recovery code hanya ditampilkan sekali, dan session baru yang sudah terverifikasi 2FA dikembalikan",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Confirm two-factor enrolment",
+                "parameters": [
+                    {
+                        "description": "Kode TOTP",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "2FA aktif",
+                        "schema": {
+                            "$ref": "#/definitions/repository.TwoFactorEnableResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Setup belum dilakukan atau kode tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "2FA sudah aktif",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/2fa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat recovery code baru dengan konfirmasi kode TOTP. Recovery code lama tidak berlaku lagi",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Regenerate recovery codes",
+                "parameters": [
+                    {
+                        "description": "Kode TOTP",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recovery code baru",
+                        "schema": {
+                            "$ref": "#/definitions/repository.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "2FA belum aktif",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized atau kode salah",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak percobaan gagal",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/2fa/setup": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat secret TOTP baru dan URI otpauth:// untuk QR code. 2FA baru aktif setelah dikonfirmasi lewat /api/me/2fa/enable",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Start two-factor enrolment",
+                "responses": {
+                    "200": {
+                        "description": "Secret dan URI provisioning",
+                        "schema": {
+                            "$ref": "#/definitions/repository.TwoFactorSetupResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "2FA sudah aktif",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/oidc": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Memulai login ke identity provider untuk menghubungkan identitasnya ke akun yang sedang login. Buka url yang dikembalikan di browser yang sama; callback /api/login/oidc/callback menghubungkan akun lalu login berikutnya lewat identity provider masuk ke akun ini",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Start linking my account to the identity provider",
+                "responses": {
+                    "200": {
+                        "description": "URL halaman login identity provider",
+                        "schema": {
+                            "$ref": "#/definitions/repository.OIDCLinkResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Login OIDC tidak dikonfigurasi",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Akun sudah terhubung dengan identity provider",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Identity provider tidak bisa dihubungi",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengganti password dengan memasukkan password saat ini. Semua session lain dicabut dan session baru dikembalikan",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Change my password",
+                "parameters": [
+                    {
+                        "description": "Password saat ini dan password baru",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password diubah, token baru dikembalikan",
+                        "schema": {
+                            "$ref": "#/definitions/repository.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized atau password saat ini salah",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak password salah",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/password/forgot": {
+            "post": {
+                "description": "Mengirim link reset password ke email user. Response selalu sama walaupun email tidak terdaftar agar tidak bisa dipakai untuk menebak akun",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Request a password reset",
+                "parameters": [
+                    {
+                        "description": "Email akun",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Link reset dikirim jika email terdaftar",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak permintaan reset password dari IP ini",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/password/reset": {
+            "post": {
+                "description": "Mengganti password memakai token dari email. Token hanya berlaku sekali dan semua session user dicabut",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Reset a password",
+                "parameters": [
+                    {
+                        "description": "Token dan password baru",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password berhasil diubah",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request atau token tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/planner": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mencari rangkaian perjalanan dari kota asal ke tujuan, termasuk yang memerlukan transit, diurutkan berdasarkan waktu tiba. Jika jaringan terlalu besar untuk ditelusuri seluruhnya, header X-Results-Truncated bernilai true dan hasilnya mungkin tidak lengkap",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Search"
+                ],
+                "summary": "Plan a journey with transfers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Kota asal",
+                        "name": "asal",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Kota tujuan",
+                        "name": "tujuan",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal keberangkatan (YYYY-MM-DD)",
+                        "name": "tanggal",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maksimal jumlah transit (default 2, maks 4)",
+                        "name": "maks_transit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Waktu transit minimal dalam menit (default 15)",
+                        "name": "min_transit_menit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah hasil maksimal (default 10, maks 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar itinerary",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/repository.Itinerary"
+                            }
+                        },
+                        "headers": {
+                            "X-Results-Truncated": {
+                                "type": "boolean",
+                                "description": "true jika penelusuran dihentikan sebelum selesai"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/register": {
+            "post": {
+                "description": "Mendaftarkan pengguna baru dengan username dan email, lalu mengirim email verifikasi",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Username or email already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rutes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mengambil data rute dengan filter, sort dan pagination. Filter: field=nilai, field[op]=nilai (eq, ne, gt, gte, lt, lte, in). Sort: sort=field,-field",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rute"
+                ],
+                "summary": "Get all rutes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Nomor halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah data per halaman (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor dari next_cursor halaman sebelumnya",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Urutan, mis. asal,-jarak_km",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter kota asal",
+                        "name": "asal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter kota tujuan",
+                        "name": "tujuan",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar rute",
+                        "schema": {
+                            "$ref": "#/definitions/query.Page-models_Rute"
+                        }
+                    },
+                    "400": {
+                        "description": "Query tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat data rute baru (Admin Only). Halte opsional, berurutan dari asal ke tujuan dengan jarak kumulatif (halte pertama 0, halte terakhir sama dengan jarak_km) dan dwell dalam menit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rute"
+                ],
+                "summary": "Create a new rute",
+                "parameters": [
+                    {
+                        "description": "Data rute baru",
+                        "name": "rute",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Rute"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Rute berhasil dibuat",
+                        "schema": {
+                            "$ref": "#/definitions/models.Rute"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - data tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rutes/nearby": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mencari rute yang melewati halte dalam radius tertentu dari sebuah titik. Setiap rute disertai halte terdekatnya dan diurutkan dari yang terdekat. Jarak dalam meter",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rute"
+                ],
+                "summary": "Find rute passing nearby",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Lintang titik (derajat desimal)",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Bujur titik (derajat desimal)",
+                        "name": "lng",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Radius dalam meter (default 1000, maks 50000)",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah hasil maksimal (default 20, maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rute terdekat",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RuteTerdekat"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rutes/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mengambil data rute berdasarkan ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rute"
+                ],
+                "summary": "Get a rute by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rute ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data rute yang ditemukan",
+                        "schema": {
+                            "$ref": "#/definitions/models.Rute"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Rute not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Memperbarui data rute yang sudah ada berdasarkan ID (Admin Only). Daftar halte ikut diganti, kirim halte kosong untuk menghapusnya. Untuk rute yang memiliki geometri, jarak rute dan halte dihitung dari geometri",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rute"
+                ],
+                "summary": "Update an existing rute",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rute ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data rute yang akan diupdate",
+                        "name": "rute",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Rute"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data berhasil diupdate",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID atau Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Rute not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus data rute berdasarkan ID (Admin Only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rute"
+                ],
+                "summary": "Delete a rute",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rute ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data berhasil dihapus",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Rute not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rutes/{id}/geojson": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mengambil rute sebagai GeoJSON Feature untuk klien peta. Geometry bernilai null jika rute belum memiliki geometri",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rute"
+                ],
+                "summary": "Get a rute as GeoJSON",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rute ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "GeoJSON Feature",
+                        "schema": {
+                            "$ref": "#/definitions/models.RuteFeature"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Rute not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rutes/{id}/geometri": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menyimpan jalur rute dari GeoJSON atau encoded polyline (Admin Only). Jarak rute dan jarak setiap halte dihitung ulang dari geometri. Ujung geometri harus berada dalam 500 m dari halte pertama dan terakhir, atau dari halte di kota asal dan tujuan untuk rute tanpa halte",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rute"
+                ],
+                "summary": "Set rute geometry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rute ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Geometri rute",
+                        "name": "geometri",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.RuteGeometriRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rute dengan geometri baru",
+                        "schema": {
+                            "$ref": "#/definitions/models.RuteFeature"
+                        }
+                    },
+                    "400": {
+                        "description": "Geometri tidak valid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Rute not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus jalur rute (Admin Only). Jarak rute dan halte tidak berubah",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rute"
+                ],
+                "summary": "Delete rute geometry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rute ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Geometri dihapus",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Rute not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Mencari keberangkatan berdasarkan kota asal, tujuan dan tanggal beserta sisa kursi dan jenis kendaraan",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Search"
+                ],
+                "summary": "Search journeys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Kota asal",
+                        "name": "asal",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Kota tujuan",
+                        "name": "tujuan",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal keberangkatan (YYYY-MM-DD)",
+                        "name": "tanggal",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Urutan: berangkat (default) atau durasi",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar keberangkatan",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/repository.SearchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/token/refresh": {
+            "post": {
+                "description": "Menukar refresh token dengan access token dan refresh token baru. Refresh token lama tidak bisa dipakai lagi; jika dipakai ulang, session dicabut",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Refresh the access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repository.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token baru",
+                        "schema": {
+                            "$ref": "#/definitions/repository.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Refresh token tidak valid, kedaluwarsa atau sudah dicabut",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Akun dinonaktifkan",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "gtfs.Issue": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "file": {
+                    "type": "string"
+                },
+                "line": {
+                    "description": "0 untuk masalah pada level file",
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "jwtkeys.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "description": "Ed25519 (RFC 8037)",
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "description": "RSA",
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "jwtkeys.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jwtkeys.JWK"
+                    }
+                }
+            }
+        },
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Beberapa karakter awal key untuk dikenali di daftar",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.Booking": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "jadwal_id": {
+                    "type": "string"
+                },
+                "jumlah_kursi": {
+                    "type": "integer"
+                },
+                "naik_halte_id": {
+                    "description": "Halte naik dan turun, kosong jika penumpang ikut dari asal sampai tujuan",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "turun_halte_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GeoPoint": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Halte": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string"
+                },
+                "kode": {
+                    "type": "string"
+                },
+                "kota": {
+                    "type": "string"
+                },
+                "lokasi": {
+                    "$ref": "#/definitions/models.GeoPoint"
+                },
+                "nama": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HalteTerdekat": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string"
+                },
+                "jarak_m": {
+                    "type": "number"
+                },
+                "kode": {
+                    "type": "string"
+                },
+                "kota": {
+                    "type": "string"
+                },
+                "lokasi": {
+                    "$ref": "#/definitions/models.GeoPoint"
+                },
+                "nama": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Jadwal": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string"
+                },
+                "estimasi_tiba": {
+                    "type": "string"
+                },
+                "gtfs": {
+                    "description": "Diisi jika dibuat dari impor GTFS",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.JadwalGTFS"
+                        }
+                    ]
+                },
+                "kendaraan_id": {
+                    "type": "string"
+                },
+                "kursi_terisi": {
+                    "type": "integer"
+                },
+                "pengemudi": {
+                    "type": "string"
+                },
+                "rute_id": {
+                    "type": "string"
+                },
+                "tanggal": {
+                    "description": "Tengah malam pada zona waktu rute",
+                    "type": "string"
+                },
+                "template_id": {
+                    "description": "Diisi jika dibuat dari JadwalTemplate",
+                    "type": "string"
+                },
+                "waktu_berangkat": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JadwalGTFS": {
+            "type": "object",
+            "properties": {
+                "tanggal": {
+                    "description": "Tanggal layanan GTFS (YYYYMMDD)",
+                    "type": "string"
+                },
+                "trip_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JadwalStop": {
+            "type": "object",
+            "properties": {
+                "berangkat": {
+                    "type": "string"
+                },
+                "halte": {
+                    "$ref": "#/definitions/models.Halte"
+                },
+                "jarak_km": {
+                    "type": "number"
+                },
+                "tiba": {
+                    "type": "string"
+                },
+                "urutan": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.JadwalTemplate": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string"
+                },
+                "aktif": {
+                    "type": "boolean"
+                },
+                "durasi_menit": {
+                    "type": "integer"
+                },
+                "hari_operasi": {
+                    "description": "0 = Minggu, 6 = Sabtu",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "jam_berangkat": {
+                    "description": "HH:MM pada zona waktu rute",
+                    "type": "string"
+                },
+                "kendaraan_id": {
+                    "type": "string"
+                },
+                "pengemudi": {
+                    "type": "string"
+                },
+                "rute_id": {
+                    "type": "string"
+                },
+                "tanggal_mulai": {
+                    "type": "string"
+                },
+                "tanggal_pengecualian": {
+                    "description": "Mis. hari libur nasional",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tanggal_selesai": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JadwalWithRute": {
+            "type": "object",
+            "properties": {
+                "estimasi_tiba": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kendaraan": {
+                    "$ref": "#/definitions/models.Kendaraan"
+                },
+                "kendaraan_id": {
+                    "type": "string"
+                },
+                "kursi_terisi": {
+                    "type": "integer"
+                },
+                "pengemudi": {
+                    "type": "string"
+                },
+                "rute": {
+                    "$ref": "#/definitions/models.Rute"
+                },
+                "rute_id": {
+                    "type": "string"
+                },
+                "tanggal": {
+                    "type": "string"
+                },
+                "waktu_berangkat": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Kendaraan": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string"
+                },
+                "jenis": {
+                    "type": "string"
+                },
+                "kapasitas": {
+                    "type": "integer"
+                },
+                "nomor_polisi": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LineString": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        }
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "LineString"
+                }
+            }
+        },
+        "models.PublicUser": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "must_reset_password": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "two_factor_enabled": {
+                    "type": "boolean"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Role": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string"
+                },
+                "built_in": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.Rute": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string"
+                },
+                "asal": {
+                    "type": "string"
+                },
+                "halte": {
+                    "description": "Halte berisi pemberhentian berurutan dari asal ke tujuan. Kosong untuk\nrute tanpa halte antara.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RuteHalte"
+                    }
+                },
+                "jarak_km": {
+                    "type": "integer"
+                },
+                "kode_rute": {
+                    "type": "string"
+                },
+                "nama_rute": {
+                    "type": "string"
+                },
+                "tujuan": {
+                    "type": "string"
+                },
+                "zona_waktu": {
+                    "description": "Nama IANA, mis. Asia/Jakarta (WIB)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RuteFeature": {
+            "type": "object",
+            "properties": {
+                "geometry": {
+                    "$ref": "#/definitions/models.LineString"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "properties": {
+                    "$ref": "#/definitions/models.RuteProperties"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Feature"
+                }
+            }
+        },
+        "models.RuteHalte": {
+            "type": "object",
+            "properties": {
+                "dwell_menit": {
+                    "description": "Lama berhenti di halte ini",
+                    "type": "integer"
+                },
+                "halte_id": {
+                    "type": "string"
+                },
+                "jarak_km": {
+                    "description": "Kumulatif dari halte pertama",
+                    "type": "number"
+                }
+            }
+        },
+        "models.RuteProperties": {
+            "type": "object",
+            "properties": {
+                "asal": {
+                    "type": "string"
+                },
+                "jarak_km": {
+                    "type": "integer"
+                },
+                "kode_rute": {
+                    "type": "string"
+                },
+                "nama_rute": {
+                    "type": "string"
+                },
+                "tujuan": {
+                    "type": "string"
+                },
+                "zona_waktu": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RuteTerdekat": {
+            "type": "object",
+            "properties": {
+                "halte": {
+                    "description": "Halte rute yang paling dekat",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Halte"
+                        }
+                    ]
+                },
+                "jarak_m": {
+                    "type": "number"
+                },
+                "rute": {
+                    "$ref": "#/definitions/models.Rute"
+                }
+            }
+        },
+        "models.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "description": "Disabled menolak login dan refresh token sampai diaktifkan lagi admin",
+                    "type": "boolean"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "must_reset_password": {
+                    "description": "MustResetPassword diisi admin untuk memaksa user mengganti password\nlewat link reset sebelum bisa login lagi",
+                    "type": "boolean"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "query.Page-models_Halte": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Halte"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "query.Page-models_JadwalWithRute": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JadwalWithRute"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "query.Page-models_Kendaraan": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Kendaraan"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "query.Page-models_PublicUser": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PublicUser"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "query.Page-models_Rute": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Rute"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "repository.APIKeyCreatedResponse": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Beberapa karakter awal key untuk dikenali di daftar",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "repository.AssignRoleRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.AuthRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.BookingRequest": {
+            "type": "object",
+            "properties": {
+                "jumlah_kursi": {
+                    "type": "integer"
+                },
+                "naik_halte": {
+                    "type": "string"
+                },
+                "turun_halte": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.ChangePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "password_confirmation": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.CreateAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "repository.DeleteAccountRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.ForgotPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.GTFSImportChange": {
+            "type": "object",
+            "properties": {
+                "aksi": {
+                    "description": "dibuat, diubah atau dihapus",
+                    "type": "string"
+                },
+                "jenis": {
+                    "description": "halte, rute atau jadwal",
+                    "type": "string"
+                },
+                "kode": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.GTFSImportCount": {
+            "type": "object",
+            "properties": {
+                "dibuat": {
+                    "type": "integer"
+                },
+                "dihapus": {
+                    "type": "integer"
+                },
+                "diubah": {
+                    "type": "integer"
+                },
+                "tetap": {
+                    "type": "integer"
+                }
+            }
+        },
+        "repository.GTFSImportResult": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "halte": {
+                    "$ref": "#/definitions/repository.GTFSImportCount"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gtfs.Issue"
+                    }
+                },
+                "jadwal": {
+                    "$ref": "#/definitions/repository.GTFSImportCount"
+                },
+                "perubahan": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.GTFSImportChange"
+                    }
+                },
+                "rute": {
+                    "$ref": "#/definitions/repository.GTFSImportCount"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.ErrorResponse": {
+        },
+        "repository.GTFSIssueResponse": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "string"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gtfs.Issue"
+                    }
                 }
             }
         },
-        "models.Jadwal": {
+        "repository.GenerateKonflik": {
             "type": "object",
             "properties": {
-                "_id": {
-                    "type": "string"
+                "conflicts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "estimasi_tiba": {
+                "tanggal": {
                     "type": "string"
+                }
+            }
+        },
+        "repository.GenerateResult": {
+            "type": "object",
+            "properties": {
+                "bentrok": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.GenerateKonflik"
+                    }
                 },
-                "kendaraan_id": {
+                "dibuat": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sudah_ada": {
+                    "type": "integer"
+                }
+            }
+        },
+        "repository.HalteRequest": {
+            "type": "object",
+            "properties": {
+                "kode": {
                     "type": "string"
                 },
-                "rute_id": {
+                "kota": {
                     "type": "string"
                 },
-                "tanggal": {
+                "lat": {
+                    "type": "number"
+                },
+                "lng": {
+                    "type": "number"
+                },
+                "nama": {
                     "type": "string"
+                }
+            }
+        },
+        "repository.Itinerary": {
+            "type": "object",
+            "properties": {
+                "durasi_menit": {
+                    "type": "integer"
+                },
+                "jumlah_transit": {
+                    "type": "integer"
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.ItineraryLeg"
+                    }
+                },
+                "total_jarak_km": {
+                    "type": "integer"
                 },
                 "waktu_berangkat": {
                     "type": "string"
+                },
+                "waktu_tiba": {
+                    "type": "string"
                 }
             }
         },
-        "models.Kendaraan": {
+        "repository.ItineraryLeg": {
             "type": "object",
             "properties": {
-                "_id": {
+                "asal": {
                     "type": "string"
                 },
-                "jenis": {
+                "estimasi_tiba": {
                     "type": "string"
                 },
-                "kapasitas": {
+                "jadwal_id": {
+                    "type": "string"
+                },
+                "jarak_km": {
+                    "type": "integer"
+                },
+                "kode_rute": {
+                    "type": "string"
+                },
+                "transit_menit": {
+                    "description": "Waktu tunggu sebelum leg ini",
+                    "type": "integer"
+                },
+                "tujuan": {
+                    "type": "string"
+                },
+                "waktu_berangkat": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.JadwalTemplateRequest": {
+            "type": "object",
+            "properties": {
+                "aktif": {
+                    "type": "boolean"
+                },
+                "durasi_menit": {
                     "type": "integer"
                 },
+                "hari_operasi": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "jam_berangkat": {
+                    "type": "string"
+                },
+                "kode_rute": {
+                    "type": "string"
+                },
                 "nomor_polisi": {
                     "type": "string"
                 },
-                "status": {
+                "pengemudi": {
+                    "type": "string"
+                },
+                "tanggal_mulai": {
+                    "type": "string"
+                },
+                "tanggal_pengecualian": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tanggal_selesai": {
                     "type": "string"
                 }
             }
         },
-        "models.Rute": {
+        "repository.LoginResponse": {
             "type": "object",
             "properties": {
-                "_id": {
+                "refresh_token": {
                     "type": "string"
                 },
+                "role": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.OIDCLinkResponse": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "repository.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.ResendVerificationRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "password_confirmation": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.RoleRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "repository.RuteGeometriRequest": {
+            "type": "object",
+            "properties": {
+                "geojson": {
+                    "type": "object"
+                },
+                "polyline": {
+                    "type": "string"
+                },
+                "precision": {
+                    "description": "Digit desimal polyline, 5 (default) atau 6",
+                    "type": "integer"
+                }
+            }
+        },
+        "repository.SearchResult": {
+            "type": "object",
+            "properties": {
                 "asal": {
                     "type": "string"
                 },
+                "durasi_menit": {
+                    "type": "integer"
+                },
+                "estimasi_tiba": {
+                    "type": "string"
+                },
+                "jadwal_id": {
+                    "type": "string"
+                },
                 "jarak_km": {
                     "type": "integer"
                 },
+                "jenis_kendaraan": {
+                    "type": "string"
+                },
+                "kapasitas": {
+                    "type": "integer"
+                },
                 "kode_rute": {
                     "type": "string"
                 },
                 "nama_rute": {
                     "type": "string"
                 },
+                "nomor_polisi": {
+                    "type": "string"
+                },
+                "sisa_kursi": {
+                    "type": "integer"
+                },
                 "tujuan": {
                     "type": "string"
+                },
+                "waktu_berangkat": {
+                    "type": "string"
                 }
             }
         },
-        "models.SuccessResponse": {
+        "repository.TwoFactorCodeRequest": {
             "type": "object",
             "properties": {
-                "message": {
+                "code": {
                     "type": "string"
                 }
             }
         },
-        "models.User": {
+        "repository.TwoFactorDisableRequest": {
             "type": "object",
             "properties": {
-                "_id": {
+                "code": {
                     "type": "string"
                 },
-                "email": {
+                "password": {
                     "type": "string"
                 },
-                "password": {
+                "recovery_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.TwoFactorEnableResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "refresh_token": {
                     "type": "string"
                 },
                 "role": {
                     "type": "string"
                 },
-                "username": {
+                "token": {
                     "type": "string"
                 }
             }
         },
-        "repository.AuthRequest": {
+        "repository.TwoFactorLoginRequest": {
             "type": "object",
             "properties": {
-                "password": {
+                "challenge_token": {
                     "type": "string"
                 },
-                "username": {
+                "code": {
+                    "type": "string"
+                },
+                "recovery_code": {
                     "type": "string"
                 }
             }
         },
-        "repository.JadwalWithRute": {
+        "repository.TwoFactorSetupResponse": {
             "type": "object",
             "properties": {
-                "estimasi_tiba": {
+                "otpauth_url": {
+                    "description": "Dijadikan QR code oleh frontend",
                     "type": "string"
                 },
-                "id": {
+                "secret": {
                     "type": "string"
-                },
-                "rute": {
-                    "$ref": "#/definitions/models.Rute"
-                },
-                "rute_id": {
+                }
+            }
+        },
+        "repository.UpdateProfileRequest": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "description": "CurrentPassword wajib diisi jika email diganti",
                     "type": "string"
                 },
-                "tanggal": {
+                "email": {
                     "type": "string"
                 },
-                "waktu_berangkat": {
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "repository.LoginResponse": {
+        "repository.VerifyEmailRequest": {
             "type": "object",
             "properties": {
                 "token": {
@@ -1144,6 +6413,12 @@ const docTemplate = `{
         }
     },
     "securityDefinitions": {
+        "APIKeyAuth": {
+            "description": "API key dari admin untuk integrasi agen tiket dan kios, hanya untuk endpoint baca",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BearerAuth": {
             "description": "Ketik \"Bearer\" diikuti spasi dan token. Contoh: \"Bearer {token}\"",
             "type": "apiKey",
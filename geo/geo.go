@@ -0,0 +1,27 @@
+// Package geo berisi perhitungan jarak di permukaan bumi untuk koordinat
+// WGS 84 dalam derajat desimal.
+package geo
+
+import "math"
+
+// EarthRadiusM adalah jari-jari rata-rata bumi dalam meter, sama dengan
+// yang dipakai MongoDB untuk query 2dsphere
+const EarthRadiusM = 6378100.0
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// Haversine menghitung jarak lingkaran besar antara dua titik dalam meter
+func Haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := radians(lat2 - lat1)
+	dLng := radians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadiusM * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// ValidLatLng memeriksa apakah koordinat berada dalam rentang yang sah
+func ValidLatLng(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
@@ -0,0 +1,93 @@
+package geo
+
+import (
+	"errors"
+	"math"
+)
+
+// Point adalah satu titik dalam derajat desimal
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Length menghitung panjang garis dalam meter
+func Length(line []Point) float64 {
+	total := 0.0
+	for i := 1; i < len(line); i++ {
+		total += Haversine(line[i-1].Lat, line[i-1].Lng, line[i].Lat, line[i].Lng)
+	}
+	return total
+}
+
+// Locate mencari titik pada garis yang paling dekat dengan p. Hasilnya
+// jarak dari awal garis sampai titik tersebut dan jarak p ke garis, keduanya
+// dalam meter. Proyeksi ke setiap segmen memakai pendekatan bidang datar
+// yang cukup akurat untuk segmen jalan yang pendek.
+func Locate(line []Point, p Point) (along, offset float64) {
+	if len(line) == 0 {
+		return 0, math.Inf(1)
+	}
+	along = 0
+	offset = Haversine(p.Lat, p.Lng, line[0].Lat, line[0].Lng)
+
+	walked := 0.0
+	for i := 1; i < len(line); i++ {
+		a, b := line[i-1], line[i]
+		segment := Haversine(a.Lat, a.Lng, b.Lat, b.Lng)
+
+		// Koordinat lokal dalam meter dengan a sebagai titik asal
+		scale := math.Cos(radians(a.Lat))
+		bx, by := radians(b.Lng-a.Lng)*scale, radians(b.Lat-a.Lat)
+		px, py := radians(p.Lng-a.Lng)*scale, radians(p.Lat-a.Lat)
+		t := 0.0
+		if d := bx*bx + by*by; d > 0 {
+			t = math.Max(0, math.Min(1, (px*bx+py*by)/d))
+		}
+		proj := Point{Lat: a.Lat + (b.Lat-a.Lat)*t, Lng: a.Lng + (b.Lng-a.Lng)*t}
+		if d := Haversine(p.Lat, p.Lng, proj.Lat, proj.Lng); d < offset {
+			offset = d
+			along = walked + segment*t
+		}
+		walked += segment
+	}
+	return along, offset
+}
+
+var ErrPolyline = errors.New("encoded polyline tidak valid")
+
+// DecodePolyline membaca encoded polyline format Google. Precision adalah
+// jumlah digit desimal koordinat, 5 untuk Google Maps dan 6 untuk OSRM.
+func DecodePolyline(s string, precision int) ([]Point, error) {
+	factor := math.Pow10(precision)
+	points := []Point{}
+	var lat, lng int64
+	for i := 0; i < len(s); {
+		var delta [2]int64
+		for k := range delta {
+			var result int64
+			var shift uint
+			for {
+				if i >= len(s) || s[i] < 63 || s[i] > 126 || shift > 60 {
+					return nil, ErrPolyline
+				}
+				b := int64(s[i]) - 63
+				i++
+				result |= (b & 0x1f) << shift
+				shift += 5
+				if b < 0x20 {
+					break
+				}
+			}
+			if result&1 != 0 {
+				delta[k] = ^(result >> 1)
+			} else {
+				delta[k] = result >> 1
+			}
+		}
+		lat += delta[0]
+		lng += delta[1]
+		points = append(points, Point{Lat: float64(lat) / factor, Lng: float64(lng) / factor})
+	}
+	return points, nil
+}
@@ -0,0 +1,154 @@
+// Package gtfs membaca, menulis dan memvalidasi feed GTFS static
+// (https://gtfs.org/schedule/reference/). Hanya file dan kolom yang dipakai
+// aplikasi ini yang didukung: agency, routes, stops, trips, stop_times dan
+// calendar.
+package gtfs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RouteTypeBus adalah route_type untuk layanan bus
+const RouteTypeBus = 3
+
+const dateLayout = "20060102"
+
+type Agency struct {
+	Line     int // Baris di file sumber, 0 untuk data yang dibuat aplikasi
+	ID       string
+	Name     string
+	URL      string
+	Timezone string
+	Lang     string
+}
+
+type Route struct {
+	Line      int
+	ID        string
+	AgencyID  string
+	ShortName string
+	LongName  string
+	Type      int
+}
+
+type Stop struct {
+	Line     int
+	ID       string
+	Name     string
+	Desc     string
+	Lat      float64
+	Lon      float64
+	Timezone string
+}
+
+type Trip struct {
+	Line      int
+	RouteID   string
+	ServiceID string
+	ID        string
+	Headsign  string
+	BlockID   string // Aplikasi ini mengisinya dengan nomor polisi kendaraan
+}
+
+type StopTime struct {
+	Line         int
+	TripID       string
+	Arrival      Time
+	Departure    Time
+	StopID       string
+	Sequence     int
+	ShapeDist    float64 // Satuan bebas; feed dari aplikasi ini memakai kilometer
+	HasShapeDist bool
+}
+
+// Calendar adalah pola layanan mingguan. Days dimulai dari Senin sesuai
+// urutan kolom di calendar.txt.
+type Calendar struct {
+	Line      int
+	ServiceID string
+	Days      [7]bool
+	Start     time.Time
+	End       time.Time
+}
+
+// Runs memeriksa apakah layanan berjalan pada tanggal tersebut
+func (c Calendar) Runs(date time.Time) bool {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Before(c.Start) || day.After(c.End) {
+		return false
+	}
+	return c.Days[(int(day.Weekday())+6)%7]
+}
+
+type Feed struct {
+	Agencies  []Agency
+	Routes    []Route
+	Stops     []Stop
+	Trips     []Trip
+	StopTimes []StopTime
+	Calendars []Calendar
+}
+
+// Time adalah jumlah detik sejak "tengah hari dikurangi 12 jam" pada hari
+// layanan, sehingga bisa melewati 24:00:00 untuk perjalanan yang melewati
+// tengah malam
+type Time int
+
+// NoTime menandai arrival_time atau departure_time yang dikosongkan
+const NoTime Time = -1
+
+func (t Time) String() string {
+	if t < 0 {
+		return ""
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", t/3600, t/60%60, t%60)
+}
+
+// ParseTime membaca waktu dengan format H:MM:SS atau HH:MM:SS. String
+// kosong menghasilkan NoTime.
+func ParseTime(s string) (Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return NoTime, nil
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 || len(parts[1]) != 2 || len(parts[2]) != 2 || len(parts[0]) == 0 || len(parts[0]) > 3 {
+		return NoTime, fmt.Errorf("format waktu harus HH:MM:SS: %q", s)
+	}
+	var v [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return NoTime, fmt.Errorf("format waktu harus HH:MM:SS: %q", s)
+		}
+		v[i] = n
+	}
+	if v[1] > 59 || v[2] > 59 {
+		return NoTime, fmt.Errorf("menit dan detik harus kurang dari 60: %q", s)
+	}
+	return Time(v[0]*3600 + v[1]*60 + v[2]), nil
+}
+
+// ServiceDay mengembalikan acuan waktu GTFS untuk tanggal tersebut, yaitu
+// tengah hari dikurangi 12 jam pada zona waktu agency. Berbeda dengan tengah
+// malam pada hari pergantian daylight saving time.
+func ServiceDay(year int, month time.Month, day int, loc *time.Location) time.Time {
+	return time.Date(year, month, day, 12, 0, 0, 0, loc).Add(-12 * time.Hour)
+}
+
+// FormatDate menulis tanggal dengan format YYYYMMDD
+func FormatDate(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+// ParseDate membaca tanggal YYYYMMDD sebagai tengah malam UTC
+func ParseDate(s string) (time.Time, error) {
+	t, err := time.Parse(dateLayout, strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("format tanggal harus YYYYMMDD: %q", s)
+	}
+	return t, nil
+}
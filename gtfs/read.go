@@ -0,0 +1,319 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Issue adalah pelanggaran aturan GTFS pada sebuah file atau baris
+type Issue struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"` // 0 untuk masalah pada level file
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (i Issue) Error() string {
+	loc := i.File
+	if i.Line > 0 {
+		loc += ":" + strconv.Itoa(i.Line)
+	}
+	if i.Field != "" {
+		loc += " " + i.Field
+	}
+	return loc + ": " + i.Message
+}
+
+var ErrNotZip = errors.New("file GTFS harus berupa zip")
+
+// maxTableBytes membatasi ukuran satu file setelah didekompresi agar zip
+// kecil yang mengembang sangat besar tidak menghabiskan memori
+var maxTableBytes int64 = 64 << 20
+
+// Read membaca feed dari zip lalu memvalidasinya. Baris yang tidak bisa
+// dibaca dilewati dan dilaporkan sebagai Issue bersama hasil Validate. Error
+// hanya dikembalikan jika data bukan zip yang bisa dibuka.
+func Read(r io.ReaderAt, size int64) (Feed, []Issue, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return Feed{}, nil, ErrNotZip
+	}
+
+	// Banyak feed dibungkus dalam satu folder, jadi file dicari berdasarkan
+	// nama tanpa folder
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		if !f.FileInfo().IsDir() {
+			files[path.Base(f.Name)] = f
+		}
+	}
+
+	var feed Feed
+	var issues []Issue
+	read := func(name string, required []string, parse func(row)) {
+		f, ok := files[name]
+		if !ok {
+			issues = append(issues, Issue{File: name, Message: "file wajib tidak ada"})
+			return
+		}
+		issues = append(issues, readTable(f, required, parse)...)
+	}
+
+	read("agency.txt", []string{"agency_name", "agency_url", "agency_timezone"}, func(r row) {
+		a := Agency{
+			Line:     r.line,
+			ID:       r.str("agency_id"),
+			Name:     r.required("agency_name"),
+			URL:      r.required("agency_url"),
+			Timezone: r.required("agency_timezone"),
+			Lang:     r.str("agency_lang"),
+		}
+		if r.ok() {
+			feed.Agencies = append(feed.Agencies, a)
+		}
+	})
+	read("routes.txt", []string{"route_id", "route_type"}, func(r row) {
+		rt := Route{
+			Line:      r.line,
+			ID:        r.required("route_id"),
+			AgencyID:  r.str("agency_id"),
+			ShortName: r.str("route_short_name"),
+			LongName:  r.str("route_long_name"),
+			Type:      r.integer("route_type"),
+		}
+		if r.ok() {
+			feed.Routes = append(feed.Routes, rt)
+		}
+	})
+	read("stops.txt", []string{"stop_id", "stop_name", "stop_lat", "stop_lon"}, func(r row) {
+		// Hanya titik pemberhentian (location_type 0) yang dipakai; stasiun
+		// induk, pintu masuk dan node tidak punya padanan halte
+		if lt := r.str("location_type"); lt != "" && lt != "0" {
+			return
+		}
+		s := Stop{
+			Line:     r.line,
+			ID:       r.required("stop_id"),
+			Name:     r.required("stop_name"),
+			Desc:     r.str("stop_desc"),
+			Lat:      r.float("stop_lat"),
+			Lon:      r.float("stop_lon"),
+			Timezone: r.str("stop_timezone"),
+		}
+		if r.ok() {
+			feed.Stops = append(feed.Stops, s)
+		}
+	})
+	read("trips.txt", []string{"route_id", "service_id", "trip_id"}, func(r row) {
+		t := Trip{
+			Line:      r.line,
+			RouteID:   r.required("route_id"),
+			ServiceID: r.required("service_id"),
+			ID:        r.required("trip_id"),
+			Headsign:  r.str("trip_headsign"),
+			BlockID:   r.str("block_id"),
+		}
+		if r.ok() {
+			feed.Trips = append(feed.Trips, t)
+		}
+	})
+	read("stop_times.txt", []string{"trip_id", "stop_id", "stop_sequence"}, func(r row) {
+		st := StopTime{
+			Line:      r.line,
+			TripID:    r.required("trip_id"),
+			Arrival:   r.time("arrival_time"),
+			Departure: r.time("departure_time"),
+			StopID:    r.required("stop_id"),
+			Sequence:  r.integer("stop_sequence"),
+		}
+		if r.str("shape_dist_traveled") != "" {
+			st.ShapeDist, st.HasShapeDist = r.float("shape_dist_traveled"), true
+		}
+		if r.ok() {
+			feed.StopTimes = append(feed.StopTimes, st)
+		}
+	})
+	days := []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+	read("calendar.txt", append([]string{"service_id", "start_date", "end_date"}, days...), func(r row) {
+		c := Calendar{Line: r.line, ServiceID: r.required("service_id")}
+		for i, day := range days {
+			c.Days[i] = r.boolean(day)
+		}
+		c.Start = r.date("start_date")
+		c.End = r.date("end_date")
+		if r.ok() {
+			feed.Calendars = append(feed.Calendars, c)
+		}
+	})
+
+	return feed, append(issues, Validate(feed)...), nil
+}
+
+// row adalah satu baris CSV. Method pembaca mencatat Issue jika nilai
+// tidak valid sehingga baris bisa dilewati dengan memeriksa ok.
+type row struct {
+	file   string
+	line   int
+	cols   map[string]int
+	fields []string
+	issues *[]Issue
+	bad    *bool
+}
+
+func (r row) ok() bool {
+	return !*r.bad
+}
+
+func (r row) fail(col, msg string) {
+	*r.issues = append(*r.issues, Issue{File: r.file, Line: r.line, Field: col, Message: msg})
+	*r.bad = true
+}
+
+func (r row) str(col string) string {
+	i, ok := r.cols[col]
+	if !ok || i >= len(r.fields) {
+		return ""
+	}
+	return strings.TrimSpace(r.fields[i])
+}
+
+func (r row) required(col string) string {
+	v := r.str(col)
+	if v == "" {
+		r.fail(col, "wajib diisi")
+	}
+	return v
+}
+
+func (r row) integer(col string) int {
+	v := r.required(col)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		r.fail(col, fmt.Sprintf("harus bilangan bulat: %q", v))
+	}
+	return n
+}
+
+func (r row) float(col string) float64 {
+	v := r.required(col)
+	if v == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		r.fail(col, fmt.Sprintf("harus angka: %q", v))
+	}
+	return f
+}
+
+func (r row) boolean(col string) bool {
+	switch v := r.required(col); v {
+	case "1":
+		return true
+	case "0", "":
+		return false
+	default:
+		r.fail(col, fmt.Sprintf("harus 0 atau 1: %q", v))
+		return false
+	}
+}
+
+func (r row) time(col string) Time {
+	t, err := ParseTime(r.str(col))
+	if err != nil {
+		r.fail(col, err.Error())
+	}
+	return t
+}
+
+func (r row) date(col string) time.Time {
+	v := r.required(col)
+	if v == "" {
+		return time.Time{}
+	}
+	t, err := ParseDate(v)
+	if err != nil {
+		r.fail(col, err.Error())
+	}
+	return t
+}
+
+// readTable membaca satu file CSV dan memanggil parse untuk setiap baris
+func readTable(f *zip.File, required []string, parse func(row)) []Issue {
+	name := path.Base(f.Name)
+	var issues []Issue
+
+	rc, err := f.Open()
+	if err != nil {
+		return []Issue{{File: name, Message: "file tidak bisa dibuka: " + err.Error()}}
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxTableBytes+1))
+	if err != nil {
+		return []Issue{{File: name, Message: "file tidak bisa dibaca: " + err.Error()}}
+	}
+	if int64(len(data)) > maxTableBytes {
+		return []Issue{{File: name, Message: fmt.Sprintf("ukuran file melebihi batas %d MB", maxTableBytes>>20)}}
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return []Issue{{File: name, Line: 1, Message: "file kosong"}}
+	}
+	if err != nil {
+		return []Issue{{File: name, Line: 1, Message: "header tidak valid: " + err.Error()}}
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.TrimSpace(h)] = i
+	}
+	for _, col := range required {
+		if _, ok := cols[col]; !ok {
+			issues = append(issues, Issue{File: name, Line: 1, Field: col, Message: "kolom wajib tidak ada"})
+		}
+	}
+	if len(issues) > 0 {
+		return issues
+	}
+
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Setelah CSV rusak posisi baris berikutnya tidak bisa dipercaya
+			issue := Issue{File: name, Message: "CSV tidak valid: " + err.Error()}
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				issue.Line, issue.Message = perr.StartLine, "CSV tidak valid: "+perr.Err.Error()
+			}
+			return append(issues, issue)
+		}
+		line, _ := cr.FieldPos(0)
+		if len(fields) != len(header) {
+			issues = append(issues, Issue{File: name, Line: line, Message: fmt.Sprintf("jumlah kolom %d, header %d", len(fields), len(header))})
+			continue
+		}
+		if len(fields) == 1 && strings.TrimSpace(fields[0]) == "" {
+			continue
+		}
+		bad := false
+		parse(row{file: name, line: line, cols: cols, fields: fields, issues: &issues, bad: &bad})
+	}
+	return issues
+}
@@ -0,0 +1,30 @@
+package gtfs
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestReadTableLimit memastikan file yang mengembang melebihi batas setelah
+// didekompresi dilaporkan sebagai issue tanpa dibaca seluruhnya
+func TestReadTableLimit(t *testing.T) {
+	defer func(limit int64) { maxTableBytes = limit }(maxTableBytes)
+	maxTableBytes = 1 << 20
+
+	feed := validFeed()
+	feed.Stops[1].Desc = strings.Repeat("x", 2<<20)
+
+	read, issues := roundTrip(t, feed)
+	found := false
+	for _, issue := range issues {
+		if issue.File == "stops.txt" && issue.Line == 0 && strings.Contains(issue.Message, "melebihi batas") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("stops.txt melebihi batas tanpa issue: %v", issues)
+	}
+	if len(read.Stops) != 0 {
+		t.Fatalf("%d stop dibaca dari file yang melebihi batas", len(read.Stops))
+	}
+}
@@ -0,0 +1,173 @@
+package gtfs
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// Validate memeriksa aturan GTFS yang berlaku untuk file yang didukung:
+// field wajib, ID unik, referensi antar file, rentang koordinat, urutan
+// stop_sequence dan waktu di setiap trip, serta tanggal calendar.
+func Validate(feed Feed) []Issue {
+	var issues []Issue
+	add := func(file string, line int, field, format string, args ...interface{}) {
+		issues = append(issues, Issue{File: file, Line: line, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(feed.Agencies) == 0 {
+		add("agency.txt", 0, "", "minimal satu agency")
+	}
+	agencies := map[string]bool{}
+	for i, a := range feed.Agencies {
+		if len(feed.Agencies) > 1 && a.ID == "" {
+			add("agency.txt", a.Line, "agency_id", "wajib diisi jika ada lebih dari satu agency")
+		}
+		if agencies[a.ID] {
+			add("agency.txt", a.Line, "agency_id", "duplikat: %s", a.ID)
+		}
+		agencies[a.ID] = true
+		if u, err := url.Parse(a.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("agency.txt", a.Line, "agency_url", "harus URL http atau https lengkap")
+		}
+		if _, err := time.LoadLocation(a.Timezone); err != nil || a.Timezone == "" || a.Timezone == "Local" {
+			add("agency.txt", a.Line, "agency_timezone", "zona waktu tidak dikenal: %s", a.Timezone)
+		}
+		if i > 0 && a.Timezone != feed.Agencies[0].Timezone {
+			add("agency.txt", a.Line, "agency_timezone", "semua agency harus memakai zona waktu yang sama")
+		}
+	}
+
+	stops := map[string]bool{}
+	for _, s := range feed.Stops {
+		if stops[s.ID] {
+			add("stops.txt", s.Line, "stop_id", "duplikat: %s", s.ID)
+		}
+		stops[s.ID] = true
+		if s.Lat < -90 || s.Lat > 90 {
+			add("stops.txt", s.Line, "stop_lat", "harus antara -90 dan 90")
+		}
+		if s.Lon < -180 || s.Lon > 180 {
+			add("stops.txt", s.Line, "stop_lon", "harus antara -180 dan 180")
+		}
+		if s.Timezone != "" {
+			if _, err := time.LoadLocation(s.Timezone); err != nil || s.Timezone == "Local" {
+				add("stops.txt", s.Line, "stop_timezone", "zona waktu tidak dikenal: %s", s.Timezone)
+			}
+		}
+	}
+
+	routes := map[string]bool{}
+	for _, r := range feed.Routes {
+		if routes[r.ID] {
+			add("routes.txt", r.Line, "route_id", "duplikat: %s", r.ID)
+		}
+		routes[r.ID] = true
+		switch {
+		case r.AgencyID != "" && !agencies[r.AgencyID]:
+			add("routes.txt", r.Line, "agency_id", "agency tidak ditemukan: %s", r.AgencyID)
+		case r.AgencyID == "" && len(feed.Agencies) > 1:
+			add("routes.txt", r.Line, "agency_id", "wajib diisi jika ada lebih dari satu agency")
+		}
+		if r.ShortName == "" && r.LongName == "" {
+			add("routes.txt", r.Line, "route_short_name", "route_short_name atau route_long_name wajib diisi")
+		}
+		if !validRouteType(r.Type) {
+			add("routes.txt", r.Line, "route_type", "tidak dikenal: %d", r.Type)
+		}
+	}
+
+	services := map[string]bool{}
+	for _, c := range feed.Calendars {
+		if services[c.ServiceID] {
+			add("calendar.txt", c.Line, "service_id", "duplikat: %s", c.ServiceID)
+		}
+		services[c.ServiceID] = true
+		if c.End.Before(c.Start) {
+			add("calendar.txt", c.Line, "end_date", "tidak boleh sebelum start_date")
+		}
+	}
+
+	trips := map[string]bool{}
+	for _, t := range feed.Trips {
+		if trips[t.ID] {
+			add("trips.txt", t.Line, "trip_id", "duplikat: %s", t.ID)
+		}
+		trips[t.ID] = true
+		if !routes[t.RouteID] {
+			add("trips.txt", t.Line, "route_id", "route tidak ditemukan: %s", t.RouteID)
+		}
+		if !services[t.ServiceID] {
+			add("trips.txt", t.Line, "service_id", "service tidak ditemukan di calendar.txt: %s", t.ServiceID)
+		}
+	}
+
+	byTrip := map[string][]StopTime{}
+	for _, st := range feed.StopTimes {
+		if !trips[st.TripID] {
+			add("stop_times.txt", st.Line, "trip_id", "trip tidak ditemukan: %s", st.TripID)
+			continue
+		}
+		if !stops[st.StopID] {
+			add("stop_times.txt", st.Line, "stop_id", "stop tidak ditemukan: %s", st.StopID)
+		}
+		if st.Sequence < 0 {
+			add("stop_times.txt", st.Line, "stop_sequence", "tidak boleh negatif")
+		}
+		if st.Arrival != NoTime && st.Departure != NoTime && st.Departure < st.Arrival {
+			add("stop_times.txt", st.Line, "departure_time", "tidak boleh sebelum arrival_time")
+		}
+		byTrip[st.TripID] = append(byTrip[st.TripID], st)
+	}
+
+	for _, t := range feed.Trips {
+		times := byTrip[t.ID]
+		if len(times) < 2 {
+			add("trips.txt", t.Line, "trip_id", "trip %s harus memiliki minimal dua stop_times", t.ID)
+			continue
+		}
+		sort.SliceStable(times, func(a, b int) bool { return times[a].Sequence < times[b].Sequence })
+
+		first, last := times[0], times[len(times)-1]
+		if first.Arrival == NoTime && first.Departure == NoTime {
+			add("stop_times.txt", first.Line, "arrival_time", "stop pertama trip %s wajib memiliki waktu", t.ID)
+		}
+		if last.Arrival == NoTime && last.Departure == NoTime {
+			add("stop_times.txt", last.Line, "arrival_time", "stop terakhir trip %s wajib memiliki waktu", t.ID)
+		}
+
+		prev := NoTime
+		for i, st := range times {
+			if i > 0 && st.Sequence == times[i-1].Sequence {
+				add("stop_times.txt", st.Line, "stop_sequence", "duplikat pada trip %s: %d", t.ID, st.Sequence)
+			}
+			if i > 0 && st.HasShapeDist && times[i-1].HasShapeDist && st.ShapeDist < times[i-1].ShapeDist {
+				add("stop_times.txt", st.Line, "shape_dist_traveled", "tidak boleh berkurang sepanjang trip %s", t.ID)
+			}
+			for _, v := range []Time{st.Arrival, st.Departure} {
+				if v == NoTime {
+					continue
+				}
+				if prev != NoTime && v < prev {
+					add("stop_times.txt", st.Line, "arrival_time", "waktu mundur dibanding stop sebelumnya pada trip %s", t.ID)
+					break
+				}
+				prev = v
+			}
+		}
+	}
+
+	return issues
+}
+
+// validRouteType menerima route_type dasar dan extended route type
+func validRouteType(t int) bool {
+	switch {
+	case t >= 0 && t <= 7, t == 11, t == 12:
+		return true
+	case t >= 100 && t <= 1702:
+		return true
+	}
+	return false
+}
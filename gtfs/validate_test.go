@@ -0,0 +1,108 @@
+package gtfs
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// validFeed adalah feed kecil yang lolos validasi: satu rute dengan tiga
+// stop dan satu trip yang melewati tengah malam
+func validFeed() Feed {
+	day := time.Date(2025, 7, 21, 0, 0, 0, 0, time.UTC)
+	c := Calendar{ServiceID: "20250721", Start: day, End: day}
+	c.Days[0] = true
+
+	return Feed{
+		Agencies: []Agency{{ID: "transport-app", Name: "Transport App", URL: "https://example.com", Timezone: "Asia/Jakarta", Lang: "id"}},
+		Routes:   []Route{{ID: "R1", AgencyID: "transport-app", ShortName: "R1", LongName: "Kota - Bandara", Type: RouteTypeBus}},
+		Stops: []Stop{
+			{ID: "H1", Name: "Terminal Kota", Lat: -6.2, Lon: 106.8, Timezone: "Asia/Jakarta"},
+			{ID: "H2", Name: "Tol", Lat: -6.15, Lon: 106.75},
+			{ID: "H3", Name: "Bandara", Lat: -6.12, Lon: 106.65},
+		},
+		Trips: []Trip{{RouteID: "R1", ServiceID: "20250721", ID: "T1", Headsign: "Bandara", BlockID: "B 1234 CD"}},
+		StopTimes: []StopTime{
+			{TripID: "T1", Arrival: 23 * 3600, Departure: 23 * 3600, StopID: "H1", Sequence: 0, HasShapeDist: true},
+			{TripID: "T1", Arrival: 23*3600 + 40*60, Departure: 23*3600 + 45*60, StopID: "H2", Sequence: 1, ShapeDist: 12.5, HasShapeDist: true},
+			{TripID: "T1", Arrival: 24*3600 + 30*60, Departure: 24*3600 + 30*60, StopID: "H3", Sequence: 2, ShapeDist: 30, HasShapeDist: true},
+		},
+		Calendars: []Calendar{c},
+	}
+}
+
+// roundTrip menulis feed sebagai zip lalu membacanya kembali
+func roundTrip(t *testing.T, feed Feed) (Feed, []Issue) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, feed); err != nil {
+		t.Fatal(err)
+	}
+	read, issues, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return read, issues
+}
+
+func TestRoundTrip(t *testing.T) {
+	feed := validFeed()
+	read, issues := roundTrip(t, feed)
+	if len(issues) > 0 {
+		t.Fatalf("feed valid menghasilkan issue: %v", issues)
+	}
+
+	if len(read.Stops) != 3 || len(read.Trips) != 1 || len(read.StopTimes) != 3 || len(read.Calendars) != 1 {
+		t.Fatalf("isi feed berubah: %+v", read)
+	}
+	if got := read.StopTimes[2].Arrival.String(); got != "24:30:00" {
+		t.Fatalf("arrival_time melewati tengah malam menjadi %s, seharusnya 24:30:00", got)
+	}
+	if read.Trips[0].BlockID != "B 1234 CD" || read.StopTimes[1].ShapeDist != 12.5 {
+		t.Fatalf("trip atau stop_times berubah: %+v %+v", read.Trips[0], read.StopTimes[1])
+	}
+	if !read.Calendars[0].Runs(feed.Calendars[0].Start) {
+		t.Fatal("calendar tidak berjalan pada tanggalnya sendiri")
+	}
+}
+
+func TestValidateIssues(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*Feed)
+		file   string
+		field  string
+	}{
+		{"stop_id ganda", func(f *Feed) {
+			f.Stops = append(f.Stops, Stop{ID: "H2", Name: "Tol Lagi", Lat: -6.1, Lon: 106.7})
+		}, "stops.txt", "stop_id"},
+		{"trip merujuk route yang tidak ada", func(f *Feed) {
+			f.Trips[0].RouteID = "R9"
+		}, "trips.txt", "route_id"},
+		{"waktu mundur", func(f *Feed) {
+			f.StopTimes[2].Arrival = 23*3600 + 30*60
+			f.StopTimes[2].Departure = 23*3600 + 30*60
+		}, "stop_times.txt", "arrival_time"},
+		{"stop_times merujuk stop yang tidak ada", func(f *Feed) {
+			f.StopTimes[1].StopID = "H9"
+		}, "stop_times.txt", "stop_id"},
+		{"service tanpa calendar", func(f *Feed) {
+			f.Trips[0].ServiceID = "20250722"
+		}, "trips.txt", "service_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed := validFeed()
+			tt.change(&feed)
+
+			_, issues := roundTrip(t, feed)
+			if len(issues) != 1 {
+				t.Fatalf("dapat %d issue %v, seharusnya 1", len(issues), issues)
+			}
+			issue := issues[0]
+			if issue.File != tt.file || issue.Field != tt.field || issue.Line == 0 {
+				t.Fatalf("issue %q, seharusnya pada %s kolom %s dengan nomor baris", issue.Error(), tt.file, tt.field)
+			}
+		})
+	}
+}
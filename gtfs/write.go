@@ -0,0 +1,96 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatBool(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+// Write menulis feed sebagai zip berisi file CSV GTFS
+func Write(w io.Writer, feed Feed) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name   string
+		header []string
+		rows   func(emit func(...string))
+	}{
+		{"agency.txt", []string{"agency_id", "agency_name", "agency_url", "agency_timezone", "agency_lang"}, func(emit func(...string)) {
+			for _, a := range feed.Agencies {
+				emit(a.ID, a.Name, a.URL, a.Timezone, a.Lang)
+			}
+		}},
+		{"routes.txt", []string{"route_id", "agency_id", "route_short_name", "route_long_name", "route_type"}, func(emit func(...string)) {
+			for _, r := range feed.Routes {
+				emit(r.ID, r.AgencyID, r.ShortName, r.LongName, strconv.Itoa(r.Type))
+			}
+		}},
+		{"stops.txt", []string{"stop_id", "stop_name", "stop_desc", "stop_lat", "stop_lon", "stop_timezone"}, func(emit func(...string)) {
+			for _, s := range feed.Stops {
+				emit(s.ID, s.Name, s.Desc, formatFloat(s.Lat), formatFloat(s.Lon), s.Timezone)
+			}
+		}},
+		{"trips.txt", []string{"route_id", "service_id", "trip_id", "trip_headsign", "block_id"}, func(emit func(...string)) {
+			for _, t := range feed.Trips {
+				emit(t.RouteID, t.ServiceID, t.ID, t.Headsign, t.BlockID)
+			}
+		}},
+		{"stop_times.txt", []string{"trip_id", "arrival_time", "departure_time", "stop_id", "stop_sequence", "shape_dist_traveled"}, func(emit func(...string)) {
+			for _, st := range feed.StopTimes {
+				dist := ""
+				if st.HasShapeDist {
+					dist = formatFloat(st.ShapeDist)
+				}
+				emit(st.TripID, st.Arrival.String(), st.Departure.String(), st.StopID, strconv.Itoa(st.Sequence), dist)
+			}
+		}},
+		{"calendar.txt", []string{"service_id", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "start_date", "end_date"}, func(emit func(...string)) {
+			for _, c := range feed.Calendars {
+				row := []string{c.ServiceID}
+				for _, d := range c.Days {
+					row = append(row, formatBool(d))
+				}
+				emit(append(row, FormatDate(c.Start), FormatDate(c.End))...)
+			}
+		}},
+	}
+
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		cw := csv.NewWriter(fw)
+		// GTFS memakai akhir baris CRLF atau LF; CRLF sesuai RFC 4180
+		cw.UseCRLF = true
+		if err := cw.Write(f.header); err != nil {
+			return err
+		}
+		var writeErr error
+		f.rows(func(fields ...string) {
+			if writeErr == nil {
+				writeErr = cw.Write(fields)
+			}
+		})
+		if writeErr != nil {
+			return writeErr
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
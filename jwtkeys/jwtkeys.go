@@ -0,0 +1,353 @@
+// Package jwtkeys mengelola kunci untuk menandatangani dan memverifikasi
+// JWT. Mode HS256 memakai JWT_SECRET seperti sebelumnya. Mode RS256 dan
+// EdDSA memakai pasangan kunci yang disimpan di store, dikenali lewat kid,
+// dirotasi terjadwal, dan kunci publiknya dipublikasikan sebagai JWKS agar
+// service lain bisa memverifikasi token tanpa mengetahui secret.
+package jwtkeys
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+const (
+	DefaultRotation = 30 * 24 * time.Hour
+
+	// prepublish adalah lama kunci baru dipublikasikan di JWKS sebelum mulai
+	// dipakai, agar service lain sempat mengambil JWKS terbaru
+	prepublish = time.Hour
+	// retain adalah lama kunci lama masih bisa memverifikasi setelah
+	// diganti. Harus lebih lama dari masa berlaku token terlama.
+	retain = time.Hour
+	// reloadJeda membatasi pembacaan ulang store saat menemukan kid yang
+	// belum dikenal, misalnya kunci yang baru dibuat instance lain
+	reloadJeda = 30 * time.Second
+)
+
+// Config menentukan algoritma tanda tangan. Pada mode RS256/EdDSA, Secret
+// hanya dipakai untuk memverifikasi token HS256 lama sampai LegacyUntil;
+// jika LegacyUntil kosong token tanpa kid langsung ditolak.
+type Config struct {
+	Algorithm   string
+	Rotation    time.Duration
+	Secret      []byte
+	LegacyUntil time.Time
+	// EncryptionKey (32 byte) mengenkripsi kunci privat sebelum disimpan.
+	// Jika kosong kunci privat disimpan sebagai PEM biasa, sehingga siapa pun
+	// yang bisa membaca database bisa menandatangani token.
+	EncryptionKey []byte
+}
+
+// ConfigFromEnv membaca JWT_SIGNING_ALG (HS256, RS256 atau EdDSA, default
+// HS256), JWT_KEY_ROTATION (durasi Go, default 720h), JWT_SECRET,
+// JWT_LEGACY_UNTIL (RFC3339, batas token HS256 lama diterima setelah pindah
+// ke RS256/EdDSA) dan JWT_KEY_ENCRYPTION_KEY (32 byte dalam base64)
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Algorithm: os.Getenv("JWT_SIGNING_ALG"),
+		Rotation:  DefaultRotation,
+		Secret:    []byte(os.Getenv("JWT_SECRET")),
+	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = HS256
+	}
+	switch cfg.Algorithm {
+	case HS256, RS256, EdDSA:
+	default:
+		return cfg, fmt.Errorf("JWT_SIGNING_ALG tidak dikenal: %s", cfg.Algorithm)
+	}
+	if v := os.Getenv("JWT_KEY_ROTATION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 2*prepublish {
+			return cfg, fmt.Errorf("JWT_KEY_ROTATION tidak valid: %s", v)
+		}
+		cfg.Rotation = d
+	}
+	if v := os.Getenv("JWT_LEGACY_UNTIL"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return cfg, fmt.Errorf("JWT_LEGACY_UNTIL tidak valid: %s", v)
+		}
+		cfg.LegacyUntil = t
+	}
+	if v := os.Getenv("JWT_KEY_ENCRYPTION_KEY"); v != "" {
+		k, err := base64.StdEncoding.DecodeString(v)
+		if err != nil || len(k) != 32 {
+			return cfg, errors.New("JWT_KEY_ENCRYPTION_KEY harus 32 byte dalam base64")
+		}
+		cfg.EncryptionKey = k
+	}
+	if cfg.Algorithm == HS256 && len(cfg.Secret) == 0 {
+		return cfg, errors.New("JWT_SECRET wajib diisi untuk HS256")
+	}
+	return cfg, nil
+}
+
+type key struct {
+	id         string
+	alg        string
+	signer     crypto.Signer
+	public     crypto.PublicKey
+	activeFrom time.Time
+	retireAt   time.Time
+}
+
+// Manager menyimpan salinan kunci dari store di memori. Aman dipakai
+// bersamaan oleh banyak request.
+type Manager struct {
+	store store.SigningKeyStore
+	cfg   Config
+
+	mu         sync.RWMutex
+	keys       []key // Urut berdasarkan activeFrom
+	lastReload time.Time
+}
+
+func New(s store.SigningKeyStore, cfg Config) *Manager {
+	if cfg.Rotation <= 0 {
+		cfg.Rotation = DefaultRotation
+	}
+	return &Manager{store: s, cfg: cfg}
+}
+
+func (m *Manager) Algorithm() string {
+	return m.cfg.Algorithm
+}
+
+// current mengembalikan kunci terbaru untuk algoritma yang dipakai yang
+// sudah aktif pada waktu now
+func (m *Manager) current(now time.Time) (key, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for i := len(m.keys) - 1; i >= 0; i-- {
+		k := m.keys[i]
+		if k.alg == m.cfg.Algorithm && !k.activeFrom.After(now) && now.Before(k.retireAt) {
+			return k, true
+		}
+	}
+	return key{}, false
+}
+
+func (m *Manager) find(kid string, now time.Time) (key, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, k := range m.keys {
+		if k.id == kid && now.Before(k.retireAt) {
+			return k, true
+		}
+	}
+	return key{}, false
+}
+
+// Sign menandatangani claims dengan kunci aktif. Token RS256/EdDSA
+// membawa kid di header.
+func (m *Manager) Sign(claims jwt.Claims) (string, error) {
+	if m.cfg.Algorithm == HS256 {
+		if len(m.cfg.Secret) == 0 {
+			return "", errors.New("JWT_SECRET kosong")
+		}
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.cfg.Secret)
+	}
+
+	k, ok := m.current(time.Now())
+	if !ok {
+		return "", errors.New("belum ada kunci penandatangan yang aktif")
+	}
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(k.alg), claims)
+	token.Header["kid"] = k.id
+	return token.SignedString(k.signer)
+}
+
+// KeyFunc memilih kunci verifikasi berdasarkan kid dan memastikan
+// algoritma token sama dengan algoritma kunci. Token tanpa kid hanya
+// diterima sebagai HS256 dengan JWT_SECRET, dan pada mode RS256/EdDSA hanya
+// sampai LegacyUntil.
+func (m *Manager) KeyFunc(t *jwt.Token) (interface{}, error) {
+	now := time.Now()
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok && m.legacyAllowed(now) {
+			return m.cfg.Secret, nil
+		}
+		return nil, errors.New("token tidak memiliki kid")
+	}
+
+	k, ok := m.find(kid, now)
+	if !ok && m.reloadAllowed(now) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := m.Reload(ctx); err != nil {
+			return nil, err
+		}
+		k, ok = m.find(kid, now)
+	}
+	if !ok {
+		return nil, fmt.Errorf("kid tidak dikenal: %s", kid)
+	}
+	if t.Method.Alg() != k.alg {
+		return nil, errors.New("algoritma token tidak cocok dengan kunci")
+	}
+	return k.public, nil
+}
+
+func (m *Manager) legacyAllowed(now time.Time) bool {
+	if len(m.cfg.Secret) == 0 {
+		return false
+	}
+	return m.cfg.Algorithm == HS256 || now.Before(m.cfg.LegacyUntil)
+}
+
+func (m *Manager) reloadAllowed(now time.Time) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return now.Sub(m.lastReload) >= reloadJeda
+}
+
+// JWKS mengembalikan kunci publik yang belum pensiun, termasuk kunci yang
+// belum aktif. Mode HS256 tidak punya kunci publik sehingga daftarnya kosong.
+func (m *Manager) JWKS() JWKS {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	set := JWKS{Keys: []JWK{}}
+	for _, k := range m.keys {
+		if now.Before(k.retireAt) {
+			set.Keys = append(set.Keys, toJWK(k))
+		}
+	}
+	return set
+}
+
+// Reload membaca ulang semua kunci dari store
+func (m *Manager) Reload(ctx context.Context) error {
+	now := time.Now()
+	stored, err := m.store.List(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]key, 0, len(stored))
+	for _, s := range stored {
+		private, err := openPrivateKey(m.cfg.EncryptionKey, s.ID, s.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("kunci %s: %w", s.ID, err)
+		}
+		signer, err := parsePrivateKey(s.Algorithm, private)
+		if err != nil {
+			return fmt.Errorf("kunci %s: %w", s.ID, err)
+		}
+		keys = append(keys, key{
+			id:         s.ID,
+			alg:        s.Algorithm,
+			signer:     signer,
+			public:     signer.Public(),
+			activeFrom: s.ActiveFrom,
+			retireAt:   s.RetireAt,
+		})
+	}
+
+	m.mu.Lock()
+	m.keys = keys
+	m.lastReload = now
+	m.mu.Unlock()
+	return nil
+}
+
+// Rotate memastikan ada kunci aktif dan membuat kunci pengganti sebelum
+// masa pakai kunci aktif habis. Kunci pengganti dipublikasikan lebih dulu
+// dan baru dipakai saat kunci lama mencapai masa rotasi.
+func (m *Manager) Rotate(ctx context.Context, now time.Time) error {
+	if m.cfg.Algorithm == HS256 {
+		return nil
+	}
+	if err := m.Reload(ctx); err != nil {
+		return err
+	}
+
+	active, ok := m.current(now)
+	if !ok {
+		return m.create(ctx, now, now)
+	}
+
+	due := active.activeFrom.Add(m.cfg.Rotation)
+	if now.Before(due.Add(-prepublish)) || m.hasSuccessor(active) {
+		return nil
+	}
+	if due.Before(now) {
+		due = now
+	}
+	return m.create(ctx, now, due)
+}
+
+func (m *Manager) hasSuccessor(active key) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, k := range m.keys {
+		if k.alg == active.alg && k.activeFrom.After(active.activeFrom) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) create(ctx context.Context, now, activeFrom time.Time) error {
+	private, err := generateKey(m.cfg.Algorithm)
+	if err != nil {
+		return err
+	}
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	kid := base64.RawURLEncoding.EncodeToString(id)
+	sealed, err := sealPrivateKey(m.cfg.EncryptionKey, kid, private)
+	if err != nil {
+		return err
+	}
+
+	err = m.store.Create(ctx, &models.SigningKey{
+		ID:         kid,
+		Algorithm:  m.cfg.Algorithm,
+		PrivateKey: sealed,
+		CreatedAt:  now,
+		ActiveFrom: activeFrom,
+		RetireAt:   activeFrom.Add(m.cfg.Rotation + retain),
+	})
+	if err != nil {
+		return err
+	}
+	return m.Reload(ctx)
+}
+
+// Start menjalankan Rotate setiap interval. Interval harus lebih pendek
+// dari satu jam agar kunci baru sempat dibaca semua instance sebelum aktif.
+func (m *Manager) Start(interval time.Duration) {
+	go func() {
+		for {
+			time.Sleep(interval)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			if err := m.Rotate(ctx, time.Now()); err != nil {
+				fmt.Println("⚠️ Rotasi kunci JWT gagal:", err)
+			}
+			cancel()
+		}
+	}()
+}
@@ -0,0 +1,179 @@
+package jwtkeys
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"transport-app/store"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var secret = []byte("rahasia-test")
+
+func newManager(t *testing.T, cfg Config) *Manager {
+	t.Helper()
+	m := New(store.NewMemoryStores().SigningKey, cfg)
+	if err := m.Rotate(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func claims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{Subject: "budi", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+}
+
+func verify(m *Manager, token string) error {
+	_, err := jwt.ParseWithClaims(token, &jwt.RegisteredClaims{}, m.KeyFunc)
+	return err
+}
+
+// legacyToken adalah token HS256 tanpa kid dari sebelum pindah ke RS256/EdDSA
+func legacyToken(t *testing.T) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims()).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestSignVerify(t *testing.T) {
+	for _, alg := range []string{HS256, RS256, EdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			m := newManager(t, Config{Algorithm: alg, Secret: secret})
+			token, err := m.Sign(claims())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := verify(m, token); err != nil {
+				t.Fatalf("token sendiri ditolak: %v", err)
+			}
+
+			keys := m.JWKS().Keys
+			if alg == HS256 && len(keys) != 0 || alg != HS256 && len(keys) != 1 {
+				t.Fatalf("JWKS %s berisi %d kunci", alg, len(keys))
+			}
+		})
+	}
+}
+
+func TestKeyFuncRejects(t *testing.T) {
+	m := newManager(t, Config{Algorithm: RS256, Secret: secret})
+	other := newManager(t, Config{Algorithm: RS256})
+
+	t.Run("kid dari manager lain", func(t *testing.T) {
+		token, err := other.Sign(claims())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := verify(m, token); err == nil {
+			t.Fatal("token dengan kid tidak dikenal diterima")
+		}
+	})
+
+	t.Run("algoritma tidak cocok dengan kid", func(t *testing.T) {
+		kid := m.JWKS().Keys[0].Kid
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := verify(m, signed); err == nil {
+			t.Fatal("token HS256 dengan kid RS256 diterima")
+		}
+	})
+}
+
+// TestLegacyToken memastikan token HS256 tanpa kid hanya diterima pada
+// mode RS256/EdDSA sampai LegacyUntil
+func TestLegacyToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		accept bool
+	}{
+		{"mode HS256", Config{Algorithm: HS256, Secret: secret}, true},
+		{"tanpa batas transisi", Config{Algorithm: RS256, Secret: secret}, false},
+		{"sebelum batas transisi", Config{Algorithm: RS256, Secret: secret, LegacyUntil: time.Now().Add(time.Hour)}, true},
+		{"setelah batas transisi", Config{Algorithm: EdDSA, Secret: secret, LegacyUntil: time.Now().Add(-time.Hour)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newManager(t, tt.cfg)
+			err := verify(m, legacyToken(t))
+			if tt.accept && err != nil {
+				t.Fatalf("token lama ditolak: %v", err)
+			}
+			if !tt.accept && err == nil {
+				t.Fatal("token lama diterima")
+			}
+		})
+	}
+}
+
+func TestEncryptedPrivateKey(t *testing.T) {
+	encKey := []byte(strings.Repeat("k", 32))
+	keys := store.NewMemoryStores().SigningKey
+	m := New(keys, Config{Algorithm: EdDSA, EncryptionKey: encKey})
+	ctx := context.Background()
+	if err := m.Rotate(ctx, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := keys.List(ctx, time.Now())
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("kunci tersimpan: %v %v", stored, err)
+	}
+	if strings.Contains(stored[0].PrivateKey, "PRIVATE KEY") {
+		t.Fatal("kunci privat disimpan tanpa enkripsi")
+	}
+
+	token, err := m.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Instance lain dengan kunci enkripsi yang sama bisa memakai kunci itu
+	if err := verify(New(keys, Config{Algorithm: EdDSA, EncryptionKey: encKey}), token); err != nil {
+		t.Fatalf("instance lain menolak token: %v", err)
+	}
+	for name, wrong := range map[string][]byte{"tanpa kunci": nil, "kunci lain": []byte(strings.Repeat("x", 32))} {
+		if err := New(keys, Config{Algorithm: EdDSA, EncryptionKey: wrong}).Reload(ctx); err == nil {
+			t.Fatalf("%s: kunci terenkripsi terbaca", name)
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	m := New(store.NewMemoryStores().SigningKey, Config{Algorithm: RS256, Rotation: 24 * time.Hour})
+	if err := m.Rotate(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := m.current(now)
+
+	// Menjelang masa rotasi, kunci pengganti dipublikasikan tanpa langsung dipakai
+	soon := now.Add(24*time.Hour - prepublish/2)
+	if err := m.Rotate(ctx, soon); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := m.current(soon); got.id != first.id {
+		t.Fatal("kunci pengganti dipakai sebelum waktunya")
+	}
+	if n := len(m.JWKS().Keys); n != 2 {
+		t.Fatalf("JWKS berisi %d kunci, seharusnya 2", n)
+	}
+
+	due := now.Add(24 * time.Hour)
+	if got, _ := m.current(due); got.id == first.id {
+		t.Fatal("kunci lama masih dipakai setelah masa rotasi")
+	}
+	if _, ok := m.find(first.id, due); !ok {
+		t.Fatal("kunci lama tidak bisa memverifikasi setelah diganti")
+	}
+}
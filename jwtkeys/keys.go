@@ -0,0 +1,124 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const rsaKeyBits = 2048
+
+// sealedPrefix menandai kunci privat yang dienkripsi AES-256-GCM. Kunci
+// tanpa prefix adalah PEM biasa dari sebelum enkripsi diaktifkan.
+const sealedPrefix = "aesgcm:"
+
+// generateKey membuat pasangan kunci baru untuk algoritma alg dan
+// mengembalikan kunci privat dalam PEM PKCS#8
+func generateKey(alg string) (string, error) {
+	var private crypto.PrivateKey
+	var err error
+	switch alg {
+	case RS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case EdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return "", fmt.Errorf("algoritma %s tidak memakai pasangan kunci", alg)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// parsePrivateKey membaca PEM PKCS#8 dan memastikan jenisnya sesuai alg
+func parsePrivateKey(alg, data string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("PEM tidak valid")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if alg == RS256 {
+			return k, nil
+		}
+	case ed25519.PrivateKey:
+		if alg == EdDSA {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("jenis kunci tidak cocok dengan algoritma %s", alg)
+}
+
+// sealPrivateKey mengenkripsi PEM dengan encKey dan kid sebagai data
+// tambahan, sehingga isi kunci tidak bisa ditukar antar kid. PEM
+// dikembalikan apa adanya jika encKey kosong.
+func sealPrivateKey(encKey []byte, kid, pemData string) (string, error) {
+	if len(encKey) == 0 {
+		return pemData, nil
+	}
+	gcm, err := newGCM(encKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(pemData), []byte(kid))
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openPrivateKey membalik sealPrivateKey. Kunci PEM biasa tetap bisa dibaca
+// agar kunci lama masih dipakai sampai dirotasi.
+func openPrivateKey(encKey []byte, kid, stored string) (string, error) {
+	if !strings.HasPrefix(stored, sealedPrefix) {
+		return stored, nil
+	}
+	if len(encKey) == 0 {
+		return "", errors.New("kunci terenkripsi tetapi JWT_KEY_ENCRYPTION_KEY kosong")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, sealedPrefix))
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(encKey)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("kunci terenkripsi terlalu pendek")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(kid))
+	if err != nil {
+		return "", errors.New("kunci tidak bisa didekripsi dengan JWT_KEY_ENCRYPTION_KEY")
+	}
+	return string(plain), nil
+}
+
+func newGCM(encKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
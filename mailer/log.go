@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+)
+
+// LogMailer tidak mengirim email, tetapi menuliskannya ke file Path
+// (ditambahkan di akhir file) atau ke log jika Path kosong
+type LogMailer struct {
+	Path string
+	From string
+
+	mu sync.Mutex
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	raw := buildMessage(m.From, msg)
+	if m.Path == "" {
+		log.Printf("📧 Email ke %s:\n%s\n", msg.To, raw)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(raw, "\r\n\r\n"...)); err != nil {
+		return err
+	}
+	return nil
+}
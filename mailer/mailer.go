@@ -0,0 +1,38 @@
+// Package mailer mengirim email transaksional seperti link reset password.
+// Implementasi dipilih lewat MAIL_DRIVER: "smtp" untuk produksi atau "log"
+// (default) yang hanya mencatat email ke log atau file untuk pengembangan.
+package mailer
+
+import (
+	"context"
+	"os"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// FromEnv membuat Mailer berdasarkan variabel lingkungan
+func FromEnv() Mailer {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+
+	if os.Getenv("MAIL_DRIVER") == "smtp" {
+		return &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}
+	}
+	return &LogMailer{Path: os.Getenv("MAIL_LOG_FILE"), From: from}
+}
@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"transport-app/config"
+	"transport-app/jwtkeys"
+	"transport-app/mailer"
 	"transport-app/middleware"
+	"transport-app/oidc"
+	"transport-app/repository"
 	"transport-app/routes"
+	"transport-app/store"
 
 	_ "transport-app/docs"
 
@@ -32,6 +40,10 @@ import (
 // @in header
 // @name Authorization
 // @description Ketik "Bearer" diikuti spasi dan token. Contoh: "Bearer {token}"
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key dari admin untuk integrasi agen tiket dan kios, hanya untuk endpoint baca
 
 func main() {
 	// Load .env file hanya jika TIDAK sedang di Railway
@@ -45,15 +57,73 @@ func main() {
 	}
 
 	config.ConnectDB()
+	if config.DB == nil {
+		log.Fatal("❌ Tidak dapat terhubung ke MongoDB")
+	}
+
+	stores := store.NewMongoStores(config.DB)
+	if err := store.EnsureIndexes(context.Background(), stores); err != nil {
+		log.Println("⚠️ Gagal membuat index:", err)
+	}
+
+	// Kunci JWT dibuat atau dirotasi saat start, lalu dicek berkala agar
+	// kunci pengganti sempat dipublikasikan di JWKS sebelum dipakai
+	keyConfig, err := jwtkeys.ConfigFromEnv()
+	if err != nil {
+		log.Fatal("❌ Konfigurasi JWT tidak valid: ", err)
+	}
+	if keyConfig.Algorithm != jwtkeys.HS256 && len(keyConfig.EncryptionKey) == 0 {
+		log.Println("⚠️ JWT_KEY_ENCRYPTION_KEY kosong, kunci privat JWT disimpan tanpa enkripsi di database")
+	}
+	keys := jwtkeys.New(stores.SigningKey, keyConfig)
+	if err := keys.Rotate(context.Background(), time.Now()); err != nil {
+		log.Fatal("❌ Gagal menyiapkan kunci JWT: ", err)
+	}
+	keys.Start(5 * time.Minute)
 
-	app := fiber.New()
+	handler := repository.NewHandler(stores, mailer.FromEnv(), keys)
+	if err := handler.SeedRoles(context.Background()); err != nil {
+		log.Println("⚠️ Gagal membuat role bawaan:", err)
+	}
+
+	// Login lewat identity provider hanya aktif jika OIDC_ISSUER diisi
+	oidcConfig, err := oidc.ConfigFromEnv()
+	switch {
+	case err == nil:
+		handler.OIDC = oidc.New(oidcConfig)
+	case err != oidc.ErrDisabled:
+		log.Fatal("❌ Konfigurasi OIDC tidak valid: ", err)
+	}
+
+	// Jadwal dari template dibuat ulang setiap hari untuk horizon ke depan
+	handler.StartJadwalGenerator(24 * time.Hour)
+
+	// Di belakang reverse proxy (mis. Railway) isi PROXY_HEADER dengan header
+	// IP client yang ditimpa proxy (mis. X-Real-IP) dan TRUSTED_PROXIES dengan
+	// IP atau CIDR proxy, dipisah koma, agar pembatasan login per IP memakai
+	// IP client. Header dari request yang tidak lewat proxy tersebut diabaikan
+	// sehingga client tidak bisa memalsukan IP-nya.
+	appConfig := fiber.Config{ProxyHeader: os.Getenv("PROXY_HEADER")}
+	if appConfig.ProxyHeader != "" {
+		for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				appConfig.TrustedProxies = append(appConfig.TrustedProxies, p)
+			}
+		}
+		if len(appConfig.TrustedProxies) == 0 {
+			log.Fatal("❌ PROXY_HEADER membutuhkan TRUSTED_PROXIES")
+		}
+		appConfig.EnableTrustedProxyCheck = true
+		appConfig.EnableIPValidation = true
+	}
+	app := fiber.New(appConfig)
 
 	middleware.SetupCORS(app)
 	middleware.SetupLogger(app)
 
 	app.Get("/docs/*", swagger.HandlerDefault)
 
-	routes.SetupRoutes(app)
+	routes.SetupRoutes(app, handler)
 
 	// WAJIB pakai PORT dari env agar Railway bisa deteksi ini web service
 	port := os.Getenv("PORT")
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyHeader adalah header tempat sistem lain mengirim API key
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyLocal adalah nama Locals untuk API key yang sudah divalidasi
+const apiKeyLocal = "api_key"
+
+// lastUsedJeda membatasi penulisan last_used_at agar tidak terjadi di
+// setiap request
+const lastUsedJeda = time.Minute
+
+// ProtectedOrAPIKey menerima API key di header X-API-Key sebagai ganti
+// Bearer JWT. Request tanpa header tersebut diteruskan ke protected.
+// Permission API key diperiksa dari scope-nya oleh RequirePermission.
+func ProtectedOrAPIKey(protected fiber.Handler, keys store.APIKeyStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		raw := c.Get(APIKeyHeader)
+		if raw == "" {
+			return protected(c)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		now := time.Now()
+		key, err := keys.GetByHash(ctx, models.HashAPIKey(raw))
+		if err != nil && err != store.ErrNotFound {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		if err == store.ErrNotFound || !key.Active(now) {
+			return c.Status(fiber.StatusUnauthorized).
+				JSON(fiber.Map{"status": "error", "message": "Invalid or expired API key", "data": nil})
+		}
+
+		if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedJeda {
+			if err := keys.TouchLastUsed(ctx, key.ID, now); err != nil {
+				fmt.Println("❌ Gagal mencatat pemakaian API key:", err)
+			}
+		}
+
+		c.Locals(apiKeyLocal, key)
+		return c.Next()
+	}
+}
@@ -1,29 +1,88 @@
 package middleware
 
 import (
+	"context"
 	"os"
+	"time"
+	"transport-app/models"
+	"transport-app/store"
 
 	"github.com/gofiber/fiber/v2"
 	jwtware "github.com/gofiber/jwt/v3"
 	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// Fungsi untk melindungi rute yang membutuhkan autentikasi
-func Protected() fiber.Handler {
+// Fungsi untk melindungi rute yang membutuhkan autentikasi. Selain tanda
+// tangan dan masa berlaku, session token (claim "sid") harus masih aktif
+// sehingga token langsung tidak berlaku setelah logout. keyFunc memilih
+// kunci verifikasi dari kid token (lihat jwtkeys.Manager.KeyFunc).
+func Protected(keyFunc jwt.Keyfunc, sessions store.SessionStore) fiber.Handler {
 	return jwtware.New(jwtware.Config{
-		SigningKey:   []byte(os.Getenv("JWT_SECRET")),
+		KeyFunc:      keyFunc,
 		ErrorHandler: jwtError,
+		SuccessHandler: func(c *fiber.Ctx) error {
+			if !sessionActive(c, sessions) {
+				return c.Status(fiber.StatusUnauthorized).
+					JSON(fiber.Map{"status": "error", "message": "Session has been revoked", "data": nil})
+			}
+			return c.Next()
+		},
 	})
 }
 
+// claimString mengambil claim bertipe string dari token yang sudah
+// divalidasi, string kosong jika token atau claim tidak ada
+func claimString(c *fiber.Ctx, key string) string {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	value, _ := claims[key].(string)
+	return value
+}
+
+func claimBool(c *fiber.Ctx, key string) bool {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	value, _ := claims[key].(bool)
+	return value
+}
+
+// adminMFARequired membaca REQUIRE_ADMIN_2FA. Jika aktif, token admin yang
+// login tanpa kode TOTP hanya bisa dipakai untuk endpoint tanpa permission,
+// termasuk untuk mengaktifkan 2FA.
+func adminMFARequired() bool {
+	return os.Getenv("REQUIRE_ADMIN_2FA") == "true"
+}
+
+func sessionActive(c *fiber.Ctx, sessions store.SessionStore) bool {
+	sessionID, err := primitive.ObjectIDFromHex(claimString(c, "sid"))
+	if err != nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, err := sessions.Get(ctx, sessionID)
+	return err == nil && session.Active(time.Now())
+}
+
 // hanya admin yang bisa akses
 func AdminOnly() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		user := c.Locals("user").(*jwt.Token)
-		claims := user.Claims.(jwt.MapClaims)
-		role := claims["role"].(string)
-
-		if role != "admin" {
+		if claimString(c, "role") != models.RoleAdmin {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"error": "Forbidden: Admin access required",
 			})
@@ -32,6 +91,54 @@ func AdminOnly() fiber.Handler {
 	}
 }
 
+// RequirePermission hanya meneruskan request jika role user memiliki semua
+// permission yang diminta. Permission role dibaca dari database setiap
+// request sehingga perubahan role langsung berlaku. Request dengan API key
+// (lihat ProtectedOrAPIKey) diperiksa dari scope key.
+func RequirePermission(roles store.RoleStore, permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		forbidden := func(body fiber.Map) error {
+			return c.Status(fiber.StatusForbidden).JSON(body)
+		}
+
+		// API key tidak punya role; aksesnya dibatasi scope yang diberikan
+		if key, ok := c.Locals(apiKeyLocal).(models.APIKey); ok {
+			for _, p := range permissions {
+				if !key.HasScope(p) {
+					return forbidden(fiber.Map{"error": "Forbidden: scope " + p + " diperlukan"})
+				}
+			}
+			return c.Next()
+		}
+
+		name := claimString(c, "role")
+		if name == "" {
+			return forbidden(fiber.Map{"error": "Forbidden: role tidak ditemukan"})
+		}
+		if name == models.RoleAdmin && adminMFARequired() && !claimBool(c, "mfa") {
+			return forbidden(fiber.Map{"error": "Forbidden: admin wajib login dengan 2FA, aktifkan lewat /api/me/2fa/setup"})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		role, err := roles.GetByName(ctx, name)
+		if err == store.ErrNotFound {
+			return forbidden(fiber.Map{"error": "Forbidden: role tidak dikenal"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		for _, p := range permissions {
+			if !role.Has(p) {
+				return forbidden(fiber.Map{"error": "Forbidden: permission " + p + " diperlukan"})
+			}
+		}
+		return c.Next()
+	}
+}
+
 func jwtError(c *fiber.Ctx, err error) error {
 	if err.Error() == "Missing or malformed JWT" {
 		return c.Status(fiber.StatusBadRequest).
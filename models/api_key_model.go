@@ -0,0 +1,64 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKeyPrefix menandai awal API key agar mudah dikenali, misalnya oleh
+// secret scanner
+const APIKeyPrefix = "tk_"
+
+// APIKeyScopes adalah permission yang boleh diberikan ke API key. API key
+// hanya dipakai integrasi agen tiket dan kios untuk membaca data.
+var APIKeyScopes = []string{PermRuteRead, PermKendaraanRead, PermJadwalRead}
+
+// APIKey dipakai sistem lain untuk mengakses API tanpa login user. Hanya
+// hash key yang disimpan; key lengkap hanya ditampilkan sekali saat dibuat.
+type APIKey struct {
+	ID         primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Name       string             `json:"name" bson:"name"`
+	Prefix     string             `json:"prefix" bson:"prefix"` // Beberapa karakter awal key untuk dikenali di daftar
+	KeyHash    string             `json:"-" bson:"key_hash"`
+	Scopes     []string           `json:"scopes" bson:"scopes"`
+	CreatedBy  primitive.ObjectID `json:"created_by" bson:"created_by"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	ExpiresAt  *time.Time         `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	LastUsedAt *time.Time         `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	RevokedAt  *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// Active memeriksa apakah key belum dicabut dan belum kedaluwarsa
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// HasScope memeriksa apakah key boleh dipakai untuk permission tersebut
+func (k APIKey) HasScope(permission string) bool {
+	for _, s := range k.Scopes {
+		if s == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAPIKeyScope memeriksa apakah permission boleh diberikan ke API key
+func IsAPIKeyScope(permission string) bool {
+	for _, s := range APIKeyScopes {
+		if s == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// HashAPIKey menghitung hash key yang disimpan di database. SHA-256 cukup
+// karena key berisi 32 byte acak, bukan password yang bisa ditebak.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	BookingStatusConfirmed = "confirmed"
+	BookingStatusCancelled = "cancelled"
+)
+
+type Booking struct {
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	JadwalID    primitive.ObjectID `json:"jadwal_id" bson:"jadwal_id"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	JumlahKursi int                `json:"jumlah_kursi" bson:"jumlah_kursi"`
+	Status      string             `json:"status" bson:"status"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	// Halte naik dan turun, kosong jika penumpang ikut dari asal sampai tujuan
+	NaikHalteID  *primitive.ObjectID `json:"naik_halte_id,omitempty" bson:"naik_halte_id,omitempty"`
+	TurunHalteID *primitive.ObjectID `json:"turun_halte_id,omitempty" bson:"turun_halte_id,omitempty"`
+}
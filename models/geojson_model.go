@@ -0,0 +1,46 @@
+package models
+
+import "transport-app/geo"
+
+// LineString adalah geometri GeoJSON berupa garis. Setiap koordinat
+// disimpan sebagai [lng, lat] sesuai urutan GeoJSON.
+type LineString struct {
+	Type        string      `json:"type" bson:"type" example:"LineString"`
+	Coordinates [][]float64 `json:"coordinates" bson:"coordinates"`
+}
+
+func NewLineString(points []geo.Point) *LineString {
+	coords := make([][]float64, len(points))
+	for i, p := range points {
+		coords[i] = []float64{p.Lng, p.Lat}
+	}
+	return &LineString{Type: "LineString", Coordinates: coords}
+}
+
+func (l LineString) Points() []geo.Point {
+	points := make([]geo.Point, 0, len(l.Coordinates))
+	for _, c := range l.Coordinates {
+		if len(c) >= 2 {
+			points = append(points, geo.Point{Lat: c[1], Lng: c[0]})
+		}
+	}
+	return points
+}
+
+// RuteFeature adalah rute dalam bentuk GeoJSON Feature untuk klien peta.
+// Geometry bernilai null jika rute belum memiliki geometri.
+type RuteFeature struct {
+	Type       string         `json:"type" example:"Feature"`
+	ID         string         `json:"id"`
+	Geometry   *LineString    `json:"geometry"`
+	Properties RuteProperties `json:"properties"`
+}
+
+type RuteProperties struct {
+	KodeRute  string `json:"kode_rute"`
+	NamaRute  string `json:"nama_rute"`
+	Asal      string `json:"asal"`
+	Tujuan    string `json:"tujuan"`
+	JarakKM   int    `json:"jarak_km"`
+	ZonaWaktu string `json:"zona_waktu"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GeoPoint adalah titik GeoJSON. Urutan koordinat mengikuti GeoJSON:
+// [longitude, latitude].
+type GeoPoint struct {
+	Type        string    `json:"type" bson:"type"`
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+}
+
+func NewGeoPoint(lat, lng float64) GeoPoint {
+	return GeoPoint{Type: "Point", Coordinates: []float64{lng, lat}}
+}
+
+func (p GeoPoint) Lat() float64 {
+	if len(p.Coordinates) < 2 {
+		return 0
+	}
+	return p.Coordinates[1]
+}
+
+func (p GeoPoint) Lng() float64 {
+	if len(p.Coordinates) < 1 {
+		return 0
+	}
+	return p.Coordinates[0]
+}
+
+// Halte adalah tempat naik dan turun penumpang. Kota dipakai untuk
+// mencocokkan halte pertama dan terakhir dengan Asal dan Tujuan rute.
+type Halte struct {
+	ID     primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Kode   string             `json:"kode" bson:"kode"`
+	Nama   string             `json:"nama" bson:"nama"`
+	Kota   string             `json:"kota" bson:"kota"`
+	Lokasi GeoPoint           `json:"lokasi" bson:"lokasi"`
+}
+
+// RuteHalte adalah satu pemberhentian pada rute, disimpan berurutan dari
+// asal ke tujuan
+type RuteHalte struct {
+	HalteID    primitive.ObjectID `json:"halte_id" bson:"halte_id"`
+	JarakKM    float64            `json:"jarak_km" bson:"jarak_km"`       // Kumulatif dari halte pertama
+	DwellMenit int                `json:"dwell_menit" bson:"dwell_menit"` // Lama berhenti di halte ini
+}
+
+// JadwalStop adalah waktu tiba dan berangkat satu jadwal di satu halte
+type JadwalStop struct {
+	Urutan    int       `json:"urutan"`
+	Halte     Halte     `json:"halte"`
+	JarakKM   float64   `json:"jarak_km"`
+	Tiba      time.Time `json:"tiba"`
+	Berangkat time.Time `json:"berangkat"`
+}
+
+// HalteTerdekat adalah halte hasil pencarian lokasi beserta jaraknya dari
+// titik yang dicari
+type HalteTerdekat struct {
+	Halte  `bson:",inline"`
+	JarakM float64 `json:"jarak_m" bson:"jarak_m"`
+}
+
+// RuteTerdekat adalah rute yang melewati halte di dekat titik yang dicari
+type RuteTerdekat struct {
+	Rute   Rute    `json:"rute"`
+	Halte  Halte   `json:"halte"` // Halte rute yang paling dekat
+	JarakM float64 `json:"jarak_m"`
+}
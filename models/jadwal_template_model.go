@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JadwalTemplate adalah pola keberangkatan berulang yang dipakai untuk
+// membuat dokumen Jadwal secara otomatis.
+type JadwalTemplate struct {
+	ID                  primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	RuteID              primitive.ObjectID `json:"rute_id" bson:"rute_id"`
+	KendaraanID         primitive.ObjectID `json:"kendaraan_id" bson:"kendaraan_id"`
+	Pengemudi           string             `json:"pengemudi,omitempty" bson:"pengemudi,omitempty"`
+	JamBerangkat        string             `json:"jam_berangkat" bson:"jam_berangkat"` // HH:MM pada zona waktu rute
+	DurasiMenit         int                `json:"durasi_menit" bson:"durasi_menit"`
+	HariOperasi         []int              `json:"hari_operasi" bson:"hari_operasi"` // 0 = Minggu, 6 = Sabtu
+	TanggalMulai        time.Time          `json:"tanggal_mulai" bson:"tanggal_mulai"`
+	TanggalSelesai      *time.Time         `json:"tanggal_selesai,omitempty" bson:"tanggal_selesai,omitempty"`
+	TanggalPengecualian []time.Time        `json:"tanggal_pengecualian" bson:"tanggal_pengecualian"` // Mis. hari libur nasional
+	Aktif               bool               `json:"aktif" bson:"aktif"`
+}
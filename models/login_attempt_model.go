@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// LoginAttempt menghitung login gagal untuk satu kunci, yaitu username
+// ("user:<username>") atau alamat IP ("ip:<alamat>"). Dokumen dihapus
+// otomatis setelah ExpiresAt sehingga hitungan mulai dari nol lagi.
+type LoginAttempt struct {
+	Key         string    `json:"key" bson:"_id"`
+	Failures    int       `json:"failures" bson:"failures"`
+	LastFailure time.Time `json:"last_failure" bson:"last_failure"`
+	LockedUntil time.Time `json:"locked_until,omitempty" bson:"locked_until,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// Locked memeriksa apakah kunci masih dikunci pada waktu now
+func (a LoginAttempt) Locked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
@@ -0,0 +1,89 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Permission yang dikenal aplikasi. PermissionAll memberi semua akses.
+const (
+	PermissionAll = "*"
+
+	PermRuteRead       = "rute:read"
+	PermRuteWrite      = "rute:write"
+	PermKendaraanRead  = "kendaraan:read"
+	PermKendaraanWrite = "kendaraan:write"
+	PermJadwalRead     = "jadwal:read"
+	PermJadwalWrite    = "jadwal:write"
+	PermTemplateWrite  = "jadwal_template:write"
+	PermBookingCreate  = "booking:create"
+	PermBookingRead    = "booking:read"   // Melihat booking semua user
+	PermBookingManage  = "booking:manage" // Membatalkan booking semua user
+	PermRoleManage     = "role:manage"
+	PermUserManage     = "user:manage"
+	PermAPIKeyManage   = "api_key:manage"
+)
+
+var AllPermissions = []string{
+	PermRuteRead, PermRuteWrite,
+	PermKendaraanRead, PermKendaraanWrite,
+	PermJadwalRead, PermJadwalWrite, PermTemplateWrite,
+	PermBookingCreate, PermBookingRead, PermBookingManage,
+	PermRoleManage, PermUserManage, PermAPIKeyManage,
+}
+
+const (
+	RoleAdmin      = "admin"
+	RoleUser       = "user"
+	RoleOperator   = "operator"
+	RoleDispatcher = "dispatcher"
+	RoleDriver     = "driver"
+	RoleFinance    = "finance"
+)
+
+// Role adalah kumpulan permission yang diberikan ke user lewat field
+// User.Role. Role bawaan dibuat saat start dan tidak bisa dihapus.
+type Role struct {
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	Permissions []string           `json:"permissions" bson:"permissions"`
+	BuiltIn     bool               `json:"built_in" bson:"built_in"`
+}
+
+// Has memeriksa apakah role memiliki permission tersebut
+func (r Role) Has(permission string) bool {
+	for _, p := range r.Permissions {
+		if p == permission || p == PermissionAll {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPermission memeriksa apakah nama permission dikenal aplikasi
+func IsPermission(permission string) bool {
+	if permission == PermissionAll {
+		return true
+	}
+	for _, p := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+var readPermissions = []string{PermRuteRead, PermKendaraanRead, PermJadwalRead}
+
+// DefaultRoles adalah role bawaan beserta permission awalnya
+func DefaultRoles() []Role {
+	with := func(extra ...string) []string {
+		return append(append([]string{}, readPermissions...), extra...)
+	}
+	return []Role{
+		{Name: RoleAdmin, Description: "Akses penuh", Permissions: []string{PermissionAll}, BuiltIn: true},
+		{Name: RoleUser, Description: "Penumpang", Permissions: with(PermBookingCreate), BuiltIn: true},
+		{Name: RoleOperator, Description: "Mengelola rute, kendaraan dan jadwal", Permissions: with(PermRuteWrite, PermKendaraanWrite, PermJadwalWrite, PermTemplateWrite), BuiltIn: true},
+		{Name: RoleDispatcher, Description: "Mengatur jadwal dan penugasan kendaraan", Permissions: with(PermJadwalWrite, PermBookingRead, PermBookingManage), BuiltIn: true},
+		{Name: RoleDriver, Description: "Melihat jadwal dan kendaraan", Permissions: with(), BuiltIn: true},
+		{Name: RoleFinance, Description: "Melihat data booking", Permissions: with(PermBookingRead), BuiltIn: true},
+	}
+}
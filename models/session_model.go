@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	SessionRevokedLogout   = "logout"
+	SessionRevokedReuse    = "reuse"
+	SessionRevokedReset    = "password_reset"
+	SessionRevokedDisabled = "disabled"
+	SessionRevokedDeleted  = "user_deleted"
+	SessionRevokedChange   = "password_change"
+)
+
+// Session adalah satu login yang masih bisa diperpanjang dengan refresh
+// token. Refresh token hanya disimpan dalam bentuk hash.
+type Session struct {
+	ID            primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserID        primitive.ObjectID `json:"user_id" bson:"user_id"`
+	RefreshHash   string             `json:"-" bson:"refresh_hash"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	LastUsedAt    time.Time          `json:"last_used_at" bson:"last_used_at"`
+	ExpiresAt     time.Time          `json:"expires_at" bson:"expires_at"`
+	RevokedAt     *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	RevokedReason string             `json:"revoked_reason,omitempty" bson:"revoked_reason,omitempty"`
+	// MFA berarti login session ini sudah diverifikasi dengan kode TOTP
+	MFA bool `json:"mfa" bson:"mfa"`
+}
+
+// Active berarti session belum dicabut dan belum kedaluwarsa
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// SigningKey adalah kunci privat untuk menandatangani JWT, dikenali lewat
+// kid di header token. Kunci dipublikasikan di JWKS sejak dibuat, dipakai
+// untuk menandatangani mulai ActiveFrom, dan dihapus setelah RetireAt
+// ketika semua token yang ditandatanganinya sudah kedaluwarsa.
+type SigningKey struct {
+	ID         string    `json:"kid" bson:"_id"`
+	Algorithm  string    `json:"alg" bson:"alg"`
+	PrivateKey string    `json:"-" bson:"private_key"` // PEM PKCS#8
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	ActiveFrom time.Time `json:"active_from" bson:"active_from"`
+	RetireAt   time.Time `json:"retire_at" bson:"retire_at"`
+}
@@ -1,14 +1,25 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type Rute struct {
-	ID       primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	KodeRute string             `json:"kode_rute" bson:"kode_rute"`
-	NamaRute string             `json:"nama_rute" bson:"nama_rute"`
-	Asal     string             `json:"asal" bson:"asal"`
-	Tujuan   string             `json:"tujuan" bson:"tujuan"`
-	JarakKM  int                `json:"jarak_km" bson:"jarak_km"`
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	KodeRute  string             `json:"kode_rute" bson:"kode_rute"`
+	NamaRute  string             `json:"nama_rute" bson:"nama_rute"`
+	Asal      string             `json:"asal" bson:"asal"`
+	Tujuan    string             `json:"tujuan" bson:"tujuan"`
+	JarakKM   int                `json:"jarak_km" bson:"jarak_km"`
+	ZonaWaktu string             `json:"zona_waktu" bson:"zona_waktu"` // Nama IANA, mis. Asia/Jakarta (WIB)
+	// Halte berisi pemberhentian berurutan dari asal ke tujuan. Kosong untuk
+	// rute tanpa halte antara.
+	Halte []RuteHalte `json:"halte,omitempty" bson:"halte,omitempty"`
+	// Geometri adalah jalur rute. Tidak ikut di JSON rute agar daftar rute
+	// tetap ringan; klien peta mengambilnya lewat /api/rutes/{id}/geojson.
+	Geometri *LineString `json:"-" bson:"geometri,omitempty"`
 }
 
 type Kendaraan struct {
@@ -20,10 +31,35 @@ type Kendaraan struct {
 }
 
 type Jadwal struct {
-	ID             primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	Tanggal        string             `json:"tanggal" bson:"tanggal"`
-	WaktuBerangkat string             `json:"waktu_berangkat" bson:"waktu_berangkat"`
-	EstimasiTiba   string             `json:"estimasi_tiba" bson:"estimasi_tiba"`
-	RuteID         primitive.ObjectID `json:"rute_id" bson:"rute_id"`
-	KendaraanID    primitive.ObjectID `json:"kendaraan_id" bson:"kendaraan_id"`
+	ID             primitive.ObjectID  `json:"_id,omitempty" bson:"_id,omitempty"`
+	Tanggal        time.Time           `json:"tanggal" bson:"tanggal"` // Tengah malam pada zona waktu rute
+	WaktuBerangkat time.Time           `json:"waktu_berangkat" bson:"waktu_berangkat"`
+	EstimasiTiba   time.Time           `json:"estimasi_tiba" bson:"estimasi_tiba"`
+	RuteID         primitive.ObjectID  `json:"rute_id" bson:"rute_id"`
+	KendaraanID    primitive.ObjectID  `json:"kendaraan_id" bson:"kendaraan_id"`
+	Pengemudi      string              `json:"pengemudi,omitempty" bson:"pengemudi,omitempty"`
+	KursiTerisi    int                 `json:"kursi_terisi" bson:"kursi_terisi"`
+	TemplateID     *primitive.ObjectID `json:"template_id,omitempty" bson:"template_id,omitempty"` // Diisi jika dibuat dari JadwalTemplate
+	GTFS           *JadwalGTFS         `json:"gtfs,omitempty" bson:"gtfs,omitempty"`               // Diisi jika dibuat dari impor GTFS
+}
+
+// JadwalGTFS menunjuk trip GTFS asal jadwal hasil impor, sehingga impor
+// berikutnya bisa memperbarui atau menghapusnya walaupun waktunya berubah
+type JadwalGTFS struct {
+	TripID  string `json:"trip_id" bson:"trip_id"`
+	Tanggal string `json:"tanggal" bson:"tanggal"` // Tanggal layanan GTFS (YYYYMMDD)
+}
+
+// JadwalWithRute is a struct to combine Jadwal with its related Rute and Kendaraan
+type JadwalWithRute struct {
+	ID             string    `json:"id" bson:"_id"`
+	Tanggal        time.Time `json:"tanggal" bson:"tanggal"`
+	WaktuBerangkat time.Time `json:"waktu_berangkat" bson:"waktu_berangkat"`
+	EstimasiTiba   time.Time `json:"estimasi_tiba" bson:"estimasi_tiba"`
+	RuteID         string    `json:"rute_id" bson:"rute_id"`
+	KendaraanID    string    `json:"kendaraan_id" bson:"kendaraan_id"`
+	Pengemudi      string    `json:"pengemudi,omitempty" bson:"pengemudi,omitempty"`
+	KursiTerisi    int       `json:"kursi_terisi" bson:"kursi_terisi"`
+	Rute           Rute      `json:"rute" bson:"rute"`
+	Kendaraan      Kendaraan `json:"kendaraan" bson:"kendaraan"`
 }
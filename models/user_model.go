@@ -3,9 +3,62 @@ package models
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
 type User struct {
-	ID       primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	Username string             `json:"username" bson:"username"`
-	Email    string             `json:"email" bson:"email"` 
-	Password string             `json:"password" bson:"password"`
-	Role     string             `json:"role" bson:"role"`
+	ID            primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Username      string             `json:"username" bson:"username"`
+	Email         string             `json:"email" bson:"email"`
+	Password      string             `json:"password" bson:"password"`
+	Role          string             `json:"role" bson:"role"`
+	EmailVerified bool               `json:"email_verified" bson:"email_verified"`
+	// Disabled menolak login dan refresh token sampai diaktifkan lagi admin
+	Disabled bool `json:"disabled" bson:"disabled"`
+	// MustResetPassword diisi admin untuk memaksa user mengganti password
+	// lewat link reset sebelum bisa login lagi
+	MustResetPassword bool `json:"must_reset_password" bson:"must_reset_password"`
+	TOTP              TOTP `json:"-" bson:"totp"`
+	// OIDC diisi untuk user yang login lewat identity provider
+	OIDC *ExternalIdentity `json:"-" bson:"oidc,omitempty"`
+}
+
+// ExternalIdentity menghubungkan user dengan akun di identity provider.
+// Subject hanya unik di dalam satu issuer.
+type ExternalIdentity struct {
+	Issuer  string `bson:"issuer"`
+	Subject string `bson:"subject"`
+}
+
+// TOTP menyimpan pengaturan two-factor authentication user. Secret diisi
+// saat setup dan baru dipakai saat login setelah Enabled. Recovery code
+// hanya disimpan dalam bentuk hash.
+type TOTP struct {
+	Secret        string   `bson:"secret,omitempty"`
+	Enabled       bool     `bson:"enabled"`
+	RecoveryCodes []string `bson:"recovery_codes,omitempty"`
+	// LastStep adalah periode kode terakhir yang dipakai agar kode yang
+	// sama tidak bisa dipakai dua kali
+	LastStep int64 `bson:"last_step,omitempty"`
+}
+
+// PublicUser adalah data user yang aman dikirim ke client, tanpa hash password
+type PublicUser struct {
+	ID                primitive.ObjectID `json:"_id"`
+	Username          string             `json:"username"`
+	Email             string             `json:"email"`
+	Role              string             `json:"role"`
+	EmailVerified     bool               `json:"email_verified"`
+	Disabled          bool               `json:"disabled"`
+	MustResetPassword bool               `json:"must_reset_password"`
+	TwoFactorEnabled  bool               `json:"two_factor_enabled"`
+}
+
+func (u User) Public() PublicUser {
+	return PublicUser{
+		ID:                u.ID,
+		Username:          u.Username,
+		Email:             u.Email,
+		Role:              u.Role,
+		EmailVerified:     u.EmailVerified,
+		Disabled:          u.Disabled,
+		MustResetPassword: u.MustResetPassword,
+		TwoFactorEnabled:  u.TOTP.Enabled,
+	}
 }
@@ -0,0 +1,127 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// jwksJeda membatasi pengambilan ulang JWKS saat menemukan kid yang belum
+// dikenal, misalnya setelah provider merotasi kunci
+const jwksJeda = time.Minute
+
+// Claims adalah claim ID token yang dipakai untuk memetakan user
+type Claims struct {
+	jwt.RegisteredClaims
+	Nonce             string   `json:"nonce"`
+	AuthorizedParty   string   `json:"azp"`
+	Email             string   `json:"email"`
+	EmailVerified     bool     `json:"email_verified"`
+	PreferredUsername string   `json:"preferred_username"`
+	Name              string   `json:"name"`
+	AMR               []string `json:"amr"`
+}
+
+// MFA memeriksa apakah provider melaporkan login dengan lebih dari satu
+// faktor lewat claim amr (RFC 8176)
+func (c Claims) MFA() bool {
+	for _, m := range c.AMR {
+		switch m {
+		case "mfa", "otp", "hwk", "swk", "fido":
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Provider) verify(ctx context.Context, raw, nonce string) (Claims, error) {
+	var claims Claims
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}))
+	_, err := parser.ParseWithClaims(raw, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return claims, fmt.Errorf("id_token tidak valid: %w", err)
+	}
+
+	if claims.Issuer != p.cfg.Issuer && claims.Issuer != p.cfg.Issuer+"/" {
+		return claims, errors.New("issuer id_token tidak cocok")
+	}
+	if !claims.VerifyAudience(p.cfg.ClientID, true) {
+		return claims, errors.New("audience id_token tidak cocok")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID {
+		return claims, errors.New("azp id_token tidak cocok")
+	}
+	if claims.ExpiresAt == nil {
+		return claims, errors.New("id_token tidak memiliki exp")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return claims, errors.New("nonce id_token tidak cocok")
+	}
+	if claims.Subject == "" {
+		return claims, errors.New("id_token tidak memiliki sub")
+	}
+	return claims, nil
+}
+
+// key mencari kunci publik provider berdasarkan kid. JWKS diambil ulang
+// jika kid belum dikenal.
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	k, ok := p.lookup(kid)
+	stale := time.Since(p.fetched) >= jwksJeda
+	p.mu.Unlock()
+	if ok {
+		return k, nil
+	}
+	if !stale {
+		return nil, fmt.Errorf("kid tidak dikenal: %s", kid)
+	}
+
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var set jwks
+	if err := p.getJSON(ctx, meta.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("JWKS OIDC: %w", err)
+	}
+	keys := map[string]interface{}{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		pub, err := jwk.publicKey()
+		if err != nil {
+			continue // Jenis kunci yang tidak didukung dilewati
+		}
+		keys[jwk.Kid] = pub
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = keys
+	p.fetched = time.Now()
+	if k, ok := p.lookup(kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("kid tidak dikenal: %s", kid)
+}
+
+// lookup harus dipanggil dengan p.mu terkunci. Token tanpa kid hanya
+// diterima jika provider punya tepat satu kunci.
+func (p *Provider) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	k, ok := p.keys[kid]
+	return k, ok
+}
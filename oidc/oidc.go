@@ -0,0 +1,226 @@
+// Package oidc adalah client OpenID Connect minimal untuk login lewat
+// identity provider perusahaan memakai authorization code flow dengan
+// PKCE. Endpoint provider dibaca dari discovery document issuer dan tanda
+// tangan ID token diverifikasi dengan JWKS provider.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDisabled dikembalikan ConfigFromEnv jika OIDC_ISSUER tidak diisi
+var ErrDisabled = errors.New("login OIDC tidak dikonfigurasi")
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string // Kosong untuk public client yang hanya memakai PKCE
+	RedirectURL  string
+	Scopes       []string
+}
+
+// ConfigFromEnv membaca OIDC_ISSUER, OIDC_CLIENT_ID, OIDC_CLIENT_SECRET,
+// OIDC_REDIRECT_URL dan OIDC_SCOPES (dipisah spasi, default
+// "openid email profile")
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Issuer:       strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       strings.Fields(os.Getenv("OIDC_SCOPES")),
+	}
+	if cfg.Issuer == "" {
+		return cfg, ErrDisabled
+	}
+	if cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return cfg, errors.New("OIDC_CLIENT_ID dan OIDC_REDIRECT_URL wajib diisi")
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return cfg, nil
+}
+
+// discovery adalah bagian discovery document yang dipakai
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider aman dipakai bersamaan oleh banyak request. Discovery document
+// diambil saat pertama dipakai lalu disimpan.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu      sync.Mutex
+	meta    *discovery
+	keys    map[string]interface{}
+	fetched time.Time // Waktu JWKS terakhir diambil
+}
+
+func New(cfg Config) *Provider {
+	return &Provider{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Issuer mengembalikan issuer provider, dipakai bersama subject untuk
+// mengenali identitas eksternal
+func (p *Provider) Issuer() string {
+	return p.cfg.Issuer
+}
+
+func (p *Provider) discover(ctx context.Context) (discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.meta != nil {
+		return *p.meta, nil
+	}
+
+	var meta discovery
+	if err := p.getJSON(ctx, p.cfg.Issuer+"/.well-known/openid-configuration", &meta); err != nil {
+		return meta, fmt.Errorf("discovery OIDC: %w", err)
+	}
+	if strings.TrimSuffix(meta.Issuer, "/") != p.cfg.Issuer {
+		return meta, fmt.Errorf("issuer discovery tidak cocok: %s", meta.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return meta, errors.New("discovery OIDC tidak lengkap")
+	}
+	p.meta = &meta
+	return meta, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status %d", url, res.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(out)
+}
+
+// Flow berisi nilai acak satu kali login yang harus disimpan client
+// (misalnya di cookie) sampai callback
+type Flow struct {
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewFlow membuat state, nonce dan PKCE code verifier baru
+func NewFlow() (Flow, error) {
+	var f Flow
+	var err error
+	if f.State, err = randomString(); err != nil {
+		return f, err
+	}
+	if f.Nonce, err = randomString(); err != nil {
+		return f, err
+	}
+	f.Verifier, err = randomString()
+	return f, err
+}
+
+// AuthCodeURL membuat URL halaman login provider dengan code challenge S256
+func (p *Provider) AuthCodeURL(ctx context.Context, f Flow) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(f.Verifier))
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {f.State},
+		"nonce":                 {f.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// Exchange menukar authorization code dengan ID token lalu memverifikasi
+// tanda tangan, issuer, audience, masa berlaku dan nonce-nya
+func (p *Provider) Exchange(ctx context.Context, code string, f Flow) (Claims, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {f.Verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return Claims{}, err
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&body); err != nil {
+		return Claims{}, fmt.Errorf("respons token endpoint tidak valid: %w", err)
+	}
+	if res.StatusCode != http.StatusOK || body.Error != "" {
+		return Claims{}, fmt.Errorf("token endpoint menolak code: %s %s", body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return Claims{}, errors.New("token endpoint tidak mengembalikan id_token")
+	}
+
+	return p.verify(ctx, body.IDToken, f.Nonce)
+}
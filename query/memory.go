@@ -0,0 +1,250 @@
+package query
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Slice menjalankan ListQuery terhadap data di memori dengan semantik yang
+// sama seperti Find, dipakai oleh penyimpanan in-memory. Setiap item diubah
+// ke dokumen BSON agar filter dan sort memakai nama field yang sama.
+func Slice[T any](items []T, q ListQuery) (Page[T], error) {
+	type entry struct {
+		item T
+		doc  bson.M
+	}
+
+	entries := []entry{}
+	for _, item := range items {
+		doc, err := toDoc(item)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		if matches(doc, q.Filters) {
+			entries = append(entries, entry{item: item, doc: doc})
+		}
+	}
+	total := int64(len(entries))
+
+	sort.SliceStable(entries, func(a, b int) bool {
+		return compareBySorts(entries[a].doc, entries[b].doc, q.Sorts) < 0
+	})
+
+	if q.Cursor != nil {
+		cursorDoc := bson.M{}
+		for i, s := range q.Sorts {
+			setPath(cursorDoc, s.Field, q.Cursor[i])
+		}
+		start := len(entries)
+		for i, e := range entries {
+			if compareBySorts(e.doc, cursorDoc, q.Sorts) > 0 {
+				start = i
+				break
+			}
+		}
+		entries = entries[start:]
+	} else if skip := int(q.Skip()); skip < len(entries) {
+		entries = entries[skip:]
+	} else {
+		entries = nil
+	}
+
+	page := Page[T]{Data: []T{}, Total: total, Limit: q.Limit}
+	if q.Cursor == nil {
+		page.Page = q.Page
+	}
+
+	hasMore := len(entries) > q.Limit
+	if hasMore {
+		entries = entries[:q.Limit]
+	}
+	for _, e := range entries {
+		page.Data = append(page.Data, e.item)
+	}
+
+	if hasMore {
+		raw, err := bson.Marshal(entries[len(entries)-1].doc)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		next, err := encodeCursor(raw, q.Sorts)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		page.NextCursor = next
+	}
+	return page, nil
+}
+
+func toDoc(item interface{}) (bson.M, error) {
+	raw, err := bson.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	doc := bson.M{}
+	err = bson.Unmarshal(raw, &doc)
+	return doc, err
+}
+
+func lookup(doc bson.M, path string) interface{} {
+	var cur interface{} = doc
+	for _, key := range strings.Split(path, ".") {
+		switch m := cur.(type) {
+		case bson.M:
+			cur = m[key]
+		case bson.D:
+			cur = m.Map()[key]
+		default:
+			return nil
+		}
+	}
+	return cur
+}
+
+func setPath(doc bson.M, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := doc[key].(bson.M)
+		if !ok {
+			next = bson.M{}
+			doc[key] = next
+		}
+		doc = next
+	}
+	doc[keys[len(keys)-1]] = value
+}
+
+func matches(doc bson.M, filters []Filter) bool {
+	for _, f := range filters {
+		v := lookup(doc, f.Field)
+		ok := false
+		switch f.Op {
+		case Eq:
+			ok = compare(v, f.Value) == 0
+		case Ne:
+			ok = compare(v, f.Value) != 0
+		case Gt:
+			ok = v != nil && compare(v, f.Value) > 0
+		case Gte:
+			ok = v != nil && compare(v, f.Value) >= 0
+		case Lt:
+			ok = v != nil && compare(v, f.Value) < 0
+		case Lte:
+			ok = v != nil && compare(v, f.Value) <= 0
+		case In:
+			list, _ := f.Value.([]interface{})
+			for _, candidate := range list {
+				if compare(v, candidate) == 0 {
+					ok = true
+					break
+				}
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func compareBySorts(a, b bson.M, sorts []Sort) int {
+	for _, s := range sorts {
+		c := compare(lookup(a, s.Field), lookup(b, s.Field))
+		if s.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compare membandingkan dua nilai BSON mengikuti urutan tipe MongoDB
+// (null < angka < string < ObjectID < bool < tanggal)
+func compare(a, b interface{}) int {
+	ra, va := normalize(a)
+	rb, vb := normalize(b)
+	if ra != rb {
+		return cmp(ra, rb)
+	}
+	switch x := va.(type) {
+	case float64:
+		return cmpFloat(x, vb.(float64))
+	case string:
+		return strings.Compare(x, vb.(string))
+	case bool:
+		y := vb.(bool)
+		if x == y {
+			return 0
+		}
+		if !x {
+			return -1
+		}
+		return 1
+	case int64:
+		y := vb.(int64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func normalize(v interface{}) (int, interface{}) {
+	switch x := v.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return 1, float64(x)
+	case int32:
+		return 1, float64(x)
+	case int64:
+		return 1, float64(x)
+	case float64:
+		return 1, x
+	case string:
+		return 2, x
+	case primitive.ObjectID:
+		return 3, x.Hex()
+	case bool:
+		return 4, x
+	case time.Time:
+		return 5, x.UnixMilli()
+	case primitive.DateTime:
+		return 5, int64(x)
+	case bson.RawValue:
+		var decoded interface{}
+		if err := x.Unmarshal(&decoded); err == nil {
+			return normalize(decoded)
+		}
+	}
+	return 6, nil
+}
+
+func cmp(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,197 @@
+package query
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoFilter mengubah filter menjadi dokumen filter MongoDB
+func (q ListQuery) MongoFilter() bson.M {
+	filter := bson.M{}
+	for _, f := range q.Filters {
+		cond, ok := filter[f.Field].(bson.M)
+		if !ok {
+			cond = bson.M{}
+			filter[f.Field] = cond
+		}
+		cond["$"+string(f.Op)] = f.Value
+	}
+	return filter
+}
+
+// MongoSort mengubah urutan menjadi dokumen sort MongoDB
+func (q ListQuery) MongoSort() bson.D {
+	sort := bson.D{}
+	for _, s := range q.Sorts {
+		dir := 1
+		if s.Desc {
+			dir = -1
+		}
+		sort = append(sort, bson.E{Key: s.Field, Value: dir})
+	}
+	return sort
+}
+
+// keysetFilter membangun kondisi "setelah cursor" sesuai urutan sort:
+// (a > va) OR (a = va AND b > vb) OR ...
+func (q ListQuery) keysetFilter() bson.M {
+	or := bson.A{}
+	for i, s := range q.Sorts {
+		cond := bson.M{}
+		for j := 0; j < i; j++ {
+			cond[q.Sorts[j].Field] = q.Cursor[j]
+		}
+		op := "$gt"
+		if s.Desc {
+			op = "$lt"
+		}
+		cond[s.Field] = bson.M{op: q.Cursor[i]}
+		or = append(or, cond)
+	}
+	return bson.M{"$or": or}
+}
+
+// countFilter menggabungkan filter query dengan kondisi tambahan dari
+// pemanggil, misalnya pencarian teks
+func (q ListQuery) countFilter(where bson.M) bson.M {
+	filter := q.MongoFilter()
+	if len(where) == 0 {
+		return filter
+	}
+	return bson.M{"$and": bson.A{filter, where}}
+}
+
+func (q ListQuery) pageFilter(where bson.M) bson.M {
+	filter := q.countFilter(where)
+	if q.Cursor == nil {
+		return filter
+	}
+	return bson.M{"$and": bson.A{filter, q.keysetFilter()}}
+}
+
+// Find menjalankan ListQuery pada koleksi dan mengembalikan satu halaman
+func Find[T any](ctx context.Context, coll *mongo.Collection, q ListQuery) (Page[T], error) {
+	return FindWhere[T](ctx, coll, q, nil)
+}
+
+// FindWhere seperti Find dengan kondisi tambahan yang tidak bisa ditulis
+// sebagai Filter, misalnya pencarian $or pada beberapa field
+func FindWhere[T any](ctx context.Context, coll *mongo.Collection, q ListQuery, where bson.M) (Page[T], error) {
+	opts := options.Find().
+		SetSort(q.MongoSort()).
+		SetSkip(q.Skip()).
+		SetLimit(int64(q.Limit + 1))
+
+	cursor, err := coll.Find(ctx, q.pageFilter(where), opts)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	return collectPage[T](ctx, coll, q, where, cursor)
+}
+
+// Aggregate seperti Find, tetapi menambahkan stages (mis. $lookup) setelah
+// filter, sort dan limit sehingga join hanya dilakukan untuk satu halaman
+func Aggregate[T any](ctx context.Context, coll *mongo.Collection, q ListQuery, stages mongo.Pipeline) (Page[T], error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: q.pageFilter(nil)}},
+		{{Key: "$sort", Value: q.MongoSort()}},
+	}
+	if skip := q.Skip(); skip > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: skip}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$limit", Value: q.Limit + 1}})
+	pipeline = append(pipeline, stages...)
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	return collectPage[T](ctx, coll, q, nil, cursor)
+}
+
+func collectPage[T any](ctx context.Context, coll *mongo.Collection, q ListQuery, where bson.M, cursor *mongo.Cursor) (Page[T], error) {
+	var raws []bson.Raw
+	if err := cursor.All(ctx, &raws); err != nil {
+		return Page[T]{}, err
+	}
+
+	total, err := coll.CountDocuments(ctx, q.countFilter(where))
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	page := Page[T]{Data: []T{}, Total: total, Limit: q.Limit}
+	if q.Cursor == nil {
+		page.Page = q.Page
+	}
+
+	hasMore := len(raws) > q.Limit
+	if hasMore {
+		raws = raws[:q.Limit]
+	}
+	for _, raw := range raws {
+		var item T
+		if err := bson.Unmarshal(raw, &item); err != nil {
+			return Page[T]{}, err
+		}
+		page.Data = append(page.Data, item)
+	}
+
+	if hasMore {
+		next, err := encodeCursor(raws[len(raws)-1], q.Sorts)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		page.NextCursor = next
+	}
+	return page, nil
+}
+
+// WithNext melengkapi envelope dengan link ke halaman berikutnya
+func (p Page[T]) WithNext(c *fiber.Ctx) Page[T] {
+	if p.NextCursor != "" {
+		p.Next = NextLink(c, p.NextCursor)
+	}
+	return p
+}
+
+type cursorDoc struct {
+	V []interface{} `bson:"v"`
+}
+
+// encodeCursor menyimpan nilai field sort dari dokumen terakhir dalam BSON
+// agar tipe seperti ObjectID dan tanggal tetap utuh saat dibandingkan
+func encodeCursor(doc bson.Raw, sorts []Sort) (string, error) {
+	values := bson.A{}
+	for _, s := range sorts {
+		rv, err := doc.LookupErr(strings.Split(s.Field, ".")...)
+		if err != nil {
+			values = append(values, nil)
+			continue
+		}
+		values = append(values, rv)
+	}
+	b, err := bson.Marshal(bson.M{"v": values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(cursor string) ([]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var doc cursorDoc
+	if err := bson.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return doc.V, nil
+}
@@ -0,0 +1,289 @@
+// Package query berisi lapisan query bersama untuk endpoint list: parsing
+// filter, sort dan pagination dari query string serta envelope responsenya.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+type Kind int
+
+const (
+	String Kind = iota
+	Int
+	Date
+	ObjectID
+	Bool
+)
+
+type Op string
+
+const (
+	Eq  Op = "eq"
+	Ne  Op = "ne"
+	Gt  Op = "gt"
+	Gte Op = "gte"
+	Lt  Op = "lt"
+	Lte Op = "lte"
+	In  Op = "in"
+)
+
+var validOps = map[Op]bool{Eq: true, Ne: true, Gt: true, Gte: true, Lt: true, Lte: true, In: true}
+
+// Field mendeskripsikan satu field yang boleh difilter dan diurutkan.
+// Location dipakai untuk nilai Date tanpa jam (mis. 2025-07-20), default UTC.
+type Field struct {
+	BSON     string
+	Kind     Kind
+	Location *time.Location
+}
+
+// Schema memetakan nama parameter query ke field dokumen
+type Schema map[string]Field
+
+type Filter struct {
+	Field string
+	Op    Op
+	Value interface{}
+}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ListQuery adalah hasil parsing query string yang sudah divalidasi
+// terhadap Schema. Jika Cursor diisi, Page diabaikan. Search berisi teks
+// bebas dari parameter q; field yang dicari ditentukan oleh pemanggil.
+type ListQuery struct {
+	Filters []Filter
+	Sorts   []Sort
+	Page    int
+	Limit   int
+	Cursor  []interface{}
+	Search  string
+}
+
+// Skip mengembalikan jumlah dokumen yang dilewati untuk pagination page/limit
+func (q ListQuery) Skip() int64 {
+	if q.Cursor != nil || q.Page <= 1 {
+		return 0
+	}
+	return int64((q.Page - 1) * q.Limit)
+}
+
+// Page adalah envelope response untuk semua endpoint list
+type Page[T any] struct {
+	Data       []T    `json:"data"`
+	Total      int64  `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+}
+
+var reserved = map[string]bool{"page": true, "limit": true, "cursor": true, "sort": true, "q": true}
+
+// Parse membaca filter, sort dan pagination dari query string.
+//
+// Filter ditulis sebagai field=nilai, field[op]=nilai (op: eq, ne, gt, gte,
+// lt, lte, in dengan nilai dipisah koma), atau field>=nilai dan field<=nilai.
+// Sort ditulis sebagai sort=field1,-field2 (tanda minus untuk descending).
+// Parameter q disimpan apa adanya di Search untuk pencarian teks bebas.
+// Parameter yang tidak ada di schema ditolak agar salah ketik tidak diam-diam
+// mengembalikan seluruh koleksi.
+func Parse(c *fiber.Ctx, schema Schema, defaultSort []Sort) (ListQuery, error) {
+	q := ListQuery{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", DefaultLimit),
+	}
+	if q.Page < 1 {
+		return q, errors.New("page harus lebih dari 0")
+	}
+	if q.Limit < 1 || q.Limit > MaxLimit {
+		return q, fmt.Errorf("limit harus antara 1 dan %d", MaxLimit)
+	}
+
+	values, err := url.ParseQuery(string(c.Context().QueryArgs().QueryString()))
+	if err != nil {
+		return q, errors.New("query string tidak valid")
+	}
+
+	for key, vals := range values {
+		if reserved[key] {
+			continue
+		}
+		name, op := splitKey(key)
+		field, ok := schema[name]
+		if !ok {
+			return q, fmt.Errorf("filter tidak dikenal: %s", name)
+		}
+		if !validOps[op] {
+			return q, fmt.Errorf("operator tidak dikenal: %s", op)
+		}
+		for _, raw := range vals {
+			value, err := convertFilter(field, op, raw)
+			if err != nil {
+				return q, fmt.Errorf("nilai %s tidak valid: %w", name, err)
+			}
+			q.Filters = append(q.Filters, Filter{Field: field.BSON, Op: op, Value: value})
+		}
+	}
+
+	q.Search = strings.TrimSpace(c.Query("q"))
+
+	// kinds mencatat tipe setiap field sort untuk memeriksa nilai cursor
+	var kinds []Kind
+	sortParam := c.Query("sort")
+	if sortParam == "" {
+		for _, s := range defaultSort {
+			q.Sorts = append(q.Sorts, s)
+			kinds = append(kinds, schema.kindOf(s.Field))
+		}
+	} else {
+		for _, part := range strings.Split(sortParam, ",") {
+			part = strings.TrimSpace(part)
+			desc := strings.HasPrefix(part, "-")
+			field, ok := schema[strings.TrimPrefix(part, "-")]
+			if !ok {
+				return q, fmt.Errorf("sort tidak dikenal: %s", part)
+			}
+			q.Sorts = append(q.Sorts, Sort{Field: field.BSON, Desc: desc})
+			kinds = append(kinds, field.Kind)
+		}
+	}
+	// _id selalu menjadi penentu terakhir agar urutan stabil untuk cursor
+	q.Sorts = append(q.Sorts, Sort{Field: "_id"})
+	kinds = append(kinds, ObjectID)
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		values, err := decodeCursor(cursor)
+		if err != nil || !validCursor(values, kinds) {
+			return q, errors.New("cursor tidak valid")
+		}
+		q.Cursor = values
+	}
+
+	return q, nil
+}
+
+// kindOf mengembalikan tipe field dengan nama BSON tersebut, atau anyKind
+// jika field tidak ada di schema
+func (s Schema) kindOf(bsonField string) Kind {
+	for _, f := range s {
+		if f.BSON == bsonField {
+			return f.Kind
+		}
+	}
+	return anyKind
+}
+
+// anyKind dipakai untuk field sort yang tipenya tidak diketahui; nilainya
+// tetap harus skalar
+const anyKind Kind = -1
+
+// validCursor memastikan setiap nilai cursor berupa skalar dengan tipe
+// field sort-nya, atau null untuk dokumen yang tidak memiliki field itu.
+// Cursor datang dari klien, jadi tanpa pengecekan ini dokumen atau operator
+// BSON sembarang bisa masuk ke filter keyset.
+func validCursor(values []interface{}, kinds []Kind) bool {
+	if len(values) != len(kinds) {
+		return false
+	}
+	for i, v := range values {
+		if v != nil && !scalarOfKind(v, kinds[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func scalarOfKind(v interface{}, kind Kind) bool {
+	switch v.(type) {
+	case string:
+		return kind == String || kind == anyKind
+	case int32, int64, float64:
+		return kind == Int || kind == anyKind
+	case bool:
+		return kind == Bool || kind == anyKind
+	case primitive.DateTime:
+		return kind == Date || kind == anyKind
+	case primitive.ObjectID:
+		return kind == ObjectID || kind == anyKind
+	}
+	return false
+}
+
+// splitKey memisahkan nama field dan operator dari kunci query string.
+// "tanggal>=2025-01-01" diterima sebagai kunci "tanggal>" oleh parser URL.
+func splitKey(key string) (string, Op) {
+	if i := strings.Index(key, "["); i > 0 && strings.HasSuffix(key, "]") {
+		return key[:i], Op(key[i+1 : len(key)-1])
+	}
+	switch {
+	case strings.HasSuffix(key, ">"):
+		return strings.TrimSuffix(key, ">"), Gte
+	case strings.HasSuffix(key, "<"):
+		return strings.TrimSuffix(key, "<"), Lte
+	case strings.HasSuffix(key, "!"):
+		return strings.TrimSuffix(key, "!"), Ne
+	}
+	return key, Eq
+}
+
+func convertFilter(field Field, op Op, raw string) (interface{}, error) {
+	if op != In {
+		return convertValue(field, raw)
+	}
+	list := []interface{}{}
+	for _, part := range strings.Split(raw, ",") {
+		v, err := convertValue(field, strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+func convertValue(field Field, raw string) (interface{}, error) {
+	switch field.Kind {
+	case Int:
+		return strconv.Atoi(raw)
+	case Bool:
+		return strconv.ParseBool(raw)
+	case ObjectID:
+		return primitive.ObjectIDFromHex(raw)
+	case Date:
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t, nil
+		}
+		loc := field.Location
+		if loc == nil {
+			loc = time.UTC
+		}
+		return time.ParseInLocation("2006-01-02", raw, loc)
+	}
+	return raw, nil
+}
+
+// NextLink membangun URL halaman berikutnya dari request saat ini
+func NextLink(c *fiber.Ctx, cursor string) string {
+	values, _ := url.ParseQuery(string(c.Context().QueryArgs().QueryString()))
+	values.Del("page")
+	values.Set("cursor", cursor)
+	return c.BaseURL() + c.Path() + "?" + values.Encode()
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateAPIKeyRequest berisi data API key baru. ExpiresAt kosong berarti
+// key berlaku sampai dicabut.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APIKeyCreatedResponse memuat key lengkap yang hanya dikembalikan sekali
+type APIKeyCreatedResponse struct {
+	Key string `json:"key"`
+	models.APIKey
+}
+
+// GetAPIKeyScopes godoc
+// @Summary List API key scopes
+// @Description Mengambil daftar scope yang bisa diberikan ke API key
+// @Tags APIKey
+// @Produce json
+// @Success 200 {array} string "Daftar scope"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Router /api/admin/api-keys/scopes [get]
+// @Security BearerAuth
+func (h *Handler) GetAPIKeyScopes(c *fiber.Ctx) error {
+	return c.JSON(models.APIKeyScopes)
+}
+
+// GetAllAPIKeys godoc
+// @Summary List API keys
+// @Description Mengambil semua API key termasuk yang sudah dicabut. Key lengkap tidak pernah dikembalikan, hanya prefix-nya
+// @Tags APIKey
+// @Produce json
+// @Success 200 {array} models.APIKey "Daftar API key"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/api-keys [get]
+// @Security BearerAuth
+func (h *Handler) GetAllAPIKeys(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys, err := h.Store.APIKey.List(ctx)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(keys)
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Membuat API key untuk agen tiket atau kios. Key dikirim di header X-API-Key dan hanya berlaku untuk endpoint baca sesuai scope. Simpan key dari respons ini karena tidak bisa ditampilkan lagi
+// @Tags APIKey
+// @Accept json
+// @Produce json
+// @Param request body CreateAPIKeyRequest true "Data API key"
+// @Success 201 {object} APIKeyCreatedResponse "API key dibuat"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/api-keys [post]
+// @Security BearerAuth
+func (h *Handler) CreateAPIKey(c *fiber.Ctx) error {
+	var input CreateAPIKeyRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Nama API key wajib diisi"})
+	}
+	if len(input.Scopes) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Minimal satu scope wajib diisi"})
+	}
+	for _, s := range input.Scopes {
+		if !models.IsAPIKeyScope(s) {
+			return c.Status(400).JSON(fiber.Map{"error": "Scope tidak dikenal: " + s})
+		}
+	}
+	now := time.Now()
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return c.Status(400).JSON(fiber.Map{"error": "expires_at harus di masa depan"})
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat API key"})
+	}
+	raw := models.APIKeyPrefix + secret
+
+	createdBy, _ := getUserIDFromToken(c)
+	key := models.APIKey{
+		ID:        primitive.NewObjectID(),
+		Name:      name,
+		Prefix:    raw[:len(models.APIKeyPrefix)+6],
+		KeyHash:   models.HashAPIKey(raw),
+		Scopes:    input.Scopes,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: input.ExpiresAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := h.Store.APIKey.Create(ctx, &key); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(APIKeyCreatedResponse{Key: raw, APIKey: key})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Mencabut API key sehingga langsung tidak bisa dipakai lagi
+// @Tags APIKey
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} models.SuccessResponse "API key dicabut"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "API key tidak ditemukan atau sudah dicabut"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/api-keys/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) RevokeAPIKey(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = h.Store.APIKey.Revoke(ctx, id, time.Now())
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "API key tidak ditemukan atau sudah dicabut"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "API key dicabut"})
+}
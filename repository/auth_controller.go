@@ -2,16 +2,16 @@ package repository
 
 import (
 	"context"
-	"os"
+	"errors"
+	"fmt"
 	"regexp"
 	"time"
-	"transport-app/config"
 	"transport-app/models"
+	"transport-app/store"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -21,7 +21,9 @@ type AuthRequest struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	Role         string `json:"role"`
 }
 
 func hashPassword(password string) (string, error) {
@@ -34,19 +36,26 @@ func checkPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
-func getUserCollection() *mongo.Collection {
-	return config.GetCollection("users")
-}
-
 // Fungsi untuk validasi format email
 func isEmailValid(email string) bool {
 	emailRegex := regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,4}$`)
 	return emailRegex.MatchString(email)
 }
 
+// validatePassword mengembalikan pesan error, atau string kosong jika valid
+func validatePassword(password, confirmation string) string {
+	if len(password) < 8 {
+		return "Password minimal harus 8 karakter"
+	}
+	if password != confirmation {
+		return "Konfirmasi password tidak cocok"
+	}
+	return ""
+}
+
 // Register User godoc
 // @Summary Register a new user
-// @Description Mendaftarkan pengguna baru dengan username dan email
+// @Description Mendaftarkan pengguna baru dengan username dan email, lalu mengirim email verifikasi
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -56,7 +65,7 @@ func isEmailValid(email string) bool {
 // @Failure 409 {object} models.ErrorResponse "Username or email already exists"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/register [post]
-func Register(c *fiber.Ctx) error {
+func (h *Handler) Register(c *fiber.Ctx) error {
 
 	var input struct {
 		Username             string `json:"username"`
@@ -79,29 +88,17 @@ func Register(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Format email tidak valid"})
 	}
 
-	// Validasi panjang password
-	if len(input.Password) < 8 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Password minimal harus 8 karakter"})
-	}
-
-	// Validasi konfirmasi password
-	if input.Password != input.PasswordConfirmation {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Konfirmasi password tidak cocok"})
+	// Validasi panjang dan konfirmasi password
+	if msg := validatePassword(input.Password, input.PasswordConfirmation); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": msg})
 	}
 
 	// Cek apakah username atau email sudah ada
-	// Menggunakan $or untuk memeriksa keduanya dalam satu query
-	filter := bson.M{
-		"$or": []bson.M{
-			{"username": input.Username},
-			{"email": input.Email},
-		},
-	}
-	count, err := getUserCollection().CountDocuments(context.TODO(), filter)
+	exists, err := h.Store.User.ExistsByUsernameOrEmail(context.TODO(), input.Username, input.Email)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error saat memeriksa data pengguna"})
 	}
-	if count > 0 {
+	if exists {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Username atau email sudah terdaftar"})
 	}
 
@@ -114,20 +111,31 @@ func Register(c *fiber.Ctx) error {
 		Username: input.Username,
 		Email:    input.Email,
 		Password: hashedPassword,
-		Role:     "user",
+		Role:     models.RoleUser,
 	}
 
-	_, err = getUserCollection().InsertOne(context.TODO(), newUser)
+	// Pendaftaran bersamaan dengan username atau email yang sama lolos cek
+	// di atas, tetapi ditolak index unik
+	err = h.Store.User.Create(context.TODO(), &newUser)
+	if err == store.ErrDuplicate {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Username atau email sudah terdaftar"})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membuat pengguna baru"})
 	}
 
+	// Kegagalan kirim email tidak membatalkan pendaftaran, user bisa
+	// meminta ulang lewat /api/email/resend
+	if err := h.sendVerificationEmail(context.TODO(), newUser); err != nil {
+		fmt.Println("❌ Gagal mengirim email verifikasi:", err)
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Pengguna berhasil dibuat"})
 }
 
 // Login User godoc
 // @Summary Login a user
-// @Description Login menggunakan username dan password untuk mendapatkan token
+// @Description Login menggunakan username dan password untuk mendapatkan access token dan refresh token. Jika user mengaktifkan 2FA, response berisi challenge_token (TwoFactorChallengeResponse) yang diselesaikan lewat /api/login/2fa. Login gagal berulang per username dan per IP dikunci sementara dengan jeda yang makin lama
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -135,9 +143,11 @@ func Register(c *fiber.Ctx) error {
 // @Success 200 {object} LoginResponse "Login successful, token returned"
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 401 {object} models.ErrorResponse "Invalid username or password"
+// @Failure 403 {object} models.ErrorResponse "Email belum diverifikasi, akun dinonaktifkan atau password harus direset"
+// @Failure 429 {object} models.ErrorResponse "Terlalu banyak percobaan login gagal"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/login [post]
-func Login(c *fiber.Ctx) error {
+func (h *Handler) Login(c *fiber.Ctx) error {
 	var input struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
@@ -147,9 +157,16 @@ func Login(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Username atau IP yang terlalu sering gagal ditolak sebelum bcrypt
+	if wait := h.beginLoginAttempt(ctx, c, input.Username); wait > 0 {
+		return loginLockedResponse(c, wait)
+	}
+
 	// Cari user di database berdasarkan username
-	var user models.User
-	err := getUserCollection().FindOne(context.TODO(), bson.M{"username": input.Username}).Decode(&user)
+	user, err := h.Store.User.GetByUsername(ctx, input.Username)
 	if err != nil {
 		// Jika tidak ditemukan, berikan pesan error yang generik
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Username atau password salah"})
@@ -159,25 +176,72 @@ func Login(c *fiber.Ctx) error {
 	if !checkPasswordHash(input.Password, user.Password) {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Username atau password salah"})
 	}
+	h.loginSucceeded(ctx, c, input.Username)
 
-	// Buat token JWT
-	claims := jwt.MapClaims{
-		"username": user.Username,
-		"email":    user.Email,
-		"user_id":  user.ID.Hex(),
-		"role":     user.Role,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(), // Token berlaku 24 jam
+	return h.finishLogin(ctx, c, user, false)
+}
+
+// finishLogin menjalankan pengecekan akun yang sama untuk semua cara login
+// setelah identitas user terbukti, lalu mengembalikan challenge 2FA atau
+// session baru. mfa diisi jika login sudah memakai lebih dari satu faktor.
+func (h *Handler) finishLogin(ctx context.Context, c *fiber.Ctx, user models.User, mfa bool) error {
+	if user.Disabled {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Akun dinonaktifkan"})
+	}
+	if user.MustResetPassword {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Password harus direset, periksa email Anda"})
+	}
+
+	if emailVerificationMode() == verifikasiUntukLogin && !user.EmailVerified {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Email belum diverifikasi"})
+	}
+
+	// User dengan 2FA harus menyelesaikan login lewat /api/login/2fa
+	if user.TOTP.Enabled {
+		challenge, err := h.signChallengeToken(user)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membuat token autentikasi"})
+		}
+		return c.JSON(TwoFactorChallengeResponse{
+			MFARequired:    true,
+			ChallengeToken: challenge,
+			ExpiresIn:      int(mfaChallengeTTL.Seconds()),
+		})
 	}
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	t, err := token.SignedString([]byte(jwtSecret))
+	// Buat session beserta access token (berlaku 15 menit) dan refresh token
+	res, err := h.issueSession(ctx, user, mfa)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membuat token autentikasi"})
 	}
 
-	return c.JSON(fiber.Map{
-		"token": t,
-		"role":  user.Role,
-	})
+	return c.JSON(res)
+}
+
+// Mengambil user_id dari token JWT yang sudah divalidasi middleware.Protected
+func getUserIDFromToken(c *fiber.Ctx) (primitive.ObjectID, error) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return primitive.NilObjectID, errors.New("token tidak ditemukan")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return primitive.NilObjectID, errors.New("claims tidak valid")
+	}
+	id, _ := claims["user_id"].(string)
+	return primitive.ObjectIDFromHex(id)
+}
+
+// Mengambil role dari token JWT, string kosong jika tidak ada
+func getRoleFromToken(c *fiber.Ctx) string {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	role, _ := claims["role"].(string)
+	return role
 }
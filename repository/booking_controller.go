@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BookingRequest berisi jumlah kursi dan, untuk rute dengan halte, kode
+// halte naik dan turun. Halte yang tidak diisi dianggap halte pertama atau
+// terakhir rute.
+type BookingRequest struct {
+	JumlahKursi int    `json:"jumlah_kursi"`
+	NaikHalte   string `json:"naik_halte"`
+	TurunHalte  string `json:"turun_halte"`
+}
+
+// bookingHalte memvalidasi halte naik dan turun pada rute jadwal dan
+// mengembalikan ID-nya, atau pesan error untuk client
+func (h *Handler) bookingHalte(ctx context.Context, jadwal models.Jadwal, input BookingRequest) (*primitive.ObjectID, *primitive.ObjectID, string, error) {
+	if input.NaikHalte == "" && input.TurunHalte == "" {
+		return nil, nil, "", nil
+	}
+
+	rute, err := h.Store.Rute.Get(ctx, jadwal.RuteID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if len(rute.Halte) == 0 {
+		return nil, nil, "Rute jadwal ini tidak memiliki halte", nil
+	}
+
+	naik, turun := 0, len(rute.Halte)-1
+	naikID, turunID := rute.Halte[naik].HalteID, rute.Halte[turun].HalteID
+	if input.NaikHalte != "" {
+		naik, naikID, err = h.ruteHalteIndex(ctx, rute, input.NaikHalte)
+		if err != nil && err != store.ErrNotFound {
+			return nil, nil, "", err
+		}
+		if naik < 0 || err == store.ErrNotFound {
+			return nil, nil, "Halte naik tidak dilewati rute ini", nil
+		}
+	}
+	if input.TurunHalte != "" {
+		turun, turunID, err = h.ruteHalteIndex(ctx, rute, input.TurunHalte)
+		if err != nil && err != store.ErrNotFound {
+			return nil, nil, "", err
+		}
+		if turun < 0 || err == store.ErrNotFound {
+			return nil, nil, "Halte turun tidak dilewati rute ini", nil
+		}
+	}
+	if naik >= turun {
+		return nil, nil, "Halte turun harus setelah halte naik", nil
+	}
+	return &naikID, &turunID, "", nil
+}
+
+// CreateBooking godoc
+// @Summary Book seats on a jadwal
+// @Description Memesan kursi pada jadwal tertentu untuk user yang sedang login. Untuk rute dengan halte, penumpang bisa naik dan turun di halte antara lewat naik_halte dan turun_halte (kode halte)
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param id path string true "Jadwal ID"
+// @Param booking body BookingRequest true "Jumlah kursi dan halte naik/turun"
+// @Success 201 {object} models.Booking "Booking berhasil dibuat"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Email belum diverifikasi"
+// @Failure 404 {object} models.ErrorResponse "Jadwal or Kendaraan not found"
+// @Failure 409 {object} models.ErrorResponse "Kursi tidak mencukupi atau jadwal sudah berangkat"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/jadwals/{id}/bookings [post]
+// @Security BearerAuth
+func (h *Handler) CreateBooking(c *fiber.Ctx) error {
+	jadwalID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	userID, err := getUserIDFromToken(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Token tidak valid"})
+	}
+
+	var input BookingRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	if input.JumlahKursi <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Jumlah kursi harus lebih dari 0"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	verified, err := h.requireVerifiedEmail(ctx, c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !verified {
+		return c.Status(403).JSON(fiber.Map{"error": "Verifikasi email sebelum memesan kursi"})
+	}
+
+	jadwal, err := h.Store.Jadwal.Get(ctx, jadwalID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Jadwal not found"})
+	}
+	if !jadwal.WaktuBerangkat.After(time.Now()) {
+		return c.Status(409).JSON(fiber.Map{"error": "Jadwal sudah berangkat"})
+	}
+
+	kendaraan, err := h.Store.Kendaraan.Get(ctx, jadwal.KendaraanID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Kendaraan not found"})
+	}
+
+	naikID, turunID, msg, err := h.bookingHalte(ctx, jadwal, input)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+
+	// Kursi dihitung terisi untuk seluruh perjalanan walaupun penumpang
+	// naik atau turun di halte antara. Kursi ditambah secara atomik hanya
+	// jika sisanya masih cukup, sehingga dua request yang bersamaan tidak
+	// bisa menjual kursi terakhir dua kali
+	ok, err := h.Store.Jadwal.ReserveSeats(ctx, jadwalID, input.JumlahKursi, kendaraan.Kapasitas)
+	if err != nil {
+		fmt.Println("❌ Error saat memesan kursi:", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memesan kursi"})
+	}
+	if !ok {
+		return c.Status(409).JSON(fiber.Map{"error": "Kursi tidak mencukupi"})
+	}
+
+	booking := models.Booking{
+		ID:           primitive.NewObjectID(),
+		JadwalID:     jadwalID,
+		UserID:       userID,
+		JumlahKursi:  input.JumlahKursi,
+		Status:       models.BookingStatusConfirmed,
+		CreatedAt:    time.Now(),
+		NaikHalteID:  naikID,
+		TurunHalteID: turunID,
+	}
+
+	if err := h.Store.Booking.Create(ctx, &booking); err != nil {
+		fmt.Println("❌ Error saat menyimpan booking:", err)
+		if err := h.Store.Jadwal.ReleaseSeats(ctx, jadwalID, input.JumlahKursi); err != nil {
+			fmt.Println("❌ Gagal mengembalikan kursi:", err)
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan booking"})
+	}
+
+	return c.Status(201).JSON(booking)
+}
+
+// GetBookingsByJadwal godoc
+// @Summary Get bookings of a jadwal
+// @Description Mengambil booking pada jadwal tertentu. Role dengan permission booking:read melihat semua booking, user lain hanya melihat booking miliknya
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param id path string true "Jadwal ID"
+// @Success 200 {array} models.Booking "Daftar booking"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/jadwals/{id}/bookings [get]
+// @Security BearerAuth
+func (h *Handler) GetBookingsByJadwal(c *fiber.Ctx) error {
+	jadwalID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	userID, err := getUserIDFromToken(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Token tidak valid"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Role dengan booking:read melihat booking semua user
+	all, err := h.hasPermission(ctx, c, models.PermBookingRead)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if all {
+		userID = primitive.NilObjectID
+	}
+
+	bookings, err := h.Store.Booking.ListByJadwal(ctx, jadwalID, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(bookings)
+}
+
+// CancelBooking godoc
+// @Summary Cancel a booking
+// @Description Membatalkan booking dan mengembalikan kursinya. Booking tidak bisa dibatalkan setelah jadwal berangkat. Tanpa permission booking:manage, user hanya bisa membatalkan booking miliknya
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param id path string true "Jadwal ID"
+// @Param bookingId path string true "Booking ID"
+// @Success 200 {object} models.SuccessResponse "Booking dibatalkan"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Jadwal or Booking not found"
+// @Failure 409 {object} models.ErrorResponse "Jadwal sudah berangkat"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/jadwals/{id}/bookings/{bookingId} [delete]
+// @Security BearerAuth
+func (h *Handler) CancelBooking(c *fiber.Ctx) error {
+	jadwalID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+	bookingID, err := primitive.ObjectIDFromHex(c.Params("bookingId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	userID, err := getUserIDFromToken(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Token tidak valid"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Role dengan booking:manage boleh membatalkan booking milik siapa pun
+	all, err := h.hasPermission(ctx, c, models.PermBookingManage)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if all {
+		userID = primitive.NilObjectID
+	}
+
+	// Kursi jadwal yang sudah berangkat tidak bisa dijual lagi, jadi
+	// booking-nya juga tidak bisa dibatalkan
+	jadwal, err := h.Store.Jadwal.Get(ctx, jadwalID)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Jadwal not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !jadwal.WaktuBerangkat.After(time.Now()) {
+		return c.Status(409).JSON(fiber.Map{"error": "Jadwal sudah berangkat"})
+	}
+
+	// Status diubah secara atomik agar kursi tidak dikembalikan dua kali
+	booking, err := h.Store.Booking.Cancel(ctx, bookingID, jadwalID, userID)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Booking not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Jika kursi gagal dikembalikan, booking dikembalikan menjadi confirmed
+	// agar kursi tidak hilang dan pembatalan bisa diulang
+	if err := h.Store.Jadwal.ReleaseSeats(ctx, jadwalID, booking.JumlahKursi); err != nil {
+		fmt.Println("❌ Gagal mengembalikan kursi:", err)
+		if err := h.Store.Booking.Restore(ctx, bookingID); err != nil {
+			fmt.Println("❌ Gagal mengembalikan status booking:", err)
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengembalikan kursi"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Booking dibatalkan"})
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+	"transport-app/gtfs"
+	"transport-app/models"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	gtfsAgencyID      = "transport-app"
+	defaultGTFSHari   = 60
+	maxGTFSHari       = 366
+	defaultGTFSAgency = "Transport App"
+	defaultGTFSURL    = "https://github.com/Haekalss"
+)
+
+// GTFSIssueResponse dikembalikan jika feed tidak lolos validasi GTFS
+type GTFSIssueResponse struct {
+	Error  string       `json:"error"`
+	Issues []gtfs.Issue `json:"issues"`
+}
+
+// gtfsAgency membaca GTFS_AGENCY_NAME, GTFS_AGENCY_URL dan GTFS_TIMEZONE.
+// Semua waktu di feed dinyatakan dalam zona waktu agency.
+func gtfsAgency() (gtfs.Agency, *time.Location) {
+	agency := gtfs.Agency{
+		ID:   gtfsAgencyID,
+		Name: os.Getenv("GTFS_AGENCY_NAME"),
+		URL:  os.Getenv("GTFS_AGENCY_URL"),
+		Lang: "id",
+	}
+	if agency.Name == "" {
+		agency.Name = defaultGTFSAgency
+	}
+	if agency.URL == "" {
+		agency.URL = defaultGTFSURL
+	}
+	zona, err := normalizeZonaWaktu(os.Getenv("GTFS_TIMEZONE"))
+	if err != nil {
+		zona = defaultZonaWaktu
+	}
+	agency.Timezone = zona
+	return agency, loadZonaWaktu(zona)
+}
+
+// BuildGTFS menyusun feed GTFS dari rute, halte dan jadwal yang berangkat
+// selama hari hari mulai tanggal dari (hari ini jika kosong). Setiap jadwal
+// menjadi satu trip dengan nomor polisi kendaraan sebagai block_id, dan
+// setiap tanggal menjadi satu service. Rute tanpa halte dilewati dan
+// dicatat di warnings karena trip GTFS membutuhkan minimal dua stop.
+func (h *Handler) BuildGTFS(ctx context.Context, dari time.Time, hari int) (gtfs.Feed, []string, error) {
+	agency, loc := gtfsAgency()
+	feed := gtfs.Feed{Agencies: []gtfs.Agency{agency}}
+	warnings := []string{}
+
+	if dari.IsZero() {
+		dari = time.Now().In(loc)
+	}
+	from := gtfs.ServiceDay(dari.Year(), dari.Month(), dari.Day(), loc)
+	until := gtfs.ServiceDay(dari.Year(), dari.Month(), dari.Day()+hari, loc)
+
+	rutes, err := h.Store.Rute.All(ctx)
+	if err != nil {
+		return feed, nil, err
+	}
+	sort.SliceStable(rutes, func(a, b int) bool { return rutes[a].KodeRute < rutes[b].KodeRute })
+
+	ids := []primitive.ObjectID{}
+	for _, rute := range rutes {
+		for _, stop := range rute.Halte {
+			ids = append(ids, stop.HalteID)
+		}
+	}
+	haltes, err := h.Store.Halte.GetMany(ctx, ids)
+	if err != nil {
+		return feed, nil, err
+	}
+
+	exported := map[primitive.ObjectID]models.Rute{}
+	for _, rute := range rutes {
+		if len(rute.Halte) < 2 {
+			warnings = append(warnings, fmt.Sprintf("Rute %s dilewati karena belum memiliki halte", rute.KodeRute))
+			continue
+		}
+		if !ruteHalteLengkap(rute, haltes) {
+			warnings = append(warnings, fmt.Sprintf("Rute %s dilewati karena ada halte yang sudah tidak ada", rute.KodeRute))
+			continue
+		}
+		exported[rute.ID] = rute
+		feed.Routes = append(feed.Routes, gtfs.Route{
+			ID:        rute.KodeRute,
+			AgencyID:  agency.ID,
+			ShortName: rute.KodeRute,
+			LongName:  rute.NamaRute,
+			Type:      gtfs.RouteTypeBus,
+		})
+	}
+
+	// Zona waktu halte diambil dari rute pertama yang melewatinya
+	zonaHalte := map[primitive.ObjectID]string{}
+	for _, rute := range rutes {
+		if _, ok := exported[rute.ID]; !ok {
+			continue
+		}
+		for _, stop := range rute.Halte {
+			if _, ok := zonaHalte[stop.HalteID]; !ok {
+				zonaHalte[stop.HalteID], _ = normalizeZonaWaktu(rute.ZonaWaktu)
+			}
+		}
+	}
+	for id, halte := range haltes {
+		if _, ok := zonaHalte[id]; !ok {
+			continue
+		}
+		feed.Stops = append(feed.Stops, gtfs.Stop{
+			ID:       halte.Kode,
+			Name:     halte.Nama,
+			Desc:     halte.Kota,
+			Lat:      halte.Lokasi.Lat(),
+			Lon:      halte.Lokasi.Lng(),
+			Timezone: zonaHalte[id],
+		})
+	}
+	sort.Slice(feed.Stops, func(a, b int) bool { return feed.Stops[a].ID < feed.Stops[b].ID })
+
+	jadwals, err := h.Store.Jadwal.FindDepartingBetween(ctx, from, until)
+	if err != nil {
+		return feed, nil, err
+	}
+	sort.SliceStable(jadwals, func(a, b int) bool { return jadwals[a].WaktuBerangkat.Before(jadwals[b].WaktuBerangkat) })
+
+	kendaraanIDs := make([]primitive.ObjectID, len(jadwals))
+	for i, jadwal := range jadwals {
+		kendaraanIDs[i] = jadwal.KendaraanID
+	}
+	kendaraans, err := h.Store.Kendaraan.GetMany(ctx, kendaraanIDs)
+	if err != nil {
+		return feed, nil, err
+	}
+
+	services := map[string]time.Time{}
+	for _, jadwal := range jadwals {
+		rute, ok := exported[jadwal.RuteID]
+		if !ok {
+			continue
+		}
+		stops := jadwalStops(jadwal, rute, haltes)
+
+		dep := jadwal.WaktuBerangkat.In(loc)
+		day := gtfs.ServiceDay(dep.Year(), dep.Month(), dep.Day(), loc)
+		serviceID := gtfs.FormatDate(dep)
+		services[serviceID] = time.Date(dep.Year(), dep.Month(), dep.Day(), 0, 0, 0, 0, time.UTC)
+
+		tripID := jadwal.ID.Hex()
+		feed.Trips = append(feed.Trips, gtfs.Trip{
+			RouteID:   rute.KodeRute,
+			ServiceID: serviceID,
+			ID:        tripID,
+			Headsign:  rute.Tujuan,
+			BlockID:   kendaraans[jadwal.KendaraanID].NomorPolisi,
+		})
+		for _, stop := range stops {
+			feed.StopTimes = append(feed.StopTimes, gtfs.StopTime{
+				TripID:       tripID,
+				Arrival:      gtfs.Time(stop.Tiba.Sub(day) / time.Second),
+				Departure:    gtfs.Time(stop.Berangkat.Sub(day) / time.Second),
+				StopID:       stop.Halte.Kode,
+				Sequence:     stop.Urutan,
+				ShapeDist:    stop.JarakKM,
+				HasShapeDist: true,
+			})
+		}
+	}
+
+	for id, date := range services {
+		c := gtfs.Calendar{ServiceID: id, Start: date, End: date}
+		c.Days[(int(date.Weekday())+6)%7] = true
+		feed.Calendars = append(feed.Calendars, c)
+	}
+	sort.Slice(feed.Calendars, func(a, b int) bool { return feed.Calendars[a].ServiceID < feed.Calendars[b].ServiceID })
+
+	return feed, warnings, nil
+}
+
+func ruteHalteLengkap(rute models.Rute, haltes map[primitive.ObjectID]models.Halte) bool {
+	for _, stop := range rute.Halte {
+		if _, ok := haltes[stop.HalteID]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ExportGTFS menulis feed GTFS sebagai zip ke w. Zip dibaca ulang dan
+// divalidasi lebih dulu; jika ada issue, tidak ada yang ditulis ke w.
+func (h *Handler) ExportGTFS(ctx context.Context, w io.Writer, dari time.Time, hari int) ([]string, []gtfs.Issue, error) {
+	feed, warnings, err := h.BuildGTFS(ctx, dari, hari)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := gtfs.Write(&buf, feed); err != nil {
+		return warnings, nil, err
+	}
+	_, issues, err := gtfs.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		return warnings, nil, err
+	}
+	if len(issues) > 0 {
+		return warnings, issues, nil
+	}
+
+	_, err = buf.WriteTo(w)
+	return warnings, nil, err
+}
+
+// GetGTFS godoc
+// @Summary Export GTFS feed
+// @Description Mengunduh jaringan rute, halte dan jadwal sebagai feed GTFS static (zip berisi agency, routes, stops, trips, stop_times dan calendar). Rute tanpa halte tidak diekspor. Waktu dinyatakan dalam zona GTFS_TIMEZONE (default Asia/Jakarta)
+// @Tags GTFS
+// @Produce application/zip
+// @Param dari query string false "Tanggal awal jadwal (YYYY-MM-DD, default hari ini)"
+// @Param hari query int false "Jumlah hari jadwal (default 60, maks 366)"
+// @Success 200 {file} file "Feed GTFS"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 422 {object} GTFSIssueResponse "Data tidak menghasilkan feed GTFS yang valid"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/gtfs [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+func (h *Handler) GetGTFS(c *fiber.Ctx) error {
+	var dari time.Time
+	if v := c.Query("dari"); v != "" {
+		t, err := parseWithLayouts(v, tanggalLayouts, time.UTC)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Format tanggal tidak valid"})
+		}
+		dari = t
+	}
+	hari := c.QueryInt("hari", defaultGTFSHari)
+	if hari <= 0 || hari > maxGTFSHari {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("hari harus antara 1 dan %d", maxGTFSHari)})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	var buf bytes.Buffer
+	warnings, issues, err := h.ExportGTFS(ctx, &buf, dari, hari)
+	if err != nil {
+		fmt.Println("❌ Gagal membuat feed GTFS:", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	for _, w := range warnings {
+		fmt.Println("⚠️ GTFS:", w)
+	}
+	if len(issues) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(GTFSIssueResponse{Error: "Feed GTFS tidak valid", Issues: issues})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="gtfs.zip"`)
+	return c.Send(buf.Bytes())
+}
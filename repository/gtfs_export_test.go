@@ -0,0 +1,140 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"transport-app/gtfs"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// seedGTFSNetwork mengisi store dengan satu rute berhalte, satu rute tanpa
+// halte dan jadwal mulai tanggal dari, termasuk satu jadwal yang melewati
+// tengah malam
+func seedGTFSNetwork(t *testing.T, s *testServer, dari time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+
+	haltes := []models.Halte{
+		{Kode: "KOTA", Nama: "Terminal Kota", Kota: "Jakarta", Lokasi: models.NewGeoPoint(-6.2, 106.8)},
+		{Kode: "TOL", Nama: "Halte Tol", Kota: "Jakarta", Lokasi: models.NewGeoPoint(-6.15, 106.75)},
+		{Kode: "CGK", Nama: "Bandara", Kota: "Tangerang", Lokasi: models.NewGeoPoint(-6.12, 106.65)},
+	}
+	for i := range haltes {
+		if err := s.store.Halte.Create(ctx, &haltes[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rute := models.Rute{
+		KodeRute: "R1", NamaRute: "Kota - Bandara", Asal: "Jakarta", Tujuan: "Tangerang", JarakKM: 30, ZonaWaktu: "Asia/Jakarta",
+		Halte: []models.RuteHalte{
+			{HalteID: haltes[0].ID},
+			{HalteID: haltes[1].ID, JarakKM: 12, DwellMenit: 5},
+			{HalteID: haltes[2].ID, JarakKM: 30},
+		},
+	}
+	tanpaHalte := models.Rute{KodeRute: "R2", NamaRute: "Kota - Pelabuhan", Asal: "Jakarta", Tujuan: "Jakarta", JarakKM: 10, ZonaWaktu: "Asia/Jakarta"}
+	kendaraan := models.Kendaraan{NomorPolisi: "B 1234 CD", Jenis: "Bus", Kapasitas: 40, Status: "aktif"}
+	for _, err := range []error{
+		s.store.Rute.Create(ctx, &rute),
+		s.store.Rute.Create(ctx, &tanpaHalte),
+		s.store.Kendaraan.Create(ctx, &kendaraan),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, jam := range []int{7, 12, 23} {
+		day := time.Date(dari.Year(), dari.Month(), dari.Day()+i, 0, 0, 0, 0, loc)
+		dep := day.Add(time.Duration(jam) * time.Hour)
+		for _, r := range []models.Rute{rute, tanpaHalte} {
+			jadwal := models.Jadwal{
+				ID: primitive.NewObjectID(), Tanggal: day, WaktuBerangkat: dep, EstimasiTiba: dep.Add(90 * time.Minute),
+				RuteID: r.ID, KendaraanID: kendaraan.ID,
+			}
+			if err := s.store.Jadwal.Create(ctx, &jadwal); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestGTFSExportRoundTrip(t *testing.T) {
+	s := newTestServer(t)
+	dari := time.Date(2025, 7, 21, 0, 0, 0, 0, time.UTC)
+	seedGTFSNetwork(t, s, dari)
+
+	feed, warnings, err := s.h.BuildGTFS(context.Background(), dari, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("warnings %v, seharusnya hanya rute R2 yang dilewati", warnings)
+	}
+
+	var buf bytes.Buffer
+	if err := gtfs.Write(&buf, feed); err != nil {
+		t.Fatal(err)
+	}
+	read, issues, err := gtfs.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) > 0 {
+		t.Fatalf("feed hasil ekspor tidak valid: %v", issues)
+	}
+
+	if len(read.Routes) != 1 || len(read.Stops) != 3 || len(read.Trips) != 3 || len(read.StopTimes) != 9 || len(read.Calendars) != 3 {
+		t.Fatalf("isi feed: %d route, %d stop, %d trip, %d stop_times, %d calendar",
+			len(read.Routes), len(read.Stops), len(read.Trips), len(read.StopTimes), len(read.Calendars))
+	}
+	// Jadwal 23:00 tiba 00:30 keesokan harinya, tetap pada service hari berangkat
+	last := read.StopTimes[len(read.StopTimes)-1]
+	if last.Arrival.String() != "24:30:00" {
+		t.Fatalf("arrival_time jadwal lewat tengah malam %s, seharusnya 24:30:00", last.Arrival)
+	}
+	for _, trip := range read.Trips {
+		if trip.BlockID != "B 1234 CD" {
+			t.Fatalf("block_id trip %s: %q", trip.ID, trip.BlockID)
+		}
+	}
+}
+
+func TestGetGTFS(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "penumpang", models.RoleUser)
+	token := s.login(t, "penumpang")
+	// Ekspor dimulai hari ini pada zona waktu agency (default WIB)
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	seedGTFSNetwork(t, s, time.Now().In(loc))
+
+	t.Run("hari tidak valid", func(t *testing.T) {
+		s.expect(t, s.request(t, http.MethodGet, "/api/gtfs?hari=0", token, nil), http.StatusBadRequest, nil)
+	})
+
+	res := s.request(t, http.MethodGet, "/api/gtfs?hari=3", token, nil)
+	if res.StatusCode != http.StatusOK {
+		s.expect(t, res, http.StatusOK, nil)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed, issues, err := gtfs.Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil || len(issues) > 0 {
+		t.Fatalf("zip dari /api/gtfs tidak valid: %v %v", err, issues)
+	}
+	if len(feed.Trips) != 3 {
+		t.Fatalf("%d trip, seharusnya 3", len(feed.Trips))
+	}
+}
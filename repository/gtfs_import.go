@@ -0,0 +1,922 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"transport-app/geo"
+	"transport-app/gtfs"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxGTFSIssues membatasi jumlah issue di laporan agar respons tetap kecil
+const maxGTFSIssues = 500
+
+// gtfsLockTTL lebih panjang dari jadwalLockTTL karena kunci impor dipegang
+// dari cek bentrok sampai seluruh feed tersimpan
+const gtfsLockTTL = 5 * time.Minute
+
+const (
+	aksiDibuat  = "dibuat"
+	aksiDiubah  = "diubah"
+	aksiDihapus = "dihapus"
+)
+
+// GTFSImportOptions mengatur impor feed GTFS. Jadwal dibuat untuk setiap
+// tanggal layanan calendar selama Hari hari mulai Dari (hari ini jika
+// kosong).
+type GTFSImportOptions struct {
+	Dari   time.Time
+	Hari   int
+	DryRun bool
+	// NomorPolisi adalah kendaraan untuk trip yang block_id-nya kosong atau
+	// bukan nomor polisi kendaraan yang terdaftar
+	NomorPolisi string
+}
+
+type GTFSImportCount struct {
+	Dibuat  int `json:"dibuat"`
+	Diubah  int `json:"diubah"`
+	Dihapus int `json:"dihapus"`
+	Tetap   int `json:"tetap"`
+}
+
+func (c *GTFSImportCount) add(aksi string) {
+	switch aksi {
+	case aksiDibuat:
+		c.Dibuat++
+	case aksiDiubah:
+		c.Diubah++
+	case aksiDihapus:
+		c.Dihapus++
+	default:
+		c.Tetap++
+	}
+}
+
+type GTFSImportChange struct {
+	Jenis string `json:"jenis"` // halte, rute atau jadwal
+	Kode  string `json:"kode"`
+	Aksi  string `json:"aksi"` // dibuat, diubah atau dihapus
+}
+
+// GTFSImportResult melaporkan perubahan hasil impor. Jika Issues tidak
+// kosong atau DryRun bernilai true, tidak ada data yang disimpan.
+type GTFSImportResult struct {
+	DryRun    bool               `json:"dry_run"`
+	Halte     GTFSImportCount    `json:"halte"`
+	Rute      GTFSImportCount    `json:"rute"`
+	Jadwal    GTFSImportCount    `json:"jadwal"`
+	Perubahan []GTFSImportChange `json:"perubahan"`
+	Warnings  []string           `json:"warnings"`
+	Issues    []gtfs.Issue       `json:"issues"`
+}
+
+// gtfsImport menyimpan rencana impor sebelum ditulis ke store
+type gtfsImport struct {
+	h      *Handler
+	feed   gtfs.Feed
+	opts   GTFSImportOptions
+	loc    *time.Location
+	result GTFSImportResult
+
+	haltes     map[string]*models.Halte // Berdasarkan kode
+	halteByID  map[primitive.ObjectID]models.Halte
+	halteAksi  map[string]string
+	halteLama  map[string]models.Halte // Isi sebelum impor untuk halte yang diubah
+	rutes      []*models.Rute
+	ruteAksi   map[string]string
+	ruteLama   map[string]models.Rute
+	ruteTrips  map[string][]gtfs.Trip
+	stopTimes  map[string][]gtfs.StopTime
+	jadwals    []importJadwal
+	hapus      []importJadwal               // Jadwal impor sebelumnya yang trip-nya tidak ada lagi di feed
+	kendaraans map[string]*models.Kendaraan // Berdasarkan nomor polisi, nil jika tidak ada
+	unlock     func()                       // Melepas kunci jadwal, nil jika belum dikunci
+}
+
+type importJadwal struct {
+	jadwal models.Jadwal
+	aksi   string
+	lama   models.Jadwal
+	trip   gtfs.Trip
+	kode   string
+}
+
+func (p *gtfsImport) issue(file string, line int, field, format string, args ...interface{}) {
+	p.result.Issues = append(p.result.Issues, gtfs.Issue{File: file, Line: line, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *gtfsImport) change(jenis, kode, aksi string) {
+	if aksi != "" {
+		p.result.Perubahan = append(p.result.Perubahan, GTFSImportChange{Jenis: jenis, Kode: kode, Aksi: aksi})
+	}
+}
+
+// ImportGTFS membaca feed GTFS lalu membuat atau memperbarui halte
+// (berdasarkan stop_id), rute (berdasarkan route_id sebagai kode_rute) dan
+// jadwal (berdasarkan trip_id dan tanggal layanan). Jadwal hasil impor
+// sebelumnya yang trip-nya tidak ada lagi di feed dalam rentang impor
+// dihapus. Impor hanya disimpan jika seluruh feed valid; jika ada issue,
+// laporan dikembalikan tanpa perubahan. Error hanya dikembalikan untuk file
+// yang bukan zip atau kegagalan store; jika store gagal saat menyimpan,
+// perubahan yang sudah tersimpan dibatalkan.
+func (h *Handler) ImportGTFS(ctx context.Context, r io.ReaderAt, size int64, opts GTFSImportOptions) (GTFSImportResult, error) {
+	feed, issues, err := gtfs.Read(r, size)
+	if err != nil {
+		return GTFSImportResult{}, err
+	}
+	if issues == nil {
+		issues = []gtfs.Issue{}
+	}
+
+	p := &gtfsImport{
+		h:    h,
+		feed: feed,
+		opts: opts,
+		result: GTFSImportResult{
+			DryRun:    opts.DryRun,
+			Perubahan: []GTFSImportChange{},
+			Warnings:  []string{},
+			Issues:    issues,
+		},
+		haltes:     map[string]*models.Halte{},
+		halteByID:  map[primitive.ObjectID]models.Halte{},
+		halteAksi:  map[string]string{},
+		halteLama:  map[string]models.Halte{},
+		ruteAksi:   map[string]string{},
+		ruteLama:   map[string]models.Rute{},
+		ruteTrips:  map[string][]gtfs.Trip{},
+		stopTimes:  map[string][]gtfs.StopTime{},
+		kendaraans: map[string]*models.Kendaraan{},
+	}
+	defer func() {
+		if p.unlock != nil {
+			p.unlock()
+		}
+	}()
+	// Feed yang melanggar aturan GTFS tidak direncanakan lebih lanjut karena
+	// baris yang rusak sudah dibuang dan referensinya tidak lengkap
+	if len(p.result.Issues) == 0 {
+		if err := p.plan(ctx); err != nil {
+			return p.result, err
+		}
+	}
+	if len(p.result.Issues) > maxGTFSIssues {
+		more := len(p.result.Issues) - maxGTFSIssues
+		p.result.Issues = append(p.result.Issues[:maxGTFSIssues], gtfs.Issue{Message: fmt.Sprintf("%d masalah lain tidak ditampilkan", more)})
+	}
+	if len(p.result.Issues) > 0 || opts.DryRun {
+		return p.result, nil
+	}
+	return p.result, p.apply(ctx)
+}
+
+func (p *gtfsImport) plan(ctx context.Context) error {
+	loc, err := time.LoadLocation(p.feed.Agencies[0].Timezone)
+	if err != nil {
+		return err
+	}
+	p.loc = loc
+
+	if err := p.planHalte(ctx); err != nil {
+		return err
+	}
+
+	for _, t := range p.feed.Trips {
+		p.ruteTrips[t.RouteID] = append(p.ruteTrips[t.RouteID], t)
+	}
+	for _, st := range p.feed.StopTimes {
+		p.stopTimes[st.TripID] = append(p.stopTimes[st.TripID], st)
+	}
+	for id, times := range p.stopTimes {
+		sort.SliceStable(times, func(a, b int) bool { return times[a].Sequence < times[b].Sequence })
+		p.stopTimes[id] = times
+	}
+
+	for _, route := range p.feed.Routes {
+		if err := p.planRute(ctx, route); err != nil {
+			return err
+		}
+	}
+	if len(p.result.Issues) > 0 {
+		return nil
+	}
+	return p.planJadwal(ctx)
+}
+
+func (p *gtfsImport) planHalte(ctx context.Context) error {
+	for _, stop := range p.feed.Stops {
+		halte := models.Halte{
+			Kode:   stop.ID,
+			Nama:   stop.Name,
+			Kota:   stop.Desc,
+			Lokasi: models.NewGeoPoint(stop.Lat, stop.Lon),
+		}
+
+		existing, err := p.h.Store.Halte.GetByKode(ctx, stop.ID)
+		if err != nil && err != store.ErrNotFound {
+			return err
+		}
+		found := err == nil
+		if halte.Kota == "" && found {
+			halte.Kota = existing.Kota
+		}
+		if halte.Kota == "" {
+			p.issue("stops.txt", stop.Line, "stop_desc", "wajib diisi dengan nama kota halte %s", stop.ID)
+			continue
+		}
+		if stop.Lat == 0 && stop.Lon == 0 {
+			p.issue("stops.txt", stop.Line, "stop_lat", "koordinat halte %s wajib diisi", stop.ID)
+			continue
+		}
+
+		aksi := aksiDibuat
+		if found {
+			halte.ID = existing.ID
+			p.halteLama[halte.Kode] = existing
+			aksi = ""
+			if halte.Nama != existing.Nama || halte.Kota != existing.Kota || !reflect.DeepEqual(halte.Lokasi, existing.Lokasi) {
+				aksi = aksiDiubah
+			}
+		} else {
+			halte.ID = primitive.NewObjectID()
+		}
+
+		p.haltes[halte.Kode] = &halte
+		p.halteByID[halte.ID] = halte
+		p.halteAksi[halte.Kode] = aksi
+		p.result.Halte.add(aksi)
+		p.change("halte", halte.Kode, aksi)
+	}
+	return nil
+}
+
+// planRute menyusun rute dari urutan halte trip-trip route tersebut. Satu
+// rute hanya punya satu urutan halte sehingga semua trip harus sama.
+func (p *gtfsImport) planRute(ctx context.Context, route gtfs.Route) error {
+	trips := p.ruteTrips[route.ID]
+	if len(trips) == 0 {
+		p.result.Warnings = append(p.result.Warnings, fmt.Sprintf("Route %s dilewati karena tidak memiliki trip", route.ID))
+		return nil
+	}
+
+	pattern := p.stopTimes[trips[0].ID]
+	valid := true
+	seen := map[string]bool{}
+	for _, st := range pattern {
+		if seen[st.StopID] {
+			p.issue("stop_times.txt", st.Line, "stop_id", "halte %s muncul lebih dari sekali pada trip %s", st.StopID, st.TripID)
+			valid = false
+		}
+		seen[st.StopID] = true
+		if p.haltes[st.StopID] == nil {
+			// Halte tidak valid, sudah dilaporkan di stops.txt
+			valid = false
+		}
+	}
+	for _, t := range trips[1:] {
+		if !sameStops(pattern, p.stopTimes[t.ID]) {
+			p.issue("trips.txt", t.Line, "trip_id", "trip %s melewati halte yang berbeda dari trip %s; satu route hanya boleh memiliki satu urutan halte", t.ID, trips[0].ID)
+			valid = false
+		}
+	}
+	if !valid {
+		return nil
+	}
+
+	// Jarak memakai shape_dist_traveled jika lengkap, selain itu jarak garis
+	// lurus antar halte. GTFS tidak menetapkan satuan shape_dist_traveled
+	// (feed ekspor aplikasi ini memakai kilometer, banyak feed lain meter),
+	// jadi satuannya ditentukan dari perbandingan dengan jarak garis lurus.
+	jarak := make([]float64, len(pattern))
+	useShape := true
+	for i, st := range pattern {
+		useShape = useShape && st.HasShapeDist
+		if i > 0 {
+			a, b := p.haltes[pattern[i-1].StopID].Lokasi, p.haltes[st.StopID].Lokasi
+			jarak[i] = jarak[i-1] + geo.Haversine(a.Lat(), a.Lng(), b.Lat(), b.Lng())/1000
+		}
+	}
+	if useShape {
+		last := len(pattern) - 1
+		if skala, ok := shapeDistSkala(pattern[last].ShapeDist-pattern[0].ShapeDist, jarak[last]); ok {
+			for i, st := range pattern {
+				jarak[i] = (st.ShapeDist - pattern[0].ShapeDist) / skala
+			}
+		} else {
+			p.result.Warnings = append(p.result.Warnings, fmt.Sprintf("shape_dist_traveled route %s tidak sebanding dengan jarak antar halte dalam kilometer maupun meter, jarak garis lurus dipakai", route.ID))
+		}
+	}
+
+	rute := models.Rute{
+		KodeRute: route.ID,
+		NamaRute: route.LongName,
+		JarakKM:  int(math.Round(jarak[len(jarak)-1])),
+	}
+	if rute.NamaRute == "" {
+		rute.NamaRute = route.ShortName
+	}
+	if rute.JarakKM <= 0 {
+		p.issue("routes.txt", route.Line, "route_id", "jarak route %s harus lebih dari 0 km", route.ID)
+		return nil
+	}
+
+	for i, st := range pattern {
+		stop := models.RuteHalte{
+			HalteID: p.haltes[st.StopID].ID,
+			JarakKM: math.Round(jarak[i]*10) / 10,
+		}
+		if i > 0 && stop.JarakKM <= rute.Halte[i-1].JarakKM {
+			p.issue("stop_times.txt", st.Line, "stop_id", "jarak halte %s harus lebih besar dari halte sebelumnya pada route %s", st.StopID, route.ID)
+			return nil
+		}
+		if i > 0 && i < len(pattern)-1 && st.Arrival != gtfs.NoTime && st.Departure != gtfs.NoTime {
+			stop.DwellMenit = int(st.Departure-st.Arrival) / 60
+		}
+		rute.Halte = append(rute.Halte, stop)
+	}
+
+	first, last := p.haltes[pattern[0].StopID], p.haltes[pattern[len(pattern)-1].StopID]
+	rute.Asal, rute.Tujuan = first.Kota, last.Kota
+
+	zona := p.feed.Agencies[0].Timezone
+	for _, stop := range p.feed.Stops {
+		if stop.ID == first.Kode && stop.Timezone != "" {
+			zona = stop.Timezone
+		}
+	}
+	zona, err := normalizeZonaWaktu(zona)
+	if err != nil {
+		p.issue("stops.txt", 0, "stop_timezone", "%s", err.Error())
+		return nil
+	}
+	rute.ZonaWaktu = zona
+
+	existing, err := p.h.Store.Rute.GetByKode(ctx, route.ID)
+	if err != nil && err != store.ErrNotFound {
+		return err
+	}
+	aksi := aksiDibuat
+	if err == nil {
+		rute.ID = existing.ID
+		p.ruteLama[rute.KodeRute] = existing
+		// Rute yang punya geometri memakai jarak dari geometri
+		if existing.Geometri != nil {
+			rute.Geometri = existing.Geometri
+			if msg := fitGeometri(&rute, p.halteByID); msg != "" {
+				p.issue("routes.txt", route.Line, "route_id", "route %s tidak cocok dengan geometri yang tersimpan: %s", route.ID, msg)
+				return nil
+			}
+		}
+		aksi = ""
+		if rute.NamaRute != existing.NamaRute || rute.Asal != existing.Asal || rute.Tujuan != existing.Tujuan ||
+			rute.JarakKM != existing.JarakKM || rute.ZonaWaktu != existing.ZonaWaktu || !reflect.DeepEqual(rute.Halte, existing.Halte) {
+			aksi = aksiDiubah
+		}
+	} else {
+		rute.ID = primitive.NewObjectID()
+	}
+
+	p.rutes = append(p.rutes, &rute)
+	p.ruteAksi[rute.KodeRute] = aksi
+	p.result.Rute.add(aksi)
+	p.change("rute", rute.KodeRute, aksi)
+	return nil
+}
+
+// Jarak sepanjang jalan lebih panjang dari jarak garis lurus, tetapi
+// jarang lebih dari beberapa kali lipat. Batas bawah memberi ruang untuk
+// koordinat halte yang sedikit meleset dari jalurnya.
+const (
+	shapeRasioMin = 0.9
+	shapeRasioMax = 4
+)
+
+// shapeDistSkala mengembalikan pembagi shape_dist_traveled ke kilometer (1
+// untuk kilometer, 1000 untuk meter) yang membuat panjang shape sebanding
+// dengan jarak garis lurus, atau false jika tidak ada yang cocok
+func shapeDistSkala(shape, garisLurusKM float64) (float64, bool) {
+	if garisLurusKM <= 0 {
+		return 0, false
+	}
+	for _, skala := range []float64{1, 1000} {
+		rasio := shape / skala / garisLurusKM
+		if rasio >= shapeRasioMin && rasio <= shapeRasioMax {
+			return skala, true
+		}
+	}
+	return 0, false
+}
+
+func sameStops(a, b []gtfs.StopTime) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].StopID != b[i].StopID {
+			return false
+		}
+	}
+	return true
+}
+
+// planJadwal membuat satu jadwal untuk setiap trip pada setiap tanggal
+// layanan dalam rentang impor
+func (p *gtfsImport) planJadwal(ctx context.Context) error {
+	calendars := map[string]gtfs.Calendar{}
+	for _, c := range p.feed.Calendars {
+		calendars[c.ServiceID] = c
+	}
+
+	dari := p.opts.Dari
+	if dari.IsZero() {
+		dari = time.Now().In(p.loc)
+	}
+	hari := p.opts.Hari
+	if hari <= 0 {
+		hari = defaultGTFSHari
+	}
+
+	// Jadwal tersimpan dicari untuk seluruh rentang impor ditambah sehari di
+	// kedua ujungnya, karena trip bisa berangkat lewat tengah malam atau
+	// jatuh pada tanggal lain di zona waktu rute
+	layanan := map[string]bool{}
+	for i := 0; i < hari; i++ {
+		layanan[time.Date(dari.Year(), dari.Month(), dari.Day()+i, 0, 0, 0, 0, time.UTC).Format("20060102")] = true
+	}
+	var keys []store.RuteTanggal
+	ruteByID := map[primitive.ObjectID]*models.Rute{}
+	for _, rute := range p.rutes {
+		ruteByID[rute.ID] = rute
+		if p.ruteAksi[rute.KodeRute] == aksiDibuat {
+			continue
+		}
+		ruteLoc := loadZonaWaktu(rute.ZonaWaktu)
+		for i := -1; i <= hari; i++ {
+			keys = append(keys, store.RuteTanggal{RuteID: rute.ID, Tanggal: time.Date(dari.Year(), dari.Month(), dari.Day()+i, 0, 0, 0, 0, ruteLoc)})
+		}
+	}
+
+	for _, rute := range p.rutes {
+		ruteLoc := loadZonaWaktu(rute.ZonaWaktu)
+		for _, trip := range p.ruteTrips[rute.KodeRute] {
+			times := p.stopTimes[trip.ID]
+			first, last := times[0], times[len(times)-1]
+			berangkat, tiba := first.Departure, last.Arrival
+			if berangkat == gtfs.NoTime {
+				berangkat = first.Arrival
+			}
+			if tiba == gtfs.NoTime {
+				tiba = last.Departure
+			}
+			if tiba <= berangkat {
+				p.issue("stop_times.txt", last.Line, "arrival_time", "waktu tiba trip %s harus setelah waktu berangkat", trip.ID)
+				continue
+			}
+
+			kendaraan, err := p.kendaraan(ctx, trip)
+			if err != nil {
+				return err
+			}
+			if kendaraan == nil {
+				p.issue("trips.txt", trip.Line, "block_id", "kendaraan trip %s tidak diketahui; isi block_id dengan nomor polisi atau kirim nomor_polisi", trip.ID)
+				continue
+			}
+
+			for i := 0; i < hari; i++ {
+				date := time.Date(dari.Year(), dari.Month(), dari.Day()+i, 0, 0, 0, 0, time.UTC)
+				if !calendars[trip.ServiceID].Runs(date) {
+					continue
+				}
+				day := gtfs.ServiceDay(date.Year(), date.Month(), date.Day(), p.loc)
+				start := day.Add(time.Duration(berangkat) * time.Second)
+				local := start.In(ruteLoc)
+				tanggal := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, ruteLoc)
+
+				p.jadwals = append(p.jadwals, importJadwal{
+					jadwal: models.Jadwal{
+						Tanggal:        tanggal,
+						WaktuBerangkat: start,
+						EstimasiTiba:   day.Add(time.Duration(tiba) * time.Second),
+						RuteID:         rute.ID,
+						KendaraanID:    kendaraan.ID,
+						GTFS:           &models.JadwalGTFS{TripID: trip.ID, Tanggal: date.Format("20060102")},
+					},
+					trip: trip,
+					kode: rute.KodeRute + " " + local.Format("2006-01-02 15:04"),
+				})
+			}
+		}
+	}
+
+	// Jadwal yang sudah ada dicocokkan berdasarkan trip dan tanggal layanan.
+	// Jadwal impor lama yang belum menyimpan trip dicocokkan berdasarkan rute
+	// dan waktu berangkat, lalu ditandai dengan trip-nya.
+	byTrip, byWaktu := map[string]models.Jadwal{}, map[string]models.Jadwal{}
+	if len(keys) > 0 {
+		found, err := p.h.Store.Jadwal.FindByRuteTanggal(ctx, keys)
+		if err != nil {
+			return err
+		}
+		for _, j := range found {
+			if j.GTFS != nil {
+				byTrip[tripKey(*j.GTFS)] = j
+			} else {
+				byWaktu[jadwalKey(j)] = j
+			}
+		}
+	}
+
+	matched := map[primitive.ObjectID]bool{}
+	for i := range p.jadwals {
+		ij := &p.jadwals[i]
+		old, ok := byTrip[tripKey(*ij.jadwal.GTFS)]
+		if !ok {
+			old, ok = byWaktu[jadwalKey(ij.jadwal)]
+		}
+		if ok && !matched[old.ID] {
+			matched[old.ID] = true
+			// Kendaraan jadwal yang sudah ada tidak diganti karena kursinya
+			// mungkin sudah dipesan
+			ij.jadwal.ID = old.ID
+			ij.lama = old
+			ij.jadwal.KendaraanID = old.KendaraanID
+			ij.jadwal.Pengemudi = old.Pengemudi
+			if old.GTFS == nil || old.RuteID != ij.jadwal.RuteID || !old.WaktuBerangkat.Equal(ij.jadwal.WaktuBerangkat) ||
+				!old.EstimasiTiba.Equal(ij.jadwal.EstimasiTiba) || !old.Tanggal.Equal(ij.jadwal.Tanggal) {
+				ij.aksi = aksiDiubah
+			}
+		} else {
+			ij.jadwal.ID = primitive.NewObjectID()
+			ij.aksi = aksiDibuat
+		}
+	}
+
+	// Jadwal impor sebelumnya dalam rentang impor yang trip-nya tidak ada
+	// lagi di feed dihapus, kecuali yang sudah dipesan
+	for _, j := range byTrip {
+		if matched[j.ID] || !layanan[j.GTFS.Tanggal] {
+			continue
+		}
+		rute := ruteByID[j.RuteID]
+		kode := rute.KodeRute + " " + j.WaktuBerangkat.In(loadZonaWaktu(rute.ZonaWaktu)).Format("2006-01-02 15:04")
+		if j.KursiTerisi > 0 {
+			p.result.Warnings = append(p.result.Warnings, fmt.Sprintf("Jadwal %s (trip %s) tidak ada lagi di feed tetapi masih memiliki booking, jadwal tidak dihapus", kode, j.GTFS.TripID))
+			continue
+		}
+		p.hapus = append(p.hapus, importJadwal{jadwal: j, aksi: aksiDihapus, lama: j, kode: kode})
+	}
+	sort.Slice(p.hapus, func(a, b int) bool { return p.hapus[a].jadwal.WaktuBerangkat.Before(p.hapus[b].jadwal.WaktuBerangkat) })
+
+	if !p.opts.DryRun {
+		if err := p.lock(ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.checkBentrok(ctx); err != nil {
+		return err
+	}
+	for _, ij := range append(p.jadwals[:len(p.jadwals):len(p.jadwals)], p.hapus...) {
+		p.result.Jadwal.add(ij.aksi)
+		p.change("jadwal", ij.kode, ij.aksi)
+	}
+	return nil
+}
+
+func tripKey(g models.JadwalGTFS) string {
+	return g.TripID + "/" + g.Tanggal
+}
+
+func jadwalKey(j models.Jadwal) string {
+	return j.RuteID.Hex() + "/" + strconv.FormatInt(j.WaktuBerangkat.Unix(), 10)
+}
+
+// kendaraan mencari kendaraan trip dari block_id, lalu dari nomor polisi
+// default. nil berarti kendaraan tidak ditemukan.
+func (p *gtfsImport) kendaraan(ctx context.Context, trip gtfs.Trip) (*models.Kendaraan, error) {
+	for _, nomor := range []string{trip.BlockID, p.opts.NomorPolisi} {
+		nomor = strings.TrimSpace(nomor)
+		if nomor == "" {
+			continue
+		}
+		k, cached := p.kendaraans[nomor]
+		if !cached {
+			found, err := p.h.Store.Kendaraan.GetByNomorPolisi(ctx, nomor)
+			if err != nil && err != store.ErrNotFound {
+				return nil, err
+			}
+			if err == nil {
+				k = &found
+			}
+			p.kendaraans[nomor] = k
+		}
+		if k != nil {
+			return k, nil
+		}
+	}
+	return nil, nil
+}
+
+// lock mengunci kendaraan dan pengemudi semua jadwal yang dibuat atau diubah.
+// Kunci dipegang sampai apply selesai agar jadwal lain untuk kendaraan atau
+// pengemudi yang sama tidak dibuat di antara cek bentrok dan penyimpanan.
+func (p *gtfsImport) lock(ctx context.Context) error {
+	keys := []string{}
+	for _, ij := range p.jadwals {
+		if ij.aksi != "" {
+			keys = append(keys, kendaraanLockKey(ij.jadwal.KendaraanID), pengemudiLockKey(ij.jadwal.Pengemudi))
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	unlock, err := p.h.lockJadwal(ctx, gtfsLockTTL, keys...)
+	if err != nil {
+		return err
+	}
+	p.unlock = unlock
+	return nil
+}
+
+// checkBentrok menolak jadwal yang kendaraannya bentrok, baik dengan jadwal
+// lain di feed maupun dengan jadwal yang sudah tersimpan
+func (p *gtfsImport) checkBentrok(ctx context.Context) error {
+	byKendaraan := map[primitive.ObjectID][]*importJadwal{}
+	for i := range p.jadwals {
+		ij := &p.jadwals[i]
+		byKendaraan[ij.jadwal.KendaraanID] = append(byKendaraan[ij.jadwal.KendaraanID], ij)
+	}
+	for _, list := range byKendaraan {
+		sort.SliceStable(list, func(a, b int) bool { return list[a].jadwal.WaktuBerangkat.Before(list[b].jadwal.WaktuBerangkat) })
+		for i := 1; i < len(list); i++ {
+			prev, cur := list[i-1], list[i]
+			if cur.jadwal.WaktuBerangkat.Before(prev.jadwal.EstimasiTiba) {
+				p.issue("trips.txt", cur.trip.Line, "block_id", "jadwal %s bentrok dengan jadwal %s pada kendaraan yang sama", cur.kode, prev.kode)
+			}
+		}
+	}
+
+	// Jadwal tersimpan diambil dengan satu query untuk seluruh kendaraan,
+	// pengemudi dan rentang waktu feed lalu dicocokkan di memori
+	var (
+		start, end   time.Time
+		kendaraanIDs []primitive.ObjectID
+		pengemudi    []string
+	)
+	feedIDs := map[primitive.ObjectID]bool{}
+	for _, ij := range p.hapus {
+		feedIDs[ij.jadwal.ID] = true
+	}
+	seenKendaraan, seenPengemudi := map[primitive.ObjectID]bool{}, map[string]bool{}
+	for _, ij := range p.jadwals {
+		feedIDs[ij.jadwal.ID] = true
+		if ij.aksi == "" {
+			continue
+		}
+		if start.IsZero() || ij.jadwal.WaktuBerangkat.Before(start) {
+			start = ij.jadwal.WaktuBerangkat
+		}
+		if ij.jadwal.EstimasiTiba.After(end) {
+			end = ij.jadwal.EstimasiTiba
+		}
+		if !seenKendaraan[ij.jadwal.KendaraanID] {
+			seenKendaraan[ij.jadwal.KendaraanID] = true
+			kendaraanIDs = append(kendaraanIDs, ij.jadwal.KendaraanID)
+		}
+		if ij.jadwal.Pengemudi != "" && !seenPengemudi[ij.jadwal.Pengemudi] {
+			seenPengemudi[ij.jadwal.Pengemudi] = true
+			pengemudi = append(pengemudi, ij.jadwal.Pengemudi)
+		}
+	}
+	if len(kendaraanIDs) == 0 {
+		return nil
+	}
+	stored, err := p.h.Store.Jadwal.FindOverlapping(ctx, kendaraanIDs, pengemudi, start, end)
+	if err != nil {
+		return err
+	}
+
+	// Jadwal yang ikut diimpor sudah diperiksa dengan waktu barunya di atas,
+	// dan jadwal yang akan dihapus tidak lagi menempati kendaraannya
+	storedKendaraan, storedPengemudi := map[primitive.ObjectID][]models.Jadwal{}, map[string][]models.Jadwal{}
+	for _, j := range stored {
+		if feedIDs[j.ID] {
+			continue
+		}
+		storedKendaraan[j.KendaraanID] = append(storedKendaraan[j.KendaraanID], j)
+		if j.Pengemudi != "" {
+			storedPengemudi[j.Pengemudi] = append(storedPengemudi[j.Pengemudi], j)
+		}
+	}
+
+	for _, ij := range p.jadwals {
+		if ij.aksi == "" {
+			continue
+		}
+		candidates := storedKendaraan[ij.jadwal.KendaraanID]
+		if ij.jadwal.Pengemudi != "" {
+			candidates = append(candidates[:len(candidates):len(candidates)], storedPengemudi[ij.jadwal.Pengemudi]...)
+		}
+		conflicts := []string{}
+		seen := map[primitive.ObjectID]bool{}
+		for _, j := range candidates {
+			if !seen[j.ID] && j.WaktuBerangkat.Before(ij.jadwal.EstimasiTiba) && j.EstimasiTiba.After(ij.jadwal.WaktuBerangkat) {
+				seen[j.ID] = true
+				conflicts = append(conflicts, j.ID.Hex())
+			}
+		}
+		if len(conflicts) > 0 {
+			p.issue("trips.txt", ij.trip.Line, "block_id", "jadwal %s bentrok dengan jadwal tersimpan %s", ij.kode, strings.Join(conflicts, ", "))
+		}
+	}
+	return nil
+}
+
+// apply menyimpan rencana impor: halte lebih dulu, lalu rute, lalu jadwal.
+// Store tidak memakai transaksi (butuh replica set), jadi setiap penulisan
+// mencatat pembatalannya dan jika satu penulisan gagal semua yang sudah
+// tersimpan dikembalikan ke keadaan sebelum impor.
+func (p *gtfsImport) apply(ctx context.Context) (err error) {
+	var undo []func(context.Context) error
+	defer func() {
+		if err != nil {
+			if failed := p.rollback(undo); failed > 0 {
+				err = fmt.Errorf("%w; %d perubahan gagal dibatalkan", err, failed)
+			}
+		}
+	}()
+
+	for kode, halte := range p.haltes {
+		halte := halte
+		switch p.halteAksi[kode] {
+		case aksiDibuat:
+			err = p.h.Store.Halte.Create(ctx, halte)
+			undo = append(undo, func(ctx context.Context) error { return p.h.Store.Halte.Delete(ctx, halte.ID) })
+		case aksiDiubah:
+			err = p.h.Store.Halte.Update(ctx, *halte)
+			lama := p.halteLama[kode]
+			undo = append(undo, func(ctx context.Context) error { return p.h.Store.Halte.Update(ctx, lama) })
+		}
+		if err != nil {
+			return fmt.Errorf("halte %s: %w", kode, err)
+		}
+	}
+	for _, rute := range p.rutes {
+		rute := rute
+		switch p.ruteAksi[rute.KodeRute] {
+		case aksiDibuat:
+			err = p.h.Store.Rute.Create(ctx, rute)
+			undo = append(undo, func(ctx context.Context) error { return p.h.Store.Rute.Delete(ctx, rute.ID) })
+		case aksiDiubah:
+			err = p.h.Store.Rute.Update(ctx, *rute)
+			lama := p.ruteLama[rute.KodeRute]
+			undo = append(undo, func(ctx context.Context) error { return p.h.Store.Rute.Update(ctx, lama) })
+		}
+		if err != nil {
+			return fmt.Errorf("rute %s: %w", rute.KodeRute, err)
+		}
+	}
+	for i := range p.jadwals {
+		ij := &p.jadwals[i]
+		switch ij.aksi {
+		case aksiDibuat:
+			err = p.h.Store.Jadwal.Create(ctx, &ij.jadwal)
+			undo = append(undo, func(ctx context.Context) error { return p.h.Store.Jadwal.Delete(ctx, ij.jadwal.ID) })
+		case aksiDiubah:
+			err = p.h.Store.Jadwal.Update(ctx, ij.jadwal.ID, jadwalUpdate(ij.jadwal))
+			undo = append(undo, func(ctx context.Context) error {
+				return p.h.Store.Jadwal.Update(ctx, ij.lama.ID, jadwalUpdate(ij.lama))
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("jadwal %s: %w", ij.kode, err)
+		}
+	}
+	for i := range p.hapus {
+		ij := &p.hapus[i]
+		err = p.h.Store.Jadwal.Delete(ctx, ij.jadwal.ID)
+		if err == store.ErrBooked || err == store.ErrNotFound {
+			// Dipesan atau dihapus request lain setelah rencana dibuat
+			p.result.Warnings = append(p.result.Warnings, fmt.Sprintf("Jadwal %s tidak dihapus: %v", ij.kode, err))
+			err = nil
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("jadwal %s: %w", ij.kode, err)
+		}
+		undo = append(undo, func(ctx context.Context) error { return p.h.Store.Jadwal.Create(ctx, &ij.lama) })
+	}
+	return nil
+}
+
+// rollback menjalankan pembatalan dari penulisan terakhir dan mengembalikan
+// jumlah pembatalan yang gagal. Pembatalan untuk penulisan yang gagal ikut
+// dijalankan; ErrNotFound darinya berarti memang tidak ada yang tersimpan.
+func (p *gtfsImport) rollback(undo []func(context.Context) error) int {
+	// Context impor bisa sudah habis, pembatalan tetap harus berjalan
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	failed := 0
+	for i := len(undo) - 1; i >= 0; i-- {
+		if err := undo[i](ctx); err != nil && err != store.ErrNotFound {
+			fmt.Println("❌ Gagal membatalkan impor GTFS:", err)
+			failed++
+		}
+	}
+	return failed
+}
+
+func jadwalUpdate(j models.Jadwal) store.JadwalUpdate {
+	return store.JadwalUpdate{
+		Tanggal:        j.Tanggal,
+		WaktuBerangkat: j.WaktuBerangkat,
+		EstimasiTiba:   j.EstimasiTiba,
+		RuteID:         j.RuteID,
+		Pengemudi:      j.Pengemudi,
+		GTFS:           j.GTFS,
+	}
+}
+
+// PostGTFSImport godoc
+// @Summary Import GTFS feed
+// @Description Membuat atau memperbarui halte (stop_id), rute (route_id sebagai kode_rute) dan jadwal dari feed GTFS static. stop_desc dibaca sebagai kota halte, block_id sebagai nomor polisi kendaraan, dan shape_dist_traveled dalam kilometer atau meter (ditentukan dari jarak garis lurus antar halte; jika tidak sebanding, jarak garis lurus dipakai). Satu route hanya boleh memiliki satu urutan halte. Jadwal dibuat dari calendar.txt untuk rentang dari/hari dan dicocokkan dengan impor sebelumnya berdasarkan trip_id dan tanggal layanan; jadwal impor sebelumnya yang trip-nya tidak ada lagi di feed dihapus kecuali sudah dipesan. calendar_dates.txt tidak dibaca. Feed hanya disimpan jika tidak ada issue. Ukuran file maksimal 4 MB, gunakan cmd/gtfs-import untuk feed yang lebih besar
+// @Tags GTFS
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Zip GTFS"
+// @Param dry_run query bool false "Hanya laporkan perubahan tanpa menyimpan"
+// @Param nomor_polisi query string false "Kendaraan untuk trip tanpa block_id yang cocok"
+// @Param dari query string false "Tanggal awal jadwal (YYYY-MM-DD, default hari ini)"
+// @Param hari query int false "Jumlah hari jadwal (default 60, maks 366)"
+// @Success 200 {object} GTFSImportResult "Laporan impor"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 409 {object} models.ErrorResponse "Kendaraan atau pengemudi sedang dijadwalkan oleh request lain"
+// @Failure 422 {object} GTFSImportResult "Feed tidak valid, tidak ada yang disimpan"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/gtfs/import [post]
+// @Security BearerAuth
+func (h *Handler) PostGTFSImport(c *fiber.Ctx) error {
+	opts := GTFSImportOptions{
+		DryRun:      c.QueryBool("dry_run", false),
+		NomorPolisi: c.Query("nomor_polisi"),
+		Hari:        c.QueryInt("hari", defaultGTFSHari),
+	}
+	if v := c.Query("dari"); v != "" {
+		t, err := parseWithLayouts(v, tanggalLayouts, time.UTC)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Format tanggal tidak valid"})
+		}
+		opts.Dari = t
+	}
+	if opts.Hari <= 0 || opts.Hari > maxGTFSHari {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("hari harus antara 1 dan %d", maxGTFSHari)})
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "File GTFS wajib diunggah pada field file"})
+	}
+	file, err := header.Open()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "File GTFS tidak bisa dibaca"})
+	}
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	result, err := h.ImportGTFS(ctx, file, header.Size, opts)
+	if errors.Is(err, gtfs.ErrNotZip) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err == errJadwalLocked {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		fmt.Println("❌ Gagal mengimpor GTFS:", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(result.Issues) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(result)
+	}
+	if !opts.DryRun {
+		fmt.Printf("✅ GTFS diimpor: %d halte, %d rute, %d jadwal berubah\n",
+			result.Halte.Dibuat+result.Halte.Diubah, result.Rute.Dibuat+result.Rute.Diubah, result.Jadwal.Dibuat+result.Jadwal.Diubah+result.Jadwal.Dihapus)
+	}
+	return c.JSON(result)
+}
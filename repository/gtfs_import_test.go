@@ -0,0 +1,504 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"transport-app/gtfs"
+	"transport-app/models"
+	"transport-app/repository"
+	"transport-app/store"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// importDari adalah tanggal awal impor di masa depan agar jadwal hasil
+// impor tidak pernah dianggap sudah berangkat
+var importDari = time.Date(2030, 7, 22, 0, 0, 0, 0, time.UTC)
+
+// importFeed adalah feed satu rute dengan tiga halte dan dua trip harian
+// (07:00 dan 12:00, masing-masing 90 menit) untuk kendaraan B 1234 CD
+func importFeed() gtfs.Feed {
+	c := gtfs.Calendar{ServiceID: "harian", Start: importDari, End: importDari.AddDate(0, 1, 0)}
+	for i := range c.Days {
+		c.Days[i] = true
+	}
+
+	feed := gtfs.Feed{
+		Agencies: []gtfs.Agency{{ID: "transport-app", Name: "Transport App", URL: "https://example.com", Timezone: "Asia/Jakarta"}},
+		Routes:   []gtfs.Route{{ID: "R1", AgencyID: "transport-app", ShortName: "R1", LongName: "Kota - Bandara", Type: gtfs.RouteTypeBus}},
+		Stops: []gtfs.Stop{
+			{ID: "KOTA", Name: "Terminal Kota", Desc: "Jakarta", Lat: -6.2, Lon: 106.8},
+			{ID: "TOL", Name: "Halte Tol", Desc: "Jakarta", Lat: -6.15, Lon: 106.75},
+			{ID: "CGK", Name: "Bandara", Desc: "Tangerang", Lat: -6.12, Lon: 106.65},
+		},
+		Calendars: []gtfs.Calendar{c},
+	}
+	for _, trip := range []struct {
+		id  string
+		jam gtfs.Time
+	}{{"T07", 7}, {"T12", 12}} {
+		feed.Trips = append(feed.Trips, gtfs.Trip{RouteID: "R1", ServiceID: "harian", ID: trip.id, BlockID: "B 1234 CD"})
+		start := trip.jam * 3600
+		feed.StopTimes = append(feed.StopTimes,
+			gtfs.StopTime{TripID: trip.id, Arrival: start, Departure: start, StopID: "KOTA", Sequence: 0, HasShapeDist: true},
+			gtfs.StopTime{TripID: trip.id, Arrival: start + 40*60, Departure: start + 45*60, StopID: "TOL", Sequence: 1, ShapeDist: 12, HasShapeDist: true},
+			gtfs.StopTime{TripID: trip.id, Arrival: start + 90*60, Departure: start + 90*60, StopID: "CGK", Sequence: 2, ShapeDist: 30, HasShapeDist: true},
+		)
+	}
+	return feed
+}
+
+func feedZip(t *testing.T, feed gtfs.Feed) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := gtfs.Write(&buf, feed); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func seedImportKendaraan(t *testing.T, s *testServer) models.Kendaraan {
+	t.Helper()
+	kendaraan := models.Kendaraan{NomorPolisi: "B 1234 CD", Jenis: "Bus", Kapasitas: 40, Status: "aktif"}
+	if err := s.store.Kendaraan.Create(context.Background(), &kendaraan); err != nil {
+		t.Fatal(err)
+	}
+	return kendaraan
+}
+
+func importGTFS(t *testing.T, s *testServer, feed gtfs.Feed, opts repository.GTFSImportOptions) repository.GTFSImportResult {
+	t.Helper()
+	data := feedZip(t, feed)
+	if opts.Dari.IsZero() {
+		opts.Dari = importDari
+	}
+	result, err := s.h.ImportGTFS(context.Background(), bytes.NewReader(data), int64(len(data)), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// countingJadwalStore menghitung query bentrok yang dikirim ke store
+type countingJadwalStore struct {
+	store.JadwalStore
+	conflicts, overlapping int
+}
+
+func (c *countingJadwalStore) FindConflicts(ctx context.Context, kendaraanID primitive.ObjectID, pengemudi string, start, end time.Time, excludeID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	c.conflicts++
+	return c.JadwalStore.FindConflicts(ctx, kendaraanID, pengemudi, start, end, excludeID)
+}
+
+func (c *countingJadwalStore) FindOverlapping(ctx context.Context, kendaraanIDs []primitive.ObjectID, pengemudi []string, start, end time.Time) ([]models.Jadwal, error) {
+	c.overlapping++
+	return c.JadwalStore.FindOverlapping(ctx, kendaraanIDs, pengemudi, start, end)
+}
+
+// TestGTFSImportConflictQueries memastikan bentrok dengan jadwal tersimpan
+// diperiksa dengan satu query berapa pun jumlah jadwal di feed
+func TestGTFSImportConflictQueries(t *testing.T) {
+	s := newTestServer(t)
+	kendaraan := seedImportKendaraan(t, s)
+
+	// Jadwal lain kendaraan yang sama pada hari ketiga pukul 12:30 WIB
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	day := time.Date(2030, 7, 24, 0, 0, 0, 0, loc)
+	lain := models.Jadwal{
+		Tanggal: day, WaktuBerangkat: day.Add(12*time.Hour + 30*time.Minute), EstimasiTiba: day.Add(14 * time.Hour),
+		RuteID: primitive.NewObjectID(), KendaraanID: kendaraan.ID,
+	}
+	if err := s.store.Jadwal.Create(context.Background(), &lain); err != nil {
+		t.Fatal(err)
+	}
+
+	counter := &countingJadwalStore{JadwalStore: s.store.Jadwal}
+	s.store.Jadwal = counter
+
+	result := importGTFS(t, s, importFeed(), repository.GTFSImportOptions{Hari: 14, DryRun: true})
+	if counter.conflicts != 0 || counter.overlapping != 1 {
+		t.Fatalf("%d FindConflicts dan %d FindOverlapping untuk %d jadwal, seharusnya 0 dan 1",
+			counter.conflicts, counter.overlapping, result.Jadwal.Dibuat)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].File != "trips.txt" || result.Issues[0].Field != "block_id" {
+		t.Fatalf("issues %v, seharusnya satu bentrok trip T12 pada 2030-07-24", result.Issues)
+	}
+}
+
+// failingJadwalStore mensimulasikan database yang terputus setelah
+// beberapa jadwal tersimpan
+type failingJadwalStore struct {
+	store.JadwalStore
+	sisa int
+}
+
+func (f *failingJadwalStore) Create(ctx context.Context, jadwal *models.Jadwal) error {
+	if f.sisa == 0 {
+		return errors.New("koneksi terputus")
+	}
+	f.sisa--
+	return f.JadwalStore.Create(ctx, jadwal)
+}
+
+// TestGTFSImportRollback memastikan kegagalan di tengah penyimpanan
+// membatalkan halte, rute dan jadwal yang sudah tersimpan
+func TestGTFSImportRollback(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	seedImportKendaraan(t, s)
+	lama := models.Halte{Kode: "KOTA", Nama: "Terminal Lama", Kota: "Jakarta", Lokasi: models.NewGeoPoint(-6.2, 106.8)}
+	if err := s.store.Halte.Create(ctx, &lama); err != nil {
+		t.Fatal(err)
+	}
+
+	jadwalStore := s.store.Jadwal
+	s.store.Jadwal = &failingJadwalStore{JadwalStore: jadwalStore, sisa: 3}
+
+	data := feedZip(t, importFeed())
+	_, err := s.h.ImportGTFS(ctx, bytes.NewReader(data), int64(len(data)), repository.GTFSImportOptions{Dari: importDari, Hari: 7})
+	if err == nil {
+		t.Fatal("impor berhasil walaupun store gagal")
+	}
+
+	if halte, err := s.store.Halte.GetByKode(ctx, "KOTA"); err != nil || halte.Nama != "Terminal Lama" {
+		t.Fatalf("halte yang diubah tidak dikembalikan: %+v %v", halte, err)
+	}
+	for _, kode := range []string{"TOL", "CGK"} {
+		if _, err := s.store.Halte.GetByKode(ctx, kode); err != store.ErrNotFound {
+			t.Fatalf("halte %s yang dibuat tidak dihapus: %v", kode, err)
+		}
+	}
+	if _, err := s.store.Rute.GetByKode(ctx, "R1"); err != store.ErrNotFound {
+		t.Fatalf("rute yang dibuat tidak dihapus: %v", err)
+	}
+	jadwals, err := jadwalStore.FindDepartingBetween(ctx, importDari.AddDate(0, 0, -1), importDari.AddDate(0, 0, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jadwals) != 0 {
+		t.Fatalf("%d jadwal yang dibuat tidak dihapus", len(jadwals))
+	}
+}
+
+// lockCheckingJadwalStore mencoba mengambil kunci kendaraan sebagai request
+// lain setiap kali impor memeriksa bentrok atau menyimpan jadwal
+type lockCheckingJadwalStore struct {
+	store.JadwalStore
+	lock     store.LockStore
+	key      string
+	checks   int
+	unlocked int
+}
+
+func (l *lockCheckingJadwalStore) check(ctx context.Context) {
+	l.checks++
+	if err := l.lock.Acquire(ctx, l.key, "request-lain", time.Now().Add(time.Minute)); err != store.ErrLocked {
+		l.unlocked++
+		l.lock.Release(ctx, l.key, "request-lain")
+	}
+}
+
+func (l *lockCheckingJadwalStore) FindOverlapping(ctx context.Context, kendaraanIDs []primitive.ObjectID, pengemudi []string, start, end time.Time) ([]models.Jadwal, error) {
+	l.check(ctx)
+	return l.JadwalStore.FindOverlapping(ctx, kendaraanIDs, pengemudi, start, end)
+}
+
+func (l *lockCheckingJadwalStore) Create(ctx context.Context, jadwal *models.Jadwal) error {
+	l.check(ctx)
+	return l.JadwalStore.Create(ctx, jadwal)
+}
+
+// TestGTFSImportLock memastikan kunci kendaraan dipegang dari cek bentrok
+// sampai jadwal terakhir tersimpan, lalu dilepas
+func TestGTFSImportLock(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	kendaraan := seedImportKendaraan(t, s)
+	key := "jadwal:kendaraan:" + kendaraan.ID.Hex()
+
+	checking := &lockCheckingJadwalStore{JadwalStore: s.store.Jadwal, lock: s.store.Lock, key: key}
+	s.store.Jadwal = checking
+	result := importGTFS(t, s, importFeed(), repository.GTFSImportOptions{Hari: 3})
+	if len(result.Issues) > 0 || result.Jadwal.Dibuat != 6 {
+		t.Fatalf("impor gagal: %+v", result)
+	}
+	if checking.checks != 7 || checking.unlocked != 0 {
+		t.Fatalf("kunci tidak dipegang pada %d dari %d pengecekan", checking.unlocked, checking.checks)
+	}
+	if err := s.store.Lock.Acquire(ctx, key, "request-lain", time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("kunci tidak dilepas setelah impor: %v", err)
+	}
+}
+
+// uploadGTFS mengirim zip feed ke /api/gtfs/import sebagai multipart
+func (s *testServer) uploadGTFS(t *testing.T, token, query string, data []byte) *http.Response {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", "gtfs.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/gtfs/import?"+query, &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := s.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// TestGTFSImportJarakHalte memastikan halte terakhir memakai jarak 0,1 km
+// dari feed, bukan jarak rute yang dibulatkan ke kilometer
+func TestGTFSImportJarakHalte(t *testing.T) {
+	s := newTestServer(t)
+	seedImportKendaraan(t, s)
+
+	feed := importFeed()
+	for i := range feed.StopTimes {
+		switch feed.StopTimes[i].StopID {
+		case "TOL":
+			feed.StopTimes[i].ShapeDist = 30.2
+		case "CGK":
+			feed.StopTimes[i].ShapeDist = 30.4
+		}
+	}
+	result := importGTFS(t, s, feed, repository.GTFSImportOptions{Hari: 1})
+	if len(result.Issues) > 0 {
+		t.Fatalf("feed valid menghasilkan issue: %v", result.Issues)
+	}
+
+	rute, err := s.store.Rute.GetByKode(context.Background(), "R1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rute.JarakKM != 30 || rute.Halte[1].JarakKM != 30.2 || rute.Halte[2].JarakKM != 30.4 {
+		t.Fatalf("jarak rute %d km, halte %+v; seharusnya 30 km dengan halte 30,2 dan 30,4 km", rute.JarakKM, rute.Halte)
+	}
+}
+
+// TestGTFSImportShapeDistSatuan memastikan shape_dist_traveled dalam meter
+// dikenali, dan nilai yang tidak sebanding dengan jarak antar halte diganti
+// jarak garis lurus
+func TestGTFSImportShapeDistSatuan(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		tol, cgk   float64
+		halte      []float64
+		jarakKM    int
+		peringatan int
+	}{
+		{"kilometer", 12, 30, []float64{0, 12, 30}, 30, 0},
+		{"meter", 12000, 30000, []float64{0, 12, 30}, 30, 0},
+		// Garis lurus Terminal Kota - Halte Tol - Bandara sekitar 7,8 dan 19,4 km
+		{"tidak sebanding", 2, 3, []float64{0, 7.8, 19.4}, 19, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestServer(t)
+			seedImportKendaraan(t, s)
+
+			feed := importFeed()
+			for i := range feed.StopTimes {
+				switch feed.StopTimes[i].StopID {
+				case "TOL":
+					feed.StopTimes[i].ShapeDist = tc.tol
+				case "CGK":
+					feed.StopTimes[i].ShapeDist = tc.cgk
+				}
+			}
+			result := importGTFS(t, s, feed, repository.GTFSImportOptions{Hari: 1})
+			if len(result.Issues) > 0 || len(result.Warnings) != tc.peringatan {
+				t.Fatalf("issue %v, peringatan %v", result.Issues, result.Warnings)
+			}
+
+			rute, err := s.store.Rute.GetByKode(context.Background(), "R1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []float64{}
+			for _, stop := range rute.Halte {
+				got = append(got, stop.JarakKM)
+			}
+			if rute.JarakKM != tc.jarakKM || !reflect.DeepEqual(got, tc.halte) {
+				t.Fatalf("jarak rute %d km, halte %v; seharusnya %d km, halte %v", rute.JarakKM, got, tc.jarakKM, tc.halte)
+			}
+		})
+	}
+}
+
+// TestGTFSImportTrip memastikan impor ulang mencocokkan jadwal berdasarkan
+// trip walaupun waktu berangkatnya berubah, dan menghapus jadwal yang
+// trip-nya tidak ada lagi di feed kecuali sudah dipesan
+func TestGTFSImportTrip(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	seedImportKendaraan(t, s)
+
+	opts := repository.GTFSImportOptions{Hari: 3}
+	importGTFS(t, s, importFeed(), opts)
+	lama, err := s.store.Jadwal.FindDepartingBetween(ctx, importDari.AddDate(0, 0, -1), importDari.AddDate(0, 0, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]primitive.ObjectID{}
+	for _, j := range lama {
+		ids[j.GTFS.TripID+"/"+j.GTFS.Tanggal] = j.ID
+	}
+	dipesan := ids["T12/20300723"]
+	if _, err := s.store.Jadwal.ReserveSeats(ctx, dipesan, 1, 40); err != nil {
+		t.Fatal(err)
+	}
+
+	// Trip T07 dimundurkan satu jam dan trip T12 dihapus dari feed
+	feed := importFeed()
+	feed.Trips = feed.Trips[:1]
+	stopTimes := []gtfs.StopTime{}
+	for _, st := range feed.StopTimes {
+		if st.TripID == "T07" {
+			st.Arrival += 3600
+			st.Departure += 3600
+			stopTimes = append(stopTimes, st)
+		}
+	}
+	feed.StopTimes = stopTimes
+
+	result := importGTFS(t, s, feed, opts)
+	if len(result.Issues) > 0 || result.Jadwal.Diubah != 3 || result.Jadwal.Dihapus != 2 || len(result.Warnings) != 1 {
+		t.Fatalf("laporan impor ulang: %+v", result)
+	}
+
+	baru, err := s.store.Jadwal.FindDepartingBetween(ctx, importDari.AddDate(0, 0, -1), importDari.AddDate(0, 0, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(baru) != 4 {
+		t.Fatalf("%d jadwal tersisa, seharusnya 3 jadwal T07 dan 1 jadwal T12 yang dipesan", len(baru))
+	}
+	for _, j := range baru {
+		key := j.GTFS.TripID + "/" + j.GTFS.Tanggal
+		if ids[key] != j.ID {
+			t.Fatalf("jadwal %s dibuat ulang, seharusnya diperbarui", key)
+		}
+		if j.GTFS.TripID == "T07" && j.WaktuBerangkat.In(j.Tanggal.Location()).Hour() != 8 {
+			t.Fatalf("jadwal %s berangkat %v, seharusnya pukul 08:00", key, j.WaktuBerangkat)
+		}
+		if j.GTFS.TripID == "T12" && j.ID != dipesan {
+			t.Fatalf("jadwal %s tidak dihapus", key)
+		}
+	}
+}
+
+func TestGTFSImportDryRun(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	seedImportKendaraan(t, s)
+
+	result := importGTFS(t, s, importFeed(), repository.GTFSImportOptions{Hari: 3, DryRun: true})
+	if len(result.Issues) > 0 {
+		t.Fatalf("feed valid menghasilkan issue: %v", result.Issues)
+	}
+	if !result.DryRun || result.Halte.Dibuat != 3 || result.Rute.Dibuat != 1 || result.Jadwal.Dibuat != 6 {
+		t.Fatalf("laporan dry run: %+v", result)
+	}
+	if len(result.Perubahan) != 10 {
+		t.Fatalf("%d perubahan dilaporkan, seharusnya 3 halte, 1 rute dan 6 jadwal", len(result.Perubahan))
+	}
+	for _, c := range result.Perubahan {
+		if c.Aksi != "dibuat" {
+			t.Fatalf("perubahan %+v, seharusnya dibuat", c)
+		}
+	}
+
+	if _, err := s.store.Halte.GetByKode(ctx, "KOTA"); err != store.ErrNotFound {
+		t.Fatalf("dry run menyimpan halte: %v", err)
+	}
+	if _, err := s.store.Rute.GetByKode(ctx, "R1"); err != store.ErrNotFound {
+		t.Fatalf("dry run menyimpan rute: %v", err)
+	}
+}
+
+func TestPostGTFSImport(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	s.createUser(t, "operator", models.RoleOperator)
+	s.createUser(t, "penumpang", models.RoleUser)
+	token := s.login(t, "operator")
+	seedImportKendaraan(t, s)
+	data := feedZip(t, importFeed())
+	query := "dari=2030-07-22&hari=3"
+
+	t.Run("tanpa izin", func(t *testing.T) {
+		s.expect(t, s.uploadGTFS(t, s.login(t, "penumpang"), query, data), http.StatusForbidden, nil)
+	})
+	t.Run("bukan zip", func(t *testing.T) {
+		s.expect(t, s.uploadGTFS(t, token, query, []byte("bukan zip")), http.StatusBadRequest, nil)
+	})
+	t.Run("feed tidak valid", func(t *testing.T) {
+		feed := importFeed()
+		feed.Trips[1].RouteID = "R9"
+		var result repository.GTFSImportResult
+		s.expect(t, s.uploadGTFS(t, token, query, feedZip(t, feed)), http.StatusUnprocessableEntity, &result)
+		if len(result.Issues) == 0 {
+			t.Fatal("respons 422 tanpa issue")
+		}
+		if _, err := s.store.Halte.GetByKode(ctx, "KOTA"); err != store.ErrNotFound {
+			t.Fatalf("feed tidak valid tetap menyimpan halte: %v", err)
+		}
+	})
+
+	var result repository.GTFSImportResult
+	s.expect(t, s.uploadGTFS(t, token, query, data), http.StatusOK, &result)
+	if result.DryRun || result.Halte.Dibuat != 3 || result.Rute.Dibuat != 1 || result.Jadwal.Dibuat != 6 {
+		t.Fatalf("laporan impor: %+v", result)
+	}
+
+	rute, err := s.store.Rute.GetByKode(ctx, "R1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rute.Halte) != 3 || rute.JarakKM != 30 || rute.Asal != "Jakarta" || rute.Tujuan != "Tangerang" {
+		t.Fatalf("rute hasil impor: %+v", rute)
+	}
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	jadwals, err := s.store.Jadwal.FindByRuteTanggal(ctx, []store.RuteTanggal{{RuteID: rute.ID, Tanggal: time.Date(2030, 7, 23, 0, 0, 0, 0, loc)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jadwals) != 2 || jadwals[0].EstimasiTiba.Sub(jadwals[0].WaktuBerangkat) != 90*time.Minute {
+		t.Fatalf("jadwal 2030-07-23: %+v", jadwals)
+	}
+
+	t.Run("impor ulang tanpa perubahan", func(t *testing.T) {
+		var again repository.GTFSImportResult
+		s.expect(t, s.uploadGTFS(t, token, query, data), http.StatusOK, &again)
+		if len(again.Perubahan) != 0 || again.Halte.Tetap != 3 || again.Rute.Tetap != 1 || again.Jadwal.Tetap != 6 {
+			t.Fatalf("impor ulang feed yang sama: %+v", again)
+		}
+	})
+
+	t.Run("impor ulang dengan perubahan", func(t *testing.T) {
+		feed := importFeed()
+		feed.Stops[0].Name = "Terminal Kota Baru"
+		var again repository.GTFSImportResult
+		s.expect(t, s.uploadGTFS(t, token, query, feedZip(t, feed)), http.StatusOK, &again)
+		want := []repository.GTFSImportChange{{Jenis: "halte", Kode: "KOTA", Aksi: "diubah"}}
+		if !reflect.DeepEqual(again.Perubahan, want) {
+			t.Fatalf("perubahan %+v, seharusnya %+v", again.Perubahan, want)
+		}
+		if halte, _ := s.store.Halte.GetByKode(ctx, "KOTA"); halte.Nama != "Terminal Kota Baru" {
+			t.Fatalf("nama halte tidak diubah: %+v", halte)
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"transport-app/geo"
+	"transport-app/models"
+	"transport-app/query"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var halteSchema = query.Schema{
+	"kode": {BSON: "kode", Kind: query.String},
+	"nama": {BSON: "nama", Kind: query.String},
+	"kota": {BSON: "kota", Kind: query.String},
+}
+
+// HalteRequest berisi data halte. Koordinat dalam derajat desimal WGS 84.
+type HalteRequest struct {
+	Kode string  `json:"kode"`
+	Nama string  `json:"nama"`
+	Kota string  `json:"kota"`
+	Lat  float64 `json:"lat"`
+	Lng  float64 `json:"lng"`
+}
+
+// halteFromRequest memvalidasi input dan mengembalikan pesan error, atau
+// string kosong jika valid
+func halteFromRequest(input HalteRequest) (models.Halte, string) {
+	halte := models.Halte{
+		Kode:   strings.TrimSpace(input.Kode),
+		Nama:   strings.TrimSpace(input.Nama),
+		Kota:   strings.TrimSpace(input.Kota),
+		Lokasi: models.NewGeoPoint(input.Lat, input.Lng),
+	}
+	if halte.Kode == "" || halte.Nama == "" || halte.Kota == "" {
+		return halte, "Kode, nama dan kota halte wajib diisi"
+	}
+	if !geo.ValidLatLng(input.Lat, input.Lng) {
+		return halte, "Koordinat tidak valid"
+	}
+	if input.Lat == 0 && input.Lng == 0 {
+		return halte, "Koordinat halte wajib diisi"
+	}
+	return halte, ""
+}
+
+// GetAllHalte godoc
+// @Summary Get all halte
+// @Description Mengambil data halte dengan filter, sort dan pagination
+// @Tags Halte
+// @Produce json
+// @Param q query string false "Cari pada kode atau nama"
+// @Param page query int false "Nomor halaman (default 1)"
+// @Param limit query int false "Jumlah data per halaman (default 20, maks 100)"
+// @Param cursor query string false "Cursor dari next_cursor halaman sebelumnya"
+// @Param sort query string false "Urutan, mis. kota,nama"
+// @Param kota query string false "Filter kota"
+// @Success 200 {object} query.Page[models.Halte] "Daftar halte"
+// @Failure 400 {object} models.ErrorResponse "Query tidak valid"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/haltes [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+func (h *Handler) GetAllHalte(c *fiber.Ctx) error {
+	q, err := query.Parse(c, halteSchema, []query.Sort{{Field: "kode"}})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	page, err := h.Store.Halte.List(ctx, q)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(page.WithNext(c))
+}
+
+// GetHalteByID godoc
+// @Summary Get a halte by ID
+// @Description Mengambil data halte berdasarkan ID
+// @Tags Halte
+// @Produce json
+// @Param id path string true "Halte ID"
+// @Success 200 {object} models.Halte "Data halte"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Halte not found"
+// @Router /api/haltes/{id} [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+func (h *Handler) GetHalteByID(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	halte, err := h.Store.Halte.Get(context.TODO(), id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Halte not found"})
+	}
+	return c.JSON(halte)
+}
+
+// CreateHalte godoc
+// @Summary Create a halte
+// @Description Membuat halte baru. Kode halte harus unik
+// @Tags Halte
+// @Accept json
+// @Produce json
+// @Param halte body HalteRequest true "Data halte"
+// @Success 201 {object} models.Halte "Halte dibuat"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 409 {object} models.ErrorResponse "Kode halte sudah dipakai"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/haltes [post]
+// @Security BearerAuth
+func (h *Handler) CreateHalte(c *fiber.Ctx) error {
+	var input HalteRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	halte, msg := halteFromRequest(input)
+	if msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := h.Store.Halte.GetByKode(ctx, halte.Kode); err == nil {
+		return c.Status(409).JSON(fiber.Map{"error": "Kode halte sudah dipakai"})
+	} else if err != store.ErrNotFound {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	halte.ID = primitive.NewObjectID()
+	if err := h.Store.Halte.Create(ctx, &halte); err != nil {
+		fmt.Println("❌ Error saat menyimpan halte:", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(halte)
+}
+
+// UpdateHalte godoc
+// @Summary Update a halte
+// @Description Mengubah data halte
+// @Tags Halte
+// @Accept json
+// @Produce json
+// @Param id path string true "Halte ID"
+// @Param halte body HalteRequest true "Data halte"
+// @Success 200 {object} models.Halte "Halte diupdate"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Halte not found"
+// @Failure 409 {object} models.ErrorResponse "Kode halte sudah dipakai"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/haltes/{id} [put]
+// @Security BearerAuth
+func (h *Handler) UpdateHalte(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var input HalteRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	halte, msg := halteFromRequest(input)
+	if msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+	halte.ID = id
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if other, err := h.Store.Halte.GetByKode(ctx, halte.Kode); err == nil && other.ID != id {
+		return c.Status(409).JSON(fiber.Map{"error": "Kode halte sudah dipakai"})
+	} else if err != nil && err != store.ErrNotFound {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	err = h.Store.Halte.Update(ctx, halte)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Halte not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(halte)
+}
+
+// DeleteHalte godoc
+// @Summary Delete a halte
+// @Description Menghapus halte. Halte yang masih dipakai rute tidak bisa dihapus
+// @Tags Halte
+// @Produce json
+// @Param id path string true "Halte ID"
+// @Success 200 {object} models.SuccessResponse "Halte dihapus"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Halte not found"
+// @Failure 409 {object} models.ErrorResponse "Halte masih dipakai rute"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/haltes/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteHalte(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	used, err := h.Store.Rute.UsesHalte(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if used {
+		return c.Status(409).JSON(fiber.Map{"error": "Halte masih dipakai rute"})
+	}
+
+	err = h.Store.Halte.Delete(ctx, id)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Halte not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Halte dihapus"})
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"transport-app/jwtkeys"
+	"transport-app/mailer"
+	"transport-app/oidc"
+	"transport-app/store"
+)
+
+// Handler menyimpan dependensi yang dipakai semua handler HTTP. Store
+// diberikan dari luar sehingga handler bisa dijalankan dengan MongoDB
+// maupun store in-memory tanpa database.
+type Handler struct {
+	Store  *store.Stores
+	Mailer mailer.Mailer
+	Keys   *jwtkeys.Manager
+	// OIDC kosong jika login lewat identity provider tidak dikonfigurasi
+	OIDC *oidc.Provider
+
+	background sync.WaitGroup
+}
+
+func NewHandler(s *store.Stores, m mailer.Mailer, k *jwtkeys.Manager) *Handler {
+	return &Handler{Store: s, Mailer: m, Keys: k}
+}
+
+// backgroundTimeout membatasi lama satu pekerjaan latar belakang
+const backgroundTimeout = 30 * time.Second
+
+// goBackground menjalankan fn setelah response dikirim, dengan context
+// sendiri karena context request sudah selesai. Dipakai untuk pekerjaan
+// yang lamanya tidak boleh terlihat dari response, seperti mengirim email
+// hanya untuk akun yang terdaftar. Error dicatat dengan label what.
+func (h *Handler) goBackground(what string, fn func(ctx context.Context) error) {
+	h.background.Add(1)
+	go func() {
+		defer h.background.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+		defer cancel()
+		if err := fn(ctx); err != nil {
+			fmt.Println("❌ Gagal "+what+":", err)
+		}
+	}()
+}
+
+// Wait menunggu semua pekerjaan latar belakang selesai
+func (h *Handler) Wait() {
+	h.background.Wait()
+}
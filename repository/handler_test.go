@@ -0,0 +1,230 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+
+	"transport-app/jwtkeys"
+	"transport-app/mailer"
+	"transport-app/models"
+	"transport-app/repository"
+	"transport-app/routes"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "rahasia123"
+
+// testMailer menyimpan email yang dikirim agar test bisa membaca tokennya
+type testMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+	// wait menunggu email yang dikirim di latar belakang
+	wait func()
+}
+
+func (m *testMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *testMailer) count() int {
+	m.wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sent)
+}
+
+var tokenLinkRegex = regexp.MustCompile(`token=(\S+)`)
+
+// lastToken mengambil token dari link pada email terakhir. Test yang
+// memakainya harus mengisi APP_URL agar email berisi link.
+func (m *testMailer) lastToken(t *testing.T) string {
+	t.Helper()
+	m.wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.sent) == 0 {
+		t.Fatal("belum ada email yang dikirim")
+	}
+	match := tokenLinkRegex.FindStringSubmatch(m.sent[len(m.sent)-1].Body)
+	if match == nil {
+		t.Fatalf("email tidak berisi token: %s", m.sent[len(m.sent)-1].Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// testServer menjalankan semua route di atas store memori
+type testServer struct {
+	app    *fiber.App
+	h      *repository.Handler
+	store  *store.Stores
+	mailer *testMailer
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	s := store.NewMemoryStores()
+	keys := jwtkeys.New(s.SigningKey, jwtkeys.Config{Algorithm: jwtkeys.HS256, Secret: []byte("rahasia-test")})
+	m := &testMailer{}
+	h := repository.NewHandler(s, m, keys)
+	m.wait = h.Wait
+	if err := h.SeedRoles(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	routes.SetupRoutes(app, h)
+	return &testServer{app: app, h: h, store: s, mailer: m}
+}
+
+// createUser menyimpan user terverifikasi langsung ke store. Hash memakai
+// cost minimum agar test tetap cepat.
+func (s *testServer) createUser(t *testing.T, username, role string) models.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{
+		Username:      username,
+		Email:         username + "@example.com",
+		Password:      string(hash),
+		Role:          role,
+		EmailVerified: true,
+	}
+	if err := s.store.User.Create(context.Background(), &user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// login masuk lewat /api/login dan mengembalikan access token
+func (s *testServer) login(t *testing.T, username string) string {
+	t.Helper()
+	res := s.request(t, http.MethodPost, "/api/login", "", fiber.Map{"username": username, "password": testPassword})
+	var body repository.LoginResponse
+	s.expect(t, res, http.StatusOK, &body)
+	return body.Token
+}
+
+// request mengirim body sebagai JSON, kecuali body berupa string yang
+// dikirim apa adanya
+func (s *testServer) request(t *testing.T, method, path, token string, body interface{}) *http.Response {
+	t.Helper()
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := s.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// expect memastikan status response dan men-decode body ke out jika diisi
+func (s *testServer) expect(t *testing.T, res *http.Response, status int, out interface{}) {
+	t.Helper()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != status {
+		t.Fatalf("%s %s: status %d, seharusnya %d: %s", res.Request.Method, res.Request.URL.Path, res.StatusCode, status, data)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: body tidak valid: %v: %s", res.Request.Method, res.Request.URL.Path, err, data)
+		}
+	}
+}
+
+func TestAuthFailures(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "operator", models.RoleOperator)
+	s.createUser(t, "penumpang", models.RoleUser)
+	rute := fiber.Map{"kode_rute": "R1", "nama_rute": "Kota", "asal": "A", "tujuan": "B", "jarak_km": 10}
+
+	t.Run("password salah", func(t *testing.T) {
+		res := s.request(t, http.MethodPost, "/api/login", "", fiber.Map{"username": "operator", "password": "salah"})
+		s.expect(t, res, http.StatusUnauthorized, nil)
+	})
+
+	t.Run("user tidak dikenal", func(t *testing.T) {
+		res := s.request(t, http.MethodPost, "/api/login", "", fiber.Map{"username": "siapa", "password": testPassword})
+		s.expect(t, res, http.StatusUnauthorized, nil)
+	})
+
+	t.Run("body login bukan JSON", func(t *testing.T) {
+		res := s.request(t, http.MethodPost, "/api/login", "", "{")
+		s.expect(t, res, http.StatusBadRequest, nil)
+	})
+
+	t.Run("tanpa token", func(t *testing.T) {
+		s.expect(t, s.request(t, http.MethodGet, "/api/rutes", "", nil), http.StatusBadRequest, nil)
+		s.expect(t, s.request(t, http.MethodPost, "/api/rutes", "", rute), http.StatusBadRequest, nil)
+	})
+
+	t.Run("token tidak valid", func(t *testing.T) {
+		s.expect(t, s.request(t, http.MethodGet, "/api/me", "bukan.token.jwt", nil), http.StatusUnauthorized, nil)
+	})
+
+	t.Run("izin kurang", func(t *testing.T) {
+		token := s.login(t, "penumpang")
+		s.expect(t, s.request(t, http.MethodGet, "/api/rutes", token, nil), http.StatusOK, nil)
+		s.expect(t, s.request(t, http.MethodPost, "/api/rutes", token, rute), http.StatusForbidden, nil)
+		s.expect(t, s.request(t, http.MethodPost, "/api/kendaraans", token, fiber.Map{}), http.StatusForbidden, nil)
+		s.expect(t, s.request(t, http.MethodGet, "/api/admin/users", token, nil), http.StatusForbidden, nil)
+	})
+
+	t.Run("sesi dicabut setelah logout", func(t *testing.T) {
+		token := s.login(t, "operator")
+		s.expect(t, s.request(t, http.MethodGet, "/api/me", token, nil), http.StatusOK, nil)
+		s.expect(t, s.request(t, http.MethodPost, "/api/logout", token, nil), http.StatusOK, nil)
+		s.expect(t, s.request(t, http.MethodGet, "/api/me", token, nil), http.StatusUnauthorized, nil)
+		s.expect(t, s.request(t, http.MethodPost, "/api/rutes", token, rute), http.StatusUnauthorized, nil)
+	})
+
+	t.Run("akun dinonaktifkan", func(t *testing.T) {
+		user := s.createUser(t, "nonaktif", models.RoleUser)
+		if err := s.store.User.SetDisabled(context.Background(), user.ID, true); err != nil {
+			t.Fatal(err)
+		}
+		res := s.request(t, http.MethodPost, "/api/login", "", fiber.Map{"username": "nonaktif", "password": testPassword})
+		s.expect(t, res, http.StatusForbidden, nil)
+	})
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"transport-app/store"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// jadwalLockTTL adalah batas lama kunci dipegang jika pemiliknya mati
+	// sebelum sempat melepasnya
+	jadwalLockTTL = time.Minute
+	// jadwalLockJeda adalah jeda antar percobaan mengambil kunci
+	jadwalLockJeda = 50 * time.Millisecond
+	// jadwalLockTunggu adalah batas menunggu kunci yang dipegang request lain
+	jadwalLockTunggu = 5 * time.Second
+)
+
+// errJadwalLocked dikembalikan jika kunci kendaraan atau pengemudi tidak
+// didapat dalam jadwalLockTunggu
+var errJadwalLocked = errors.New("kendaraan atau pengemudi sedang dijadwalkan oleh request lain, coba lagi")
+
+func kendaraanLockKey(id primitive.ObjectID) string {
+	return "jadwal:kendaraan:" + id.Hex()
+}
+
+// pengemudiLockKey mengembalikan string kosong untuk jadwal tanpa pengemudi
+func pengemudiLockKey(pengemudi string) string {
+	if pengemudi == "" {
+		return ""
+	}
+	return "jadwal:pengemudi:" + pengemudi
+}
+
+// lockJadwal mengunci kendaraan dan pengemudi selama pengecekan bentrok dan
+// penyimpanan jadwal, sehingga dua request yang bersamaan tidak bisa sama-sama
+// lolos pengecekan. Kunci diambil berurutan menurut nama agar dua request
+// tidak saling menunggu. Fungsi yang dikembalikan melepas semua kunci.
+func (h *Handler) lockJadwal(ctx context.Context, ttl time.Duration, keys ...string) (func(), error) {
+	owner, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	unique := map[string]bool{}
+	sorted := []string{}
+	for _, key := range keys {
+		if key != "" && !unique[key] {
+			unique[key] = true
+			sorted = append(sorted, key)
+		}
+	}
+	sort.Strings(sorted)
+
+	held := []string{}
+	release := func() {
+		// Context request bisa sudah selesai, kunci tetap harus dilepas
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		for _, key := range held {
+			if err := h.Store.Lock.Release(ctx, key, owner); err != nil {
+				fmt.Println("⚠️ Gagal melepas kunci", key+":", err)
+			}
+		}
+	}
+
+	deadline := time.Now().Add(jadwalLockTunggu)
+	for _, key := range sorted {
+		for {
+			err := h.Store.Lock.Acquire(ctx, key, owner, time.Now().Add(ttl))
+			if err == nil {
+				held = append(held, key)
+				break
+			}
+			if err != store.ErrLocked {
+				release()
+				return nil, err
+			}
+			if time.Now().After(deadline) {
+				release()
+				return nil, errJadwalLocked
+			}
+			select {
+			case <-ctx.Done():
+				release()
+				return nil, ctx.Err()
+			case <-time.After(jadwalLockJeda):
+			}
+		}
+	}
+	return release, nil
+}
+
+// findJadwalConflicts mencari jadwal lain yang memakai kendaraan (atau
+// pengemudi, jika diisi) yang sama pada rentang waktu yang beririsan.
+// excludeID dipakai saat update agar jadwal itu sendiri tidak dihitung.
+// Pemanggil yang menyimpan jadwal harus memegang lockJadwal.
+func (h *Handler) findJadwalConflicts(ctx context.Context, kendaraanID primitive.ObjectID, pengemudi string, start, end time.Time, excludeID primitive.ObjectID) ([]string, error) {
+	ids, err := h.Store.Jadwal.FindConflicts(ctx, kendaraanID, pengemudi, start, end, excludeID)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []string{}
+	for _, id := range ids {
+		conflicts = append(conflicts, id.Hex())
+	}
+	return conflicts, nil
+}
@@ -4,83 +4,69 @@ import (
 	"context"
 	"fmt"
 	"time"
-	"transport-app/config"
 	"transport-app/models"
+	"transport-app/query"
+	"transport-app/store"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func getJadwalCollection() *mongo.Collection {
-	return config.GetCollection("jadwal")
-}
-
-// JadwalWithRute is a struct to combine Jadwal with its related Rute
-type JadwalWithRute struct {
-	ID             string      `json:"id" bson:"_id"`
-	Tanggal        string      `json:"tanggal"`
-	WaktuBerangkat string      `json:"waktu_berangkat"`
-	EstimasiTiba   string      `json:"estimasi_tiba"`
-	RuteID         string      `json:"rute_id"`
-	Rute           models.Rute `json:"rute"`
+// Filter tanggal tanpa jam diinterpretasikan dalam WIB
+var jadwalSchema = query.Schema{
+	"tanggal":         {BSON: "tanggal", Kind: query.Date, Location: loadZonaWaktu(defaultZonaWaktu)},
+	"waktu_berangkat": {BSON: "waktu_berangkat", Kind: query.Date, Location: loadZonaWaktu(defaultZonaWaktu)},
+	"estimasi_tiba":   {BSON: "estimasi_tiba", Kind: query.Date, Location: loadZonaWaktu(defaultZonaWaktu)},
+	"rute_id":         {BSON: "rute_id", Kind: query.ObjectID},
+	"kendaraan_id":    {BSON: "kendaraan_id", Kind: query.ObjectID},
+	"template_id":     {BSON: "template_id", Kind: query.ObjectID},
+	"pengemudi":       {BSON: "pengemudi", Kind: query.String},
+	"kursi_terisi":    {BSON: "kursi_terisi", Kind: query.Int},
 }
 
 // GetAllJadwal godoc
 // @Summary Get all jadwal
-// @Description Mengambil semua data jadwal beserta detail rutenya
+// @Description Mengambil data jadwal beserta detail rute dan kendaraannya dengan filter, sort dan pagination. Filter: field=nilai, field[op]=nilai (eq, ne, gt, gte, lt, lte, in) atau tanggal>=2025-01-01. Sort: sort=field,-field
 // @Tags Jadwal
 // @Accept json
 // @Produce json
-// @Success 200 {array} repository.JadwalWithRute "Daftar semua jadwal"
+// @Param page query int false "Nomor halaman (default 1)"
+// @Param limit query int false "Jumlah data per halaman (default 20, maks 100)"
+// @Param cursor query string false "Cursor dari next_cursor halaman sebelumnya"
+// @Param sort query string false "Urutan, mis. -waktu_berangkat"
+// @Param rute_id query string false "Filter rute"
+// @Param kendaraan_id query string false "Filter kendaraan"
+// @Success 200 {object} query.Page[models.JadwalWithRute] "Daftar jadwal"
+// @Failure 400 {object} models.ErrorResponse "Query tidak valid"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/jadwals [get]
 // @Security BearerAuth
-func GetAllJadwal(c *fiber.Ctx) error {
-	collection := getJadwalCollection()
-	ruteCollection := config.GetCollection("rutes")
+// @Security APIKeyAuth
+func (h *Handler) GetAllJadwal(c *fiber.Ctx) error {
+	q, err := query.Parse(c, jadwalSchema, []query.Sort{{Field: "waktu_berangkat"}})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Ambil semua jadwal
-	cursor, err := collection.Find(ctx, bson.M{})
+	page, err := h.Store.Jadwal.List(ctx, q)
 	if err != nil {
 		fmt.Println("❌ Error saat mengambil jadwal:", err)
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data jadwal"})
 	}
 
-	var jadwals []models.Jadwal
-	if err := cursor.All(ctx, &jadwals); err != nil {
-		fmt.Println("❌ Gagal parsing jadwal:", err)
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal parsing data jadwal"})
+	for i := range page.Data {
+		j := &page.Data[i]
+		loc := loadZonaWaktu(j.Rute.ZonaWaktu)
+		j.Tanggal = j.Tanggal.In(loc)
+		j.WaktuBerangkat = j.WaktuBerangkat.In(loc)
+		j.EstimasiTiba = j.EstimasiTiba.In(loc)
 	}
 
-	var result []JadwalWithRute
-
-	for _, j := range jadwals {
-		fmt.Println("🔍 Memproses jadwal dengan ID:", j.ID.Hex(), "dan RuteID:", j.RuteID)
-
-		var rute models.Rute
-		err = ruteCollection.FindOne(ctx, bson.M{"_id": j.RuteID}).Decode(&rute)
-		if err != nil {
-			fmt.Println("⚠️ Error saat mencari rute:", err)
-			rute = models.Rute{}
-		}
-
-		result = append(result, JadwalWithRute{
-			ID:             j.ID.Hex(),
-			Tanggal:        j.Tanggal,
-			WaktuBerangkat: j.WaktuBerangkat,
-			EstimasiTiba:   j.EstimasiTiba,
-			RuteID:         j.RuteID.Hex(),
-			Rute:           rute,
-		})
-	}
-
-	return c.JSON(result)
+	return c.JSON(page.WithNext(c))
 }
 
 // GetJadwalByID godoc
@@ -96,7 +82,8 @@ func GetAllJadwal(c *fiber.Ctx) error {
 // @Failure 404 {object} models.ErrorResponse "Jadwal not found"
 // @Router /api/jadwals/{id} [get]
 // @Security BearerAuth
-func GetJadwalByID(c *fiber.Ctx) error {
+// @Security APIKeyAuth
+func (h *Handler) GetJadwalByID(c *fiber.Ctx) error {
 	id := c.Params("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -104,13 +91,17 @@ func GetJadwalByID(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	var jadwal models.Jadwal
-	err = getJadwalCollection().FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&jadwal)
+	jadwal, err := h.Store.Jadwal.Get(context.TODO(), objID)
 	if err != nil {
 		fmt.Println("❌ Jadwal not found with ID:", id)
 		return c.Status(404).JSON(fiber.Map{"error": "Jadwal not found"})
 	}
 
+	// Waktu ditampilkan pada zona waktu rute, UTC tetap dipakai jika rute tidak ada
+	if rute, err := h.Store.Rute.Get(context.TODO(), jadwal.RuteID); err == nil {
+		localizeJadwal(&jadwal, loadZonaWaktu(rute.ZonaWaktu))
+	}
+
 	return c.JSON(jadwal)
 }
 
@@ -126,16 +117,18 @@ func GetJadwalByID(c *fiber.Ctx) error {
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
 // @Failure 404 {object} models.ErrorResponse "Rute or Kendaraan not found"
+// @Failure 409 {object} models.ErrorResponse "Kendaraan atau pengemudi bentrok dengan jadwal lain"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/jadwals [post]
 // @Security BearerAuth
-func CreateJadwal(c *fiber.Ctx) error {
+func (h *Handler) CreateJadwal(c *fiber.Ctx) error {
 	var input struct {
 		Tanggal        string `json:"tanggal"`
 		WaktuBerangkat string `json:"waktu_berangkat"`
 		EstimasiTiba   string `json:"estimasi_tiba"`
 		KodeRute       string `json:"kode_rute"`    // Input kode_rute dari frontend
 		NomorPolisi    string `json:"nomor_polisi"` // Input nomor_polisi dari frontend
+		Pengemudi      string `json:"pengemudi"`    // Opsional, ikut dicek bentrok jadwalnya
 	}
 
 	if err := c.BodyParser(&input); err != nil {
@@ -152,32 +145,69 @@ func CreateJadwal(c *fiber.Ctx) error {
 	}
 
 	// Cari rute_id berdasarkan kode_rute
-	var rute models.Rute
-	err := config.GetCollection("rutes").FindOne(context.TODO(), bson.M{"kode_rute": input.KodeRute}).Decode(&rute)
+	rute, err := h.Store.Rute.GetByKode(context.TODO(), input.KodeRute)
 	if err != nil {
 		fmt.Println("❌ Rute not found with kode_rute:", input.KodeRute)
 		return c.Status(404).JSON(fiber.Map{"error": "Rute not found"})
 	}
 
 	// Cari kendaraan_id berdasarkan nomor_polisi
-	var kendaraan models.Kendaraan
-	err = config.GetCollection("kendaraan").FindOne(context.TODO(), bson.M{"nomor_polisi": input.NomorPolisi}).Decode(&kendaraan)
+	kendaraan, err := h.Store.Kendaraan.GetByNomorPolisi(context.TODO(), input.NomorPolisi)
 	if err != nil {
 		fmt.Println("❌ Kendaraan not found with nomor_polisi:", input.NomorPolisi)
 		return c.Status(404).JSON(fiber.Map{"error": "Kendaraan not found"})
 	}
 
+	// Waktu diinterpretasikan pada zona waktu rute
+	loc := loadZonaWaktu(rute.ZonaWaktu)
+	tanggal, start, end, err := parseJadwalWaktu(input.Tanggal, input.WaktuBerangkat, input.EstimasiTiba, loc)
+	if err == errTibaSebelumBerangkat {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Format tanggal atau waktu tidak valid"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Kendaraan dan pengemudi dikunci sampai jadwal tersimpan agar request
+	// lain tidak lolos pengecekan bentrok pada saat yang sama
+	unlock, err := h.lockJadwal(ctx, jadwalLockTTL, kendaraanLockKey(kendaraan.ID), pengemudiLockKey(input.Pengemudi))
+	if err == errJadwalLocked {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		fmt.Println("❌ Error saat mengunci jadwal:", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer unlock()
+
+	// Kendaraan atau pengemudi tidak boleh dipakai di dua jadwal yang waktunya beririsan
+	conflicts, err := h.findJadwalConflicts(ctx, kendaraan.ID, input.Pengemudi, start, end, primitive.NilObjectID)
+	if err != nil {
+		fmt.Println("❌ Error saat mengecek bentrok jadwal:", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(conflicts) > 0 {
+		return c.Status(409).JSON(fiber.Map{
+			"error":     "Kendaraan atau pengemudi sudah terjadwal pada waktu tersebut",
+			"conflicts": conflicts,
+		})
+	}
+
 	// Buat jadwal baru
 	jadwal := models.Jadwal{
 		ID:             primitive.NewObjectID(),
-		Tanggal:        input.Tanggal,
-		WaktuBerangkat: input.WaktuBerangkat,
-		EstimasiTiba:   input.EstimasiTiba,
+		Tanggal:        tanggal,
+		WaktuBerangkat: start,
+		EstimasiTiba:   end,
 		RuteID:         rute.ID,      // Gunakan rute_id yang ditemukan
 		KendaraanID:    kendaraan.ID, // Gunakan kendaraan_id yang ditemukan
+		Pengemudi:      input.Pengemudi,
 	}
 
-	_, err = getJadwalCollection().InsertOne(context.TODO(), jadwal)
+	err = h.Store.Jadwal.Create(ctx, &jadwal)
 	if err != nil {
 		fmt.Println("❌ Error saat menyimpan jadwal:", err)
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
@@ -199,11 +229,12 @@ func CreateJadwal(c *fiber.Ctx) error {
 // @Failure 400 {object} models.ErrorResponse "Invalid ID atau Bad Request"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
-// @Failure 404 {object} models.ErrorResponse "Rute not found"
+// @Failure 404 {object} models.ErrorResponse "Jadwal or Rute not found"
+// @Failure 409 {object} models.ErrorResponse "Kendaraan atau pengemudi bentrok dengan jadwal lain"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/jadwals/{id} [put]
 // @Security BearerAuth
-func UpdateJadwal(c *fiber.Ctx) error {
+func (h *Handler) UpdateJadwal(c *fiber.Ctx) error {
 	id := c.Params("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -215,39 +246,73 @@ func UpdateJadwal(c *fiber.Ctx) error {
 		WaktuBerangkat string `json:"waktu_berangkat"`
 		EstimasiTiba   string `json:"estimasi_tiba"`
 		KodeRute       string `json:"kode_rute"`
+		Pengemudi      string `json:"pengemudi"`
 	}
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	existing, err := h.Store.Jadwal.Get(context.TODO(), objID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Jadwal not found"})
+	}
+
 	// Cari rute berdasarkan kode_rute
-	var rute models.Rute
-	err = getRuteCollection().FindOne(context.TODO(), bson.M{"kode_rute": input.KodeRute}).Decode(&rute)
+	rute, err := h.Store.Rute.GetByKode(context.TODO(), input.KodeRute)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Rute not found"})
 	}
 
+	loc := loadZonaWaktu(rute.ZonaWaktu)
+	tanggal, start, end, err := parseJadwalWaktu(input.Tanggal, input.WaktuBerangkat, input.EstimasiTiba, loc)
+	if err == errTibaSebelumBerangkat {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Format tanggal atau waktu tidak valid"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	unlock, err := h.lockJadwal(ctx, jadwalLockTTL, kendaraanLockKey(existing.KendaraanID), pengemudiLockKey(input.Pengemudi))
+	if err == errJadwalLocked {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer unlock()
+
+	conflicts, err := h.findJadwalConflicts(ctx, existing.KendaraanID, input.Pengemudi, start, end, objID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(conflicts) > 0 {
+		return c.Status(409).JSON(fiber.Map{
+			"error":     "Kendaraan atau pengemudi sudah terjadwal pada waktu tersebut",
+			"conflicts": conflicts,
+		})
+	}
+
 	// Update jadwal
-	update := bson.M{
-		"$set": bson.M{
-			"tanggal":         input.Tanggal,
-			"waktu_berangkat": input.WaktuBerangkat,
-			"estimasi_tiba":   input.EstimasiTiba,
-			"kode_rute":       input.KodeRute,
-			"rute_id":         rute.ID,
-		},
-	}
-	_, err = getJadwalCollection().UpdateByID(context.TODO(), objID, update)
+	err = h.Store.Jadwal.Update(ctx, objID, store.JadwalUpdate{
+		Tanggal:        tanggal,
+		WaktuBerangkat: start,
+		EstimasiTiba:   end,
+		RuteID:         rute.ID,
+		Pengemudi:      input.Pengemudi,
+	})
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	// Ambil ulang jadwal yang sudah diupdate
-	var jadwal models.Jadwal
-	err = getJadwalCollection().FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&jadwal)
+	jadwal, err := h.Store.Jadwal.Get(context.TODO(), objID)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	localizeJadwal(&jadwal, loc)
 
 	// Return jadwal with rute info using a response struct
 	response := struct {
@@ -263,7 +328,7 @@ func UpdateJadwal(c *fiber.Ctx) error {
 
 // DeleteJadwal godoc
 // @Summary Delete a jadwal
-// @Description Menghapus data jadwal berdasarkan ID (Admin Only)
+// @Description Menghapus data jadwal berdasarkan ID. Jadwal yang masih memiliki booking tidak bisa dihapus sebelum booking-nya dibatalkan (Admin Only)
 // @Tags Jadwal
 // @Accept json
 // @Produce json
@@ -272,10 +337,12 @@ func UpdateJadwal(c *fiber.Ctx) error {
 // @Failure 400 {object} models.ErrorResponse "Invalid ID"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} models.ErrorResponse "Jadwal not found"
+// @Failure 409 {object} models.ErrorResponse "Jadwal masih memiliki booking"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/jadwals/{id} [delete]
 // @Security BearerAuth
-func DeleteJadwal(c *fiber.Ctx) error {
+func (h *Handler) DeleteJadwal(c *fiber.Ctx) error {
 	id := c.Params("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -283,7 +350,14 @@ func DeleteJadwal(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	_, err = getJadwalCollection().DeleteOne(context.TODO(), bson.M{"_id": objID})
+	// Booking yang masih aktif akan kehilangan jadwalnya jika dihapus
+	err = h.Store.Jadwal.Delete(context.TODO(), objID)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Jadwal not found"})
+	}
+	if err == store.ErrBooked {
+		return c.Status(409).JSON(fiber.Map{"error": "Jadwal masih memiliki booking, batalkan booking terlebih dahulu"})
+	}
 	if err != nil {
 		fmt.Println("❌ Error saat menghapus jadwal:", err)
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
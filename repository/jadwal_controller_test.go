@@ -0,0 +1,217 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"transport-app/models"
+	"transport-app/query"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// seedJadwalData membuat rute R1 dan kendaraan B 1234 CD lewat API
+func seedJadwalData(t *testing.T, s *testServer, token string) (models.Rute, models.Kendaraan) {
+	t.Helper()
+	var rute models.Rute
+	s.expect(t, s.request(t, http.MethodPost, "/api/rutes", token,
+		fiber.Map{"kode_rute": "R1", "nama_rute": "Kota - Bandara", "asal": "Kota", "tujuan": "Bandara", "jarak_km": 25}),
+		http.StatusCreated, &rute)
+	var kendaraan models.Kendaraan
+	s.expect(t, s.request(t, http.MethodPost, "/api/kendaraans", token,
+		fiber.Map{"nomor_polisi": "B 1234 CD", "jenis": "Bus", "kapasitas": 2, "status": "aktif"}),
+		http.StatusCreated, &kendaraan)
+	return rute, kendaraan
+}
+
+func besok() string {
+	return time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+}
+
+func TestJadwalCRUD(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "operator", models.RoleOperator)
+	token := s.login(t, "operator")
+	rute, kendaraan := seedJadwalData(t, s, token)
+
+	input := fiber.Map{"tanggal": besok(), "waktu_berangkat": "08:00", "estimasi_tiba": "10:00", "kode_rute": "R1", "nomor_polisi": "B 1234 CD", "pengemudi": "Budi"}
+	var created models.Jadwal
+	s.expect(t, s.request(t, http.MethodPost, "/api/jadwals", token, input), http.StatusCreated, &created)
+	if created.RuteID != rute.ID || created.KendaraanID != kendaraan.ID {
+		t.Fatalf("jadwal tidak merujuk rute dan kendaraan: %+v", created)
+	}
+	if got := created.EstimasiTiba.Sub(created.WaktuBerangkat); got != 2*time.Hour {
+		t.Fatalf("durasi jadwal %s, seharusnya 2 jam", got)
+	}
+	path := "/api/jadwals/" + created.ID.Hex()
+
+	var got models.Jadwal
+	s.expect(t, s.request(t, http.MethodGet, path, token, nil), http.StatusOK, &got)
+	if got.ID != created.ID || got.Pengemudi != "Budi" {
+		t.Fatalf("jadwal tidak sesuai: %+v", got)
+	}
+
+	var page query.Page[models.JadwalWithRute]
+	s.expect(t, s.request(t, http.MethodGet, "/api/jadwals", token, nil), http.StatusOK, &page)
+	if page.Total != 1 || len(page.Data) != 1 || page.Data[0].Rute.KodeRute != "R1" || page.Data[0].Kendaraan.NomorPolisi != "B 1234 CD" {
+		t.Fatalf("daftar jadwal tidak berisi rute dan kendaraan: %+v", page)
+	}
+
+	update := fiber.Map{"tanggal": besok(), "waktu_berangkat": "09:00", "estimasi_tiba": "11:30", "kode_rute": "R1", "pengemudi": "Siti"}
+	s.expect(t, s.request(t, http.MethodPut, path, token, update), http.StatusOK, nil)
+	s.expect(t, s.request(t, http.MethodGet, path, token, nil), http.StatusOK, &got)
+	if got.Pengemudi != "Siti" || got.EstimasiTiba.Sub(got.WaktuBerangkat) != 150*time.Minute {
+		t.Fatalf("jadwal tidak diperbarui: %+v", got)
+	}
+
+	s.expect(t, s.request(t, http.MethodDelete, path, token, nil), http.StatusOK, nil)
+	s.expect(t, s.request(t, http.MethodGet, path, token, nil), http.StatusNotFound, nil)
+}
+
+func TestJadwalErrors(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "operator", models.RoleOperator)
+	token := s.login(t, "operator")
+	seedJadwalData(t, s, token)
+
+	input := func(berangkat, tiba, kodeRute, nomorPolisi, pengemudi string) fiber.Map {
+		return fiber.Map{"tanggal": besok(), "waktu_berangkat": berangkat, "estimasi_tiba": tiba, "kode_rute": kodeRute, "nomor_polisi": nomorPolisi, "pengemudi": pengemudi}
+	}
+	s.expect(t, s.request(t, http.MethodPost, "/api/jadwals", token, input("08:00", "10:00", "R1", "B 1234 CD", "Budi")), http.StatusCreated, nil)
+	missing := "/api/jadwals/" + primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{"ID tidak valid", http.MethodGet, "/api/jadwals/bukan-id", nil, http.StatusBadRequest},
+		{"hapus ID tidak valid", http.MethodDelete, "/api/jadwals/bukan-id", nil, http.StatusBadRequest},
+		{"tidak ditemukan", http.MethodGet, missing, nil, http.StatusNotFound},
+		{"update tidak ditemukan", http.MethodPut, missing, input("08:00", "10:00", "R1", "", ""), http.StatusNotFound},
+		{"hapus tidak ditemukan", http.MethodDelete, missing, nil, http.StatusNotFound},
+		{"JSON rusak", http.MethodPost, "/api/jadwals", "{", http.StatusBadRequest},
+		{"field wajib kosong", http.MethodPost, "/api/jadwals", fiber.Map{"kode_rute": "R1"}, http.StatusBadRequest},
+		{"rute tidak dikenal", http.MethodPost, "/api/jadwals", input("12:00", "13:00", "R9", "B 1234 CD", ""), http.StatusNotFound},
+		{"kendaraan tidak dikenal", http.MethodPost, "/api/jadwals", input("12:00", "13:00", "R1", "Z 9 Z", ""), http.StatusNotFound},
+		{"waktu tidak valid", http.MethodPost, "/api/jadwals", input("jam delapan", "10:00", "R1", "B 1234 CD", ""), http.StatusBadRequest},
+		{"timestamp tiba sebelum berangkat", http.MethodPost, "/api/jadwals", input("12:00", besok()+"T11:00:00+07:00", "R1", "B 1234 CD", ""), http.StatusBadRequest},
+		{"timestamp tiba sama dengan berangkat", http.MethodPost, "/api/jadwals", input(besok()+"T12:00:00+07:00", besok()+"T12:00:00+07:00", "R1", "B 1234 CD", ""), http.StatusBadRequest},
+		{"kendaraan bentrok", http.MethodPost, "/api/jadwals", input("09:00", "11:00", "R1", "B 1234 CD", ""), http.StatusConflict},
+		{"pengemudi bentrok", http.MethodPost, "/api/jadwals", input("09:30", "10:30", "R1", "B 1234 CD", "Budi"), http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.expect(t, s.request(t, tt.method, tt.path, token, tt.body), tt.status, nil)
+		})
+	}
+
+	t.Run("jadwal bersambung tidak bentrok", func(t *testing.T) {
+		s.expect(t, s.request(t, http.MethodPost, "/api/jadwals", token, input("10:00", "11:00", "R1", "B 1234 CD", "Budi")), http.StatusCreated, nil)
+	})
+
+	t.Run("jam tiba sebelum berangkat berarti keesokan harinya", func(t *testing.T) {
+		var jadwal models.Jadwal
+		s.expect(t, s.request(t, http.MethodPost, "/api/jadwals", token, input("23:00", "01:00", "R1", "B 1234 CD", "")), http.StatusCreated, &jadwal)
+		if got := jadwal.EstimasiTiba.Sub(jadwal.WaktuBerangkat); got != 2*time.Hour {
+			t.Errorf("durasi = %v, seharusnya 2 jam", got)
+		}
+	})
+}
+
+func TestJadwalBooking(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "operator", models.RoleOperator)
+	penumpangID := s.createUser(t, "penumpang", models.RoleUser).ID
+	operator := s.login(t, "operator")
+	penumpang := s.login(t, "penumpang")
+	rute, kendaraan := seedJadwalData(t, s, operator)
+
+	var jadwal models.Jadwal
+	s.expect(t, s.request(t, http.MethodPost, "/api/jadwals", operator,
+		fiber.Map{"tanggal": besok(), "waktu_berangkat": "08:00", "estimasi_tiba": "10:00", "kode_rute": "R1", "nomor_polisi": "B 1234 CD"}),
+		http.StatusCreated, &jadwal)
+	path := "/api/jadwals/" + jadwal.ID.Hex()
+
+	t.Run("kursi tidak cukup", func(t *testing.T) {
+		s.expect(t, s.request(t, http.MethodPost, path+"/bookings", penumpang, fiber.Map{"jumlah_kursi": 3}), http.StatusConflict, nil)
+	})
+
+	t.Run("jumlah kursi tidak valid", func(t *testing.T) {
+		s.expect(t, s.request(t, http.MethodPost, path+"/bookings", penumpang, fiber.Map{"jumlah_kursi": 0}), http.StatusBadRequest, nil)
+	})
+
+	t.Run("jadwal dengan booking tidak bisa dihapus", func(t *testing.T) {
+		var booking models.Booking
+		s.expect(t, s.request(t, http.MethodPost, path+"/bookings", penumpang, fiber.Map{"jumlah_kursi": 2}), http.StatusCreated, &booking)
+		s.expect(t, s.request(t, http.MethodDelete, path, operator, nil), http.StatusConflict, nil)
+
+		s.expect(t, s.request(t, http.MethodDelete, path+"/bookings/"+booking.ID.Hex(), penumpang, nil), http.StatusOK, nil)
+		s.expect(t, s.request(t, http.MethodDelete, path, operator, nil), http.StatusOK, nil)
+	})
+
+	t.Run("jadwal yang sudah berangkat", func(t *testing.T) {
+		berangkat := time.Now().Add(-time.Hour)
+		past := models.Jadwal{
+			ID:             primitive.NewObjectID(),
+			Tanggal:        berangkat.Truncate(24 * time.Hour),
+			WaktuBerangkat: berangkat,
+			EstimasiTiba:   berangkat.Add(2 * time.Hour),
+			RuteID:         rute.ID,
+			KendaraanID:    kendaraan.ID,
+		}
+		if err := s.store.Jadwal.Create(context.Background(), &past); err != nil {
+			t.Fatal(err)
+		}
+		s.expect(t, s.request(t, http.MethodPost, "/api/jadwals/"+past.ID.Hex()+"/bookings", penumpang, fiber.Map{"jumlah_kursi": 1}), http.StatusConflict, nil)
+
+		// Booking yang dibuat sebelum berangkat tidak bisa dibatalkan lagi
+		booking := models.Booking{JadwalID: past.ID, UserID: penumpangID, JumlahKursi: 1, Status: models.BookingStatusConfirmed}
+		if err := s.store.Booking.Create(context.Background(), &booking); err != nil {
+			t.Fatal(err)
+		}
+		s.expect(t, s.request(t, http.MethodDelete, "/api/jadwals/"+past.ID.Hex()+"/bookings/"+booking.ID.Hex(), penumpang, nil), http.StatusConflict, nil)
+	})
+
+	t.Run("kursi gagal dikembalikan", func(t *testing.T) {
+		var jadwal models.Jadwal
+		s.expect(t, s.request(t, http.MethodPost, "/api/jadwals", operator,
+			fiber.Map{"tanggal": besok(), "waktu_berangkat": "13:00", "estimasi_tiba": "15:00", "kode_rute": "R1", "nomor_polisi": "B 1234 CD"}),
+			http.StatusCreated, &jadwal)
+		var booking models.Booking
+		s.expect(t, s.request(t, http.MethodPost, "/api/jadwals/"+jadwal.ID.Hex()+"/bookings", penumpang, fiber.Map{"jumlah_kursi": 1}), http.StatusCreated, &booking)
+		cancelPath := "/api/jadwals/" + jadwal.ID.Hex() + "/bookings/" + booking.ID.Hex()
+
+		jadwals := s.store.Jadwal
+		s.store.Jadwal = &releaseFailingJadwalStore{JadwalStore: jadwals}
+		s.expect(t, s.request(t, http.MethodDelete, cancelPath, penumpang, nil), http.StatusInternalServerError, nil)
+		s.store.Jadwal = jadwals
+
+		// Booking kembali confirmed sehingga pembatalan bisa diulang
+		s.expect(t, s.request(t, http.MethodDelete, cancelPath, penumpang, nil), http.StatusOK, nil)
+		got, err := s.store.Jadwal.Get(context.Background(), jadwal.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.KursiTerisi != 0 {
+			t.Errorf("kursi terisi = %d, seharusnya 0", got.KursiTerisi)
+		}
+	})
+}
+
+// releaseFailingJadwalStore mensimulasikan database yang gagal saat kursi
+// dikembalikan
+type releaseFailingJadwalStore struct {
+	store.JadwalStore
+}
+
+func (f *releaseFailingJadwalStore) ReleaseSeats(ctx context.Context, id primitive.ObjectID, jumlah int) error {
+	return errors.New("koneksi terputus")
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"transport-app/store"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MigrateJadwalWaktu mengubah jadwal lama yang masih menyimpan tanggal dan
+// waktu sebagai string menjadi BSON date sesuai zona waktu rutenya. Hanya
+// dokumen yang tanggalnya masih bertipe string yang diproses, sehingga aman
+// dijalankan berulang kali. Dokumen yang gagal diparse dilewati dan ID-nya
+// dikembalikan agar bisa diperbaiki manual.
+func (h *Handler) MigrateJadwalWaktu(ctx context.Context) (int, []string, error) {
+	jadwals, err := h.Store.Jadwal.FindLegacyWaktu(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	zonaRute := map[primitive.ObjectID]*time.Location{}
+	migrated := 0
+	failed := []string{}
+
+	for _, old := range jadwals {
+		loc, ok := zonaRute[old.RuteID]
+		if !ok {
+			// Rute yang sudah dihapus memakai zona waktu default. Error lain
+			// menghentikan migrasi agar jadwal tidak dikonversi dengan zona
+			// yang salah.
+			rute, err := h.Store.Rute.Get(ctx, old.RuteID)
+			if err != nil && err != store.ErrNotFound {
+				return migrated, failed, fmt.Errorf("gagal membaca rute %s: %w", old.RuteID.Hex(), err)
+			}
+			loc = loadZonaWaktu(rute.ZonaWaktu)
+			zonaRute[old.RuteID] = loc
+		}
+
+		tanggal, berangkat, tiba, err := parseJadwalWaktu(old.Tanggal, old.WaktuBerangkat, old.EstimasiTiba, loc)
+		if err != nil {
+			fmt.Println("⚠️ Jadwal", old.ID.Hex(), "dilewati:", err)
+			failed = append(failed, old.ID.Hex())
+			continue
+		}
+
+		if err := h.Store.Jadwal.SetWaktu(ctx, old.ID, tanggal, berangkat, tiba); err != nil {
+			return migrated, failed, err
+		}
+		migrated++
+	}
+
+	return migrated, failed, nil
+}
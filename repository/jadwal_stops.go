@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"transport-app/models"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// validateRuteHalte memeriksa daftar halte rute dan mengembalikan pesan
+// error untuk client, atau string kosong jika valid. Rute tanpa halte tetap
+// diperbolehkan.
+func (h *Handler) validateRuteHalte(ctx context.Context, rute models.Rute) (string, error) {
+	if len(rute.Halte) == 0 {
+		return "", nil
+	}
+	if len(rute.Halte) < 2 {
+		return "Rute dengan halte minimal memiliki dua halte", nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(rute.Halte))
+	seen := map[primitive.ObjectID]bool{}
+	for i, stop := range rute.Halte {
+		if seen[stop.HalteID] {
+			return fmt.Sprintf("Halte ke-%d muncul lebih dari sekali", i+1), nil
+		}
+		seen[stop.HalteID] = true
+		ids = append(ids, stop.HalteID)
+
+		if stop.DwellMenit < 0 {
+			return fmt.Sprintf("Dwell halte ke-%d tidak boleh negatif", i+1), nil
+		}
+		if i == 0 && stop.JarakKM != 0 {
+			return "Jarak halte pertama harus 0", nil
+		}
+		if i > 0 && stop.JarakKM <= rute.Halte[i-1].JarakKM {
+			return fmt.Sprintf("Jarak halte ke-%d harus lebih besar dari halte sebelumnya", i+1), nil
+		}
+	}
+
+	last := rute.Halte[len(rute.Halte)-1].JarakKM
+	if math.Abs(last-float64(rute.JarakKM)) > 0.5 {
+		return fmt.Sprintf("Jarak halte terakhir (%.1f km) harus sama dengan jarak rute (%d km)", last, rute.JarakKM), nil
+	}
+
+	haltes, err := h.Store.Halte.GetMany(ctx, ids)
+	if err != nil {
+		return "", err
+	}
+	for i, id := range ids {
+		if _, ok := haltes[id]; !ok {
+			return fmt.Sprintf("Halte ke-%d tidak ditemukan", i+1), nil
+		}
+	}
+
+	first, end := haltes[ids[0]], haltes[ids[len(ids)-1]]
+	if !strings.EqualFold(first.Kota, strings.TrimSpace(rute.Asal)) {
+		return "Halte pertama harus berada di kota asal " + rute.Asal, nil
+	}
+	if !strings.EqualFold(end.Kota, strings.TrimSpace(rute.Tujuan)) {
+		return "Halte terakhir harus berada di kota tujuan " + rute.Tujuan, nil
+	}
+	return "", nil
+}
+
+// jadwalStops menghitung waktu tiba dan berangkat jadwal di setiap halte
+// rute. Waktu perjalanan tanpa dwell dibagi sebanding dengan jarak, lalu
+// dwell halte antara ditambahkan. Halte pertama berangkat tepat pada
+// WaktuBerangkat dan halte terakhir tiba tepat pada EstimasiTiba.
+func jadwalStops(jadwal models.Jadwal, rute models.Rute, haltes map[primitive.ObjectID]models.Halte) []models.JadwalStop {
+	stops := []models.JadwalStop{}
+	n := len(rute.Halte)
+	if n < 2 {
+		return stops
+	}
+
+	var dwellTotal time.Duration
+	for _, stop := range rute.Halte[1 : n-1] {
+		dwellTotal += time.Duration(stop.DwellMenit) * time.Minute
+	}
+	moving := jadwal.EstimasiTiba.Sub(jadwal.WaktuBerangkat) - dwellTotal
+	if moving < 0 {
+		moving = 0
+	}
+	total := rute.Halte[n-1].JarakKM
+
+	var dwellSoFar time.Duration
+	for i, stop := range rute.Halte {
+		tiba := jadwal.WaktuBerangkat.Add(time.Duration(float64(moving)*stop.JarakKM/total) + dwellSoFar).Round(time.Minute)
+		berangkat := tiba
+		switch i {
+		case 0:
+			tiba, berangkat = jadwal.WaktuBerangkat, jadwal.WaktuBerangkat
+		case n - 1:
+			tiba, berangkat = jadwal.EstimasiTiba, jadwal.EstimasiTiba
+		default:
+			dwell := time.Duration(stop.DwellMenit) * time.Minute
+			berangkat = tiba.Add(dwell)
+			dwellSoFar += dwell
+		}
+
+		stops = append(stops, models.JadwalStop{
+			Urutan:    i + 1,
+			Halte:     haltes[stop.HalteID],
+			JarakKM:   stop.JarakKM,
+			Tiba:      tiba,
+			Berangkat: berangkat,
+		})
+	}
+	return stops
+}
+
+// ruteHalteIndex mencari posisi halte dengan kode tersebut pada rute,
+// -1 jika rute tidak melewati halte itu
+func (h *Handler) ruteHalteIndex(ctx context.Context, rute models.Rute, kode string) (int, primitive.ObjectID, error) {
+	halte, err := h.Store.Halte.GetByKode(ctx, strings.TrimSpace(kode))
+	if err != nil {
+		return -1, primitive.NilObjectID, err
+	}
+	for i, stop := range rute.Halte {
+		if stop.HalteID == halte.ID {
+			return i, halte.ID, nil
+		}
+	}
+	return -1, halte.ID, nil
+}
+
+// GetJadwalStops godoc
+// @Summary Get stop times of a jadwal
+// @Description Mengambil waktu tiba dan berangkat jadwal di setiap halte rute, dihitung dari jarak kumulatif dan dwell halte. Rute tanpa halte menghasilkan daftar kosong
+// @Tags Jadwal
+// @Produce json
+// @Param id path string true "Jadwal ID"
+// @Success 200 {array} models.JadwalStop "Waktu di setiap halte"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Jadwal or Rute not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/jadwals/{id}/stops [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+func (h *Handler) GetJadwalStops(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	jadwal, err := h.Store.Jadwal.Get(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Jadwal not found"})
+	}
+	rute, err := h.Store.Rute.Get(ctx, jadwal.RuteID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Rute not found"})
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(rute.Halte))
+	for _, stop := range rute.Halte {
+		ids = append(ids, stop.HalteID)
+	}
+	haltes, err := h.Store.Halte.GetMany(ctx, ids)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	localizeJadwal(&jadwal, loadZonaWaktu(rute.ZonaWaktu))
+	return c.JSON(jadwalStops(jadwal, rute, haltes))
+}
@@ -0,0 +1,498 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const defaultHorizonHari = 14
+const maxHorizonHari = 90
+
+type JadwalTemplateRequest struct {
+	KodeRute            string   `json:"kode_rute"`
+	NomorPolisi         string   `json:"nomor_polisi"`
+	Pengemudi           string   `json:"pengemudi"`
+	JamBerangkat        string   `json:"jam_berangkat"`
+	DurasiMenit         int      `json:"durasi_menit"`
+	HariOperasi         []int    `json:"hari_operasi"`
+	TanggalMulai        string   `json:"tanggal_mulai"`
+	TanggalSelesai      string   `json:"tanggal_selesai"`
+	TanggalPengecualian []string `json:"tanggal_pengecualian"`
+	Aktif               *bool    `json:"aktif"`
+}
+
+// GenerateResult merangkum hasil pembuatan jadwal dari template
+type GenerateResult struct {
+	Dibuat   []string          `json:"dibuat"`
+	SudahAda int               `json:"sudah_ada"`
+	Bentrok  []GenerateKonflik `json:"bentrok"`
+}
+
+type GenerateKonflik struct {
+	Tanggal   string   `json:"tanggal"`
+	Conflicts []string `json:"conflicts"`
+}
+
+// buildJadwalTemplate memvalidasi input dan mengubahnya menjadi template.
+// Tanggal diinterpretasikan pada zona waktu rute.
+func (h *Handler) buildJadwalTemplate(input JadwalTemplateRequest) (models.JadwalTemplate, int, string) {
+	var tpl models.JadwalTemplate
+
+	if input.KodeRute == "" || input.NomorPolisi == "" || input.JamBerangkat == "" || input.TanggalMulai == "" || len(input.HariOperasi) == 0 {
+		return tpl, 400, "kode_rute, nomor_polisi, jam_berangkat, hari_operasi dan tanggal_mulai wajib diisi"
+	}
+	if input.DurasiMenit <= 0 {
+		return tpl, 400, "Durasi harus lebih dari 0 menit"
+	}
+	for _, h := range input.HariOperasi {
+		if h < 0 || h > 6 {
+			return tpl, 400, "hari_operasi harus bernilai 0 (Minggu) sampai 6 (Sabtu)"
+		}
+	}
+
+	rute, err := h.Store.Rute.GetByKode(context.TODO(), input.KodeRute)
+	if err != nil {
+		return tpl, 404, "Rute not found"
+	}
+	kendaraan, err := h.Store.Kendaraan.GetByNomorPolisi(context.TODO(), input.NomorPolisi)
+	if err != nil {
+		return tpl, 404, "Kendaraan not found"
+	}
+
+	loc := loadZonaWaktu(rute.ZonaWaktu)
+	if _, err := parseWithLayouts(input.JamBerangkat, waktuLayouts, loc); err != nil {
+		return tpl, 400, "Format jam_berangkat tidak valid"
+	}
+	mulai, err := parseWithLayouts(input.TanggalMulai, tanggalLayouts, loc)
+	if err != nil {
+		return tpl, 400, "Format tanggal_mulai tidak valid"
+	}
+
+	tpl = models.JadwalTemplate{
+		RuteID:              rute.ID,
+		KendaraanID:         kendaraan.ID,
+		Pengemudi:           input.Pengemudi,
+		JamBerangkat:        input.JamBerangkat,
+		DurasiMenit:         input.DurasiMenit,
+		HariOperasi:         input.HariOperasi,
+		TanggalMulai:        mulai,
+		TanggalPengecualian: []time.Time{},
+		Aktif:               input.Aktif == nil || *input.Aktif,
+	}
+
+	if input.TanggalSelesai != "" {
+		selesai, err := parseWithLayouts(input.TanggalSelesai, tanggalLayouts, loc)
+		if err != nil {
+			return tpl, 400, "Format tanggal_selesai tidak valid"
+		}
+		if selesai.Before(mulai) {
+			return tpl, 400, "tanggal_selesai tidak boleh sebelum tanggal_mulai"
+		}
+		tpl.TanggalSelesai = &selesai
+	}
+
+	for _, t := range input.TanggalPengecualian {
+		d, err := parseWithLayouts(t, tanggalLayouts, loc)
+		if err != nil {
+			return tpl, 400, "Format tanggal_pengecualian tidak valid: " + t
+		}
+		tpl.TanggalPengecualian = append(tpl.TanggalPengecualian, d)
+	}
+
+	return tpl, 0, ""
+}
+
+// generateFromTemplate membuat jadwal dari template untuk horizonHari hari
+// mulai hari ini. Jadwal yang sudah pernah dibuat untuk tanggal yang
+// sama tidak dibuat ulang, dan tanggal yang bentrok dengan jadwal lain
+// dilaporkan tanpa dibuat.
+func (h *Handler) generateFromTemplate(ctx context.Context, tpl models.JadwalTemplate, horizonHari int, now time.Time) (GenerateResult, error) {
+	result := GenerateResult{Dibuat: []string{}, Bentrok: []GenerateKonflik{}}
+
+	rute, err := h.Store.Rute.Get(ctx, tpl.RuteID)
+	if err != nil {
+		return result, fmt.Errorf("rute template tidak ditemukan: %w", err)
+	}
+	loc := loadZonaWaktu(rute.ZonaWaktu)
+
+	jam, err := parseWithLayouts(tpl.JamBerangkat, waktuLayouts, loc)
+	if err != nil {
+		return result, err
+	}
+
+	// until tidak ikut dibuat; tanggal_selesai tetap ikut dibuat
+	today := now.In(loc)
+	from := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, loc)
+	until := from.AddDate(0, 0, horizonHari)
+
+	mulai := tpl.TanggalMulai.In(loc)
+	if mulai.After(from) {
+		from = time.Date(mulai.Year(), mulai.Month(), mulai.Day(), 0, 0, 0, 0, loc)
+	}
+	if tpl.TanggalSelesai != nil {
+		selesai := tpl.TanggalSelesai.In(loc)
+		selesai = time.Date(selesai.Year(), selesai.Month(), selesai.Day()+1, 0, 0, 0, 0, loc)
+		if selesai.Before(until) {
+			until = selesai
+		}
+	}
+
+	hari := map[time.Weekday]bool{}
+	for _, h := range tpl.HariOperasi {
+		hari[time.Weekday(h)] = true
+	}
+	libur := map[string]bool{}
+	for _, t := range tpl.TanggalPengecualian {
+		libur[t.In(loc).Format("2006-01-02")] = true
+	}
+
+	// Kunci dipegang selama generate agar jadwal lain untuk kendaraan atau
+	// pengemudi yang sama tidak dibuat di antara cek bentrok dan penyimpanan
+	unlock, err := h.lockJadwal(ctx, jadwalLockTTL, kendaraanLockKey(tpl.KendaraanID), pengemudiLockKey(tpl.Pengemudi))
+	if err != nil {
+		return result, err
+	}
+	defer unlock()
+
+	for day := from; day.Before(until); day = day.AddDate(0, 0, 1) {
+		if !hari[day.Weekday()] || libur[day.Format("2006-01-02")] {
+			continue
+		}
+
+		exists, err := h.Store.Jadwal.ExistsForTemplate(ctx, tpl.ID, day)
+		if err != nil {
+			return result, err
+		}
+		if exists {
+			result.SudahAda++
+			continue
+		}
+
+		berangkat := time.Date(day.Year(), day.Month(), day.Day(), jam.Hour(), jam.Minute(), 0, 0, loc)
+		tiba := berangkat.Add(time.Duration(tpl.DurasiMenit) * time.Minute)
+
+		conflicts, err := h.findJadwalConflicts(ctx, tpl.KendaraanID, tpl.Pengemudi, berangkat, tiba, primitive.NilObjectID)
+		if err != nil {
+			return result, err
+		}
+		if len(conflicts) > 0 {
+			result.Bentrok = append(result.Bentrok, GenerateKonflik{Tanggal: day.Format("2006-01-02"), Conflicts: conflicts})
+			continue
+		}
+
+		templateID := tpl.ID
+		jadwal := models.Jadwal{
+			ID:             primitive.NewObjectID(),
+			Tanggal:        day,
+			WaktuBerangkat: berangkat,
+			EstimasiTiba:   tiba,
+			RuteID:         tpl.RuteID,
+			KendaraanID:    tpl.KendaraanID,
+			Pengemudi:      tpl.Pengemudi,
+			TemplateID:     &templateID,
+		}
+
+		// Store menjaga generator tetap idempoten walaupun dijalankan
+		// bersamaan dari beberapa instance
+		created, err := h.Store.Jadwal.CreateFromTemplate(ctx, &jadwal)
+		if err != nil {
+			return result, err
+		}
+		if created {
+			result.Dibuat = append(result.Dibuat, jadwal.ID.Hex())
+		} else {
+			result.SudahAda++
+		}
+	}
+
+	return result, nil
+}
+
+// GenerateAllJadwalTemplates menjalankan generator untuk semua template aktif
+func (h *Handler) GenerateAllJadwalTemplates(ctx context.Context, horizonHari int) (map[string]GenerateResult, error) {
+	templates, err := h.Store.JadwalTemplate.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := map[string]GenerateResult{}
+	for _, tpl := range templates {
+		res, err := h.generateFromTemplate(ctx, tpl, horizonHari, time.Now())
+		if err != nil {
+			fmt.Println("⚠️ Gagal generate template", tpl.ID.Hex(), ":", err)
+			continue
+		}
+		results[tpl.ID.Hex()] = res
+	}
+	return results, nil
+}
+
+// StartJadwalGenerator menjalankan generator sekali saat start lalu setiap
+// interval, sehingga jadwal selalu tersedia untuk JADWAL_HORIZON_HARI ke depan.
+func (h *Handler) StartJadwalGenerator(interval time.Duration) {
+	horizon := defaultHorizonHari
+	if v, err := strconv.Atoi(os.Getenv("JADWAL_HORIZON_HARI")); err == nil && v > 0 && v <= maxHorizonHari {
+		horizon = v
+	}
+
+	go func() {
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			if _, err := h.GenerateAllJadwalTemplates(ctx, horizon); err != nil {
+				fmt.Println("⚠️ Generator jadwal gagal:", err)
+			}
+			cancel()
+			time.Sleep(interval)
+		}
+	}()
+}
+
+func parseHorizon(c *fiber.Ctx) int {
+	horizon := c.QueryInt("hari", defaultHorizonHari)
+	if horizon <= 0 {
+		horizon = defaultHorizonHari
+	}
+	if horizon > maxHorizonHari {
+		horizon = maxHorizonHari
+	}
+	return horizon
+}
+
+// GetAllJadwalTemplate godoc
+// @Summary Get all jadwal templates
+// @Description Mengambil semua template jadwal berulang (Admin Only)
+// @Tags JadwalTemplate
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.JadwalTemplate "Daftar template jadwal"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/jadwal-templates [get]
+// @Security BearerAuth
+func (h *Handler) GetAllJadwalTemplate(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	templates, err := h.Store.JadwalTemplate.All(ctx)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(templates)
+}
+
+// GetJadwalTemplateByID godoc
+// @Summary Get a jadwal template by ID
+// @Description Mengambil template jadwal berdasarkan ID (Admin Only)
+// @Tags JadwalTemplate
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} models.JadwalTemplate "Data template"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} models.ErrorResponse "Template not found"
+// @Router /api/jadwal-templates/{id} [get]
+// @Security BearerAuth
+func (h *Handler) GetJadwalTemplateByID(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	tpl, err := h.Store.JadwalTemplate.Get(context.TODO(), objID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Template not found"})
+	}
+
+	return c.JSON(tpl)
+}
+
+// CreateJadwalTemplate godoc
+// @Summary Create a jadwal template
+// @Description Membuat template jadwal berulang (Admin Only)
+// @Tags JadwalTemplate
+// @Accept json
+// @Produce json
+// @Param template body JadwalTemplateRequest true "Data template"
+// @Success 201 {object} models.JadwalTemplate "Template berhasil dibuat"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - data tidak valid"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} models.ErrorResponse "Rute or Kendaraan not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/jadwal-templates [post]
+// @Security BearerAuth
+func (h *Handler) CreateJadwalTemplate(c *fiber.Ctx) error {
+	var input JadwalTemplateRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tpl, status, msg := h.buildJadwalTemplate(input)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	tpl.ID = primitive.NewObjectID()
+
+	if err := h.Store.JadwalTemplate.Create(context.TODO(), &tpl); err != nil {
+		fmt.Println("❌ Error saat menyimpan template jadwal:", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(tpl)
+}
+
+// UpdateJadwalTemplate godoc
+// @Summary Update a jadwal template
+// @Description Memperbarui template jadwal. Jadwal yang sudah dibuat tidak ikut berubah (Admin Only)
+// @Tags JadwalTemplate
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param template body JadwalTemplateRequest true "Data template"
+// @Success 200 {object} models.JadwalTemplate "Template diupdate"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID atau Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} models.ErrorResponse "Template, Rute or Kendaraan not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/jadwal-templates/{id} [put]
+// @Security BearerAuth
+func (h *Handler) UpdateJadwalTemplate(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var input JadwalTemplateRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tpl, status, msg := h.buildJadwalTemplate(input)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	tpl.ID = objID
+
+	err = h.Store.JadwalTemplate.Replace(context.TODO(), tpl)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Template not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(tpl)
+}
+
+// DeleteJadwalTemplate godoc
+// @Summary Delete a jadwal template
+// @Description Menghapus template jadwal. Jadwal yang sudah dibuat tetap ada (Admin Only)
+// @Tags JadwalTemplate
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} models.SuccessResponse "Template dihapus"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} models.ErrorResponse "Template not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/jadwal-templates/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteJadwalTemplate(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	err = h.Store.JadwalTemplate.Delete(context.TODO(), objID)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Template not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Template dihapus"})
+}
+
+// GenerateJadwalFromTemplate godoc
+// @Summary Generate jadwal from a template
+// @Description Membuat jadwal dari template untuk beberapa hari ke depan. Aman dijalankan berulang kali (Admin Only)
+// @Tags JadwalTemplate
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param hari query int false "Jumlah hari ke depan (default 14, maks 90)"
+// @Success 200 {object} GenerateResult "Hasil generate"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} models.ErrorResponse "Template not found"
+// @Failure 409 {object} models.ErrorResponse "Kendaraan sedang dijadwalkan oleh request lain"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/jadwal-templates/{id}/generate [post]
+// @Security BearerAuth
+func (h *Handler) GenerateJadwalFromTemplate(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	tpl, err := h.Store.JadwalTemplate.Get(ctx, objID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Template not found"})
+	}
+
+	result, err := h.generateFromTemplate(ctx, tpl, parseHorizon(c), time.Now())
+	if err == errJadwalLocked {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		fmt.Println("❌ Error saat generate jadwal:", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(result)
+}
+
+// GenerateAllJadwal godoc
+// @Summary Generate jadwal from all active templates
+// @Description Membuat jadwal dari semua template aktif untuk beberapa hari ke depan (Admin Only)
+// @Tags JadwalTemplate
+// @Accept json
+// @Produce json
+// @Param hari query int false "Jumlah hari ke depan (default 14, maks 90)"
+// @Success 200 {object} map[string]GenerateResult "Hasil generate per template"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/jadwal-templates/generate [post]
+// @Security BearerAuth
+func (h *Handler) GenerateAllJadwal(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	results, err := h.GenerateAllJadwalTemplates(ctx, parseHorizon(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(results)
+}
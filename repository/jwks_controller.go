@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetJWKS godoc
+// @Summary JSON Web Key Set
+// @Description Kunci publik (RS256/EdDSA) untuk memverifikasi access token, dikenali lewat kid di header token. Kunci pengganti dipublikasikan sebelum dipakai. Kosong jika server memakai HS256
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} jwtkeys.JWKS "Daftar kunci publik"
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.Keys.JWKS())
+}
@@ -4,46 +4,55 @@ import (
 	"context"
 	"fmt"
 	"time"
-	"transport-app/config"
 	"transport-app/models"
+	"transport-app/query"
+	"transport-app/store"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func getKendaraanCollection() *mongo.Collection {
-	return config.GetCollection("kendaraan")
+var kendaraanSchema = query.Schema{
+	"nomor_polisi": {BSON: "nomor_polisi", Kind: query.String},
+	"jenis":        {BSON: "jenis", Kind: query.String},
+	"kapasitas":    {BSON: "kapasitas", Kind: query.Int},
+	"status":       {BSON: "status", Kind: query.String},
 }
 
 // GetAllKendaraan godoc
 // @Summary Get all kendaraan
-// @Description Mengambil semua data kendaraan
+// @Description Mengambil data kendaraan dengan filter, sort dan pagination. Filter: field=nilai, field[op]=nilai (eq, ne, gt, gte, lt, lte, in). Sort: sort=field,-field
 // @Tags Kendaraan
 // @Accept json
 // @Produce json
-// @Success 200 {array} models.Kendaraan "Daftar semua kendaraan"
+// @Param page query int false "Nomor halaman (default 1)"
+// @Param limit query int false "Jumlah data per halaman (default 20, maks 100)"
+// @Param cursor query string false "Cursor dari next_cursor halaman sebelumnya"
+// @Param sort query string false "Urutan, mis. jenis,-kapasitas"
+// @Param jenis query string false "Filter jenis kendaraan"
+// @Param status query string false "Filter status kendaraan"
+// @Success 200 {object} query.Page[models.Kendaraan] "Daftar kendaraan"
+// @Failure 400 {object} models.ErrorResponse "Query tidak valid"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/kendaraans [get]
 // @Security BearerAuth
-func GetAllKendaraan(c *fiber.Ctx) error {
+// @Security APIKeyAuth
+func (h *Handler) GetAllKendaraan(c *fiber.Ctx) error {
+	q, err := query.Parse(c, kendaraanSchema, []query.Sort{{Field: "nomor_polisi"}})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Ambil semua kendaraan
-	cursor, err := getKendaraanCollection().Find(ctx, bson.M{})
+	page, err := h.Store.Kendaraan.List(ctx, q)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	var kendaraanList []models.Kendaraan
-	if err := cursor.All(ctx, &kendaraanList); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(kendaraanList)
+	return c.JSON(page.WithNext(c))
 }
 
 // GetKendaraanByID godoc
@@ -59,7 +68,8 @@ func GetAllKendaraan(c *fiber.Ctx) error {
 // @Failure 404 {object} models.ErrorResponse "Kendaraan not found"
 // @Router /api/kendaraans/{id} [get]
 // @Security BearerAuth
-func GetKendaraanByID(c *fiber.Ctx) error {
+// @Security APIKeyAuth
+func (h *Handler) GetKendaraanByID(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	// Konversi ID dari string ke ObjectID
@@ -69,8 +79,7 @@ func GetKendaraanByID(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	var kendaraan models.Kendaraan
-	err = getKendaraanCollection().FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&kendaraan)
+	kendaraan, err := h.Store.Kendaraan.Get(context.TODO(), objID)
 	if err != nil {
 		fmt.Println("❌ Kendaraan not found with ID:", id)
 		return c.Status(404).JSON(fiber.Map{"error": "Kendaraan not found"})
@@ -93,7 +102,7 @@ func GetKendaraanByID(c *fiber.Ctx) error {
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/kendaraans [post]
 // @Security BearerAuth
-func CreateKendaraan(c *fiber.Ctx) error {
+func (h *Handler) CreateKendaraan(c *fiber.Ctx) error {
 	var input struct {
 		NomorPolisi string `json:"nomor_polisi"`
 		Jenis       string `json:"jenis"`
@@ -123,7 +132,7 @@ func CreateKendaraan(c *fiber.Ctx) error {
 		Status:      input.Status,
 	}
 
-	_, err := getKendaraanCollection().InsertOne(context.TODO(), kendaraan)
+	err := h.Store.Kendaraan.Create(context.TODO(), &kendaraan)
 	if err != nil {
 		fmt.Println("❌ Error saat menyimpan kendaraan:", err)
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
@@ -145,10 +154,11 @@ func CreateKendaraan(c *fiber.Ctx) error {
 // @Failure 400 {object} models.ErrorResponse "Invalid ID atau Bad Request"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} models.ErrorResponse "Kendaraan not found"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/kendaraans/{id} [put]
 // @Security BearerAuth
-func UpdateKendaraan(c *fiber.Ctx) error {
+func (h *Handler) UpdateKendaraan(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	// Konversi ID dari string ke ObjectID
@@ -172,8 +182,11 @@ func UpdateKendaraan(c *fiber.Ctx) error {
 		})
 	}
 
-	update := bson.M{"$set": kendaraan}
-	_, err = getKendaraanCollection().UpdateByID(context.TODO(), objID, update)
+	kendaraan.ID = objID
+	err = h.Store.Kendaraan.Update(context.TODO(), kendaraan)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Kendaraan not found"})
+	}
 	if err != nil {
 		fmt.Println("❌ Error saat mengupdate kendaraan:", err)
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
@@ -195,10 +208,11 @@ func UpdateKendaraan(c *fiber.Ctx) error {
 // @Failure 400 {object} models.ErrorResponse "Invalid ID"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} models.ErrorResponse "Kendaraan not found"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/kendaraans/{id} [delete]
 // @Security BearerAuth
-func DeleteKendaraan(c *fiber.Ctx) error {
+func (h *Handler) DeleteKendaraan(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	// Konversi ID dari string ke ObjectID
@@ -208,7 +222,10 @@ func DeleteKendaraan(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	_, err = getKendaraanCollection().DeleteOne(context.TODO(), bson.M{"_id": objID})
+	err = h.Store.Kendaraan.Delete(context.TODO(), objID)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Kendaraan not found"})
+	}
 	if err != nil {
 		fmt.Println("❌ Error saat menghapus kendaraan:", err)
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
@@ -0,0 +1,79 @@
+package repository_test
+
+import (
+	"net/http"
+	"testing"
+
+	"transport-app/models"
+	"transport-app/query"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestKendaraanCRUD(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "operator", models.RoleOperator)
+	token := s.login(t, "operator")
+
+	input := fiber.Map{"nomor_polisi": "B 1234 CD", "jenis": "Bus", "kapasitas": 40, "status": "aktif"}
+	var created models.Kendaraan
+	s.expect(t, s.request(t, http.MethodPost, "/api/kendaraans", token, input), http.StatusCreated, &created)
+	if created.ID.IsZero() {
+		t.Fatal("kendaraan dibuat tanpa ID")
+	}
+	path := "/api/kendaraans/" + created.ID.Hex()
+
+	var got models.Kendaraan
+	s.expect(t, s.request(t, http.MethodGet, path, token, nil), http.StatusOK, &got)
+	if got.NomorPolisi != "B 1234 CD" || got.Kapasitas != 40 {
+		t.Fatalf("kendaraan tidak sesuai: %+v", got)
+	}
+
+	var page query.Page[models.Kendaraan]
+	s.expect(t, s.request(t, http.MethodGet, "/api/kendaraans", token, nil), http.StatusOK, &page)
+	if page.Total != 1 || len(page.Data) != 1 {
+		t.Fatalf("daftar kendaraan: %+v", page)
+	}
+
+	input["status"] = "perawatan"
+	s.expect(t, s.request(t, http.MethodPut, path, token, input), http.StatusOK, nil)
+	s.expect(t, s.request(t, http.MethodGet, path, token, nil), http.StatusOK, &got)
+	if got.Status != "perawatan" {
+		t.Fatalf("kendaraan tidak diperbarui: %+v", got)
+	}
+
+	s.expect(t, s.request(t, http.MethodDelete, path, token, nil), http.StatusOK, nil)
+	s.expect(t, s.request(t, http.MethodGet, path, token, nil), http.StatusNotFound, nil)
+}
+
+func TestKendaraanErrors(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "operator", models.RoleOperator)
+	token := s.login(t, "operator")
+	missing := "/api/kendaraans/" + primitive.NewObjectID().Hex()
+	valid := fiber.Map{"nomor_polisi": "B 1 A", "jenis": "Bus", "kapasitas": 10, "status": "aktif"}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{"ID tidak valid", http.MethodGet, "/api/kendaraans/bukan-id", nil, http.StatusBadRequest},
+		{"update ID tidak valid", http.MethodPut, "/api/kendaraans/bukan-id", valid, http.StatusBadRequest},
+		{"hapus ID tidak valid", http.MethodDelete, "/api/kendaraans/bukan-id", nil, http.StatusBadRequest},
+		{"tidak ditemukan", http.MethodGet, missing, nil, http.StatusNotFound},
+		{"update tidak ditemukan", http.MethodPut, missing, valid, http.StatusNotFound},
+		{"hapus tidak ditemukan", http.MethodDelete, missing, nil, http.StatusNotFound},
+		{"JSON rusak", http.MethodPost, "/api/kendaraans", "{", http.StatusBadRequest},
+		{"field wajib kosong", http.MethodPost, "/api/kendaraans", fiber.Map{"nomor_polisi": "B 1 A"}, http.StatusBadRequest},
+		{"kapasitas tidak positif", http.MethodPost, "/api/kendaraans", fiber.Map{"nomor_polisi": "B 1 A", "jenis": "Bus", "kapasitas": 0, "status": "aktif"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.expect(t, s.request(t, tt.method, tt.path, token, tt.body), tt.status, nil)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// loginLimit mengatur batas login gagal untuk satu jenis kunci. Setelah
+// free kali gagal, setiap kegagalan berikutnya mengunci kunci tersebut
+// dengan jeda yang berlipat dua. Setelah lockAfter kali gagal, kunci
+// dikunci selama loginLockout.
+type loginLimit struct {
+	free      int
+	lockAfter int
+}
+
+var (
+	accountLoginLimit = loginLimit{free: 3, lockAfter: 10}
+	// IP dibuat lebih longgar karena banyak user bisa berbagi satu IP
+	ipLoginLimit = loginLimit{free: 20, lockAfter: 100}
+)
+
+const (
+	loginBackoffBase   = time.Second
+	loginBackoffMax    = 5 * time.Minute
+	loginLockout       = 15 * time.Minute
+	loginAttemptWindow = time.Hour // Hitungan gagal direset jika tidak ada kegagalan selama ini
+)
+
+// delay menghitung lama kunci setelah failures kali gagal
+func (l loginLimit) delay(failures int) time.Duration {
+	if failures >= l.lockAfter {
+		return loginLockout
+	}
+	if failures <= l.free {
+		return 0
+	}
+	d := loginBackoffBase << uint(failures-l.free-1)
+	if d <= 0 || d > loginBackoffMax {
+		return loginBackoffMax
+	}
+	return d
+}
+
+func accountAttemptKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// beginLoginAttempt menghitung percobaan untuk username dan IP request
+// sebagai gagal sebelum password diperiksa dan mengunci kunci yang melewati
+// batas. Pengecekan kunci, hitungan dan kunci baru disimpan dalam satu
+// operasi atomik, sehingga percobaan bersamaan tidak bisa sama-sama lolos sebelum
+// kegagalan pertama tercatat. Mengembalikan sisa waktu kunci terlama, atau
+// 0 jika percobaan boleh dilanjutkan; percobaan yang berhasil harus memanggil
+// loginSucceeded. Error store hanya dicatat agar login tetap bisa dipakai
+// walaupun penyimpanan hitungan bermasalah.
+func (h *Handler) beginLoginAttempt(ctx context.Context, c *fiber.Ctx, username string) time.Duration {
+	now := time.Now()
+	limits := []struct {
+		key   string
+		limit loginLimit
+	}{
+		{accountAttemptKey(username), accountLoginLimit},
+		{ipAttemptKey(c.IP()), ipLoginLimit},
+	}
+
+	var wait time.Duration
+	counted := []string{}
+	for _, l := range limits {
+		attempt, err := h.Store.LoginAttempt.Attempt(ctx, l.key, now, loginAttemptWindow, l.limit.delay)
+		if err == store.ErrLocked {
+			if d := attempt.LockedUntil.Sub(now); d > wait {
+				wait = d
+			}
+			continue
+		}
+		if err != nil {
+			fmt.Println("❌ Gagal mencatat percobaan login:", err)
+			continue
+		}
+		counted = append(counted, l.key)
+		if l.limit.delay(attempt.Failures) == loginLockout {
+			fmt.Printf("⚠️ %s dikunci %s setelah %d kali login gagal\n", l.key, loginLockout, attempt.Failures)
+		}
+	}
+
+	// Percobaan yang ditolak karena kunci lain tidak ikut dihitung
+	if wait > 0 {
+		for _, key := range counted {
+			if err := h.Store.LoginAttempt.Forgive(ctx, key); err != nil {
+				fmt.Println("❌ Gagal mengurangi hitungan login:", err)
+			}
+		}
+	}
+	return wait
+}
+
+// loginSucceeded menghapus hitungan gagal username dan membatalkan hitungan
+// IP untuk percobaan yang berhasil. Hitungan IP tidak direset agar satu akun
+// valid tidak bisa dipakai untuk membuka kunci IP.
+func (h *Handler) loginSucceeded(ctx context.Context, c *fiber.Ctx, username string) {
+	if err := h.Store.LoginAttempt.Reset(ctx, accountAttemptKey(username)); err != nil {
+		fmt.Println("❌ Gagal mereset hitungan login:", err)
+	}
+	if err := h.Store.LoginAttempt.Forgive(ctx, ipAttemptKey(c.IP())); err != nil {
+		fmt.Println("❌ Gagal mengurangi hitungan login:", err)
+	}
+}
+
+func loginLockedResponse(c *fiber.Ctx, wait time.Duration) error {
+	seconds := int(wait.Seconds()) + 1
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error": fmt.Sprintf("Terlalu banyak percobaan login gagal, coba lagi dalam %d detik", seconds),
+	})
+}
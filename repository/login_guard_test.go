@@ -0,0 +1,42 @@
+package repository_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"transport-app/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestLoginBurst memastikan tebakan password yang dikirim bersamaan tidak
+// bisa sama-sama lolos pengecekan kunci sebelum kegagalan pertama tercatat
+func TestLoginBurst(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "budi", models.RoleUser)
+
+	const n = 30
+	statuses := make(chan int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := s.request(t, http.MethodPost, "/api/login", "", fiber.Map{"username": "budi", "password": "salah"})
+			res.Body.Close()
+			statuses <- res.StatusCode
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	count := map[int]int{}
+	for status := range statuses {
+		count[status]++
+	}
+	// Tiga percobaan bebas ditambah percobaan keempat yang mulai mengunci
+	if count[http.StatusUnauthorized] != 4 || count[http.StatusTooManyRequests] != n-4 {
+		t.Fatalf("status %v, seharusnya 4 kali 401 dan sisanya 429", count)
+	}
+}
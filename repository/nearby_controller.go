@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"transport-app/geo"
+	"transport-app/models"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultNearbyRadiusM = 1000
+	maxNearbyRadiusM     = 50000
+	defaultNearbyLimit   = 20
+	maxNearbyLimit       = 100
+	// nearbyHalteRute adalah jumlah halte terdekat yang diperiksa saat
+	// mencari rute, cukup untuk kota dengan halte yang rapat
+	nearbyHalteRute = 500
+)
+
+// nearbyQuery membaca lat, lng, radius (meter) dan limit dari query string
+func nearbyQuery(c *fiber.Ctx) (lat, lng, radius float64, limit int, msg string) {
+	lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, errLng := strconv.ParseFloat(c.Query("lng"), 64)
+	if errLat != nil || errLng != nil {
+		return 0, 0, 0, 0, "lat dan lng wajib diisi dengan angka"
+	}
+	if !geo.ValidLatLng(lat, lng) {
+		return 0, 0, 0, 0, "Koordinat tidak valid"
+	}
+
+	radius = defaultNearbyRadiusM
+	if v := c.Query("radius"); v != "" {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil || r <= 0 || r > maxNearbyRadiusM {
+			return 0, 0, 0, 0, fmt.Sprintf("radius harus antara 0 dan %d meter", maxNearbyRadiusM)
+		}
+		radius = r
+	}
+
+	limit = c.QueryInt("limit", defaultNearbyLimit)
+	if limit <= 0 || limit > maxNearbyLimit {
+		limit = defaultNearbyLimit
+	}
+	return lat, lng, radius, limit, ""
+}
+
+// GetNearbyHalte godoc
+// @Summary Find nearby halte
+// @Description Mencari halte dalam radius tertentu dari sebuah titik, diurutkan dari yang terdekat. Jarak dalam meter
+// @Tags Halte
+// @Produce json
+// @Param lat query number true "Lintang titik (derajat desimal)"
+// @Param lng query number true "Bujur titik (derajat desimal)"
+// @Param radius query number false "Radius dalam meter (default 1000, maks 50000)"
+// @Param limit query int false "Jumlah hasil maksimal (default 20, maks 100)"
+// @Success 200 {array} models.HalteTerdekat "Halte terdekat"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/haltes/nearby [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+func (h *Handler) GetNearbyHalte(c *fiber.Ctx) error {
+	lat, lng, radius, limit, msg := nearbyQuery(c)
+	if msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	haltes, err := h.Store.Halte.Nearby(ctx, lat, lng, radius, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(haltes)
+}
+
+// GetNearbyRute godoc
+// @Summary Find rute passing nearby
+// @Description Mencari rute yang melewati halte dalam radius tertentu dari sebuah titik. Setiap rute disertai halte terdekatnya dan diurutkan dari yang terdekat. Jarak dalam meter
+// @Tags Rute
+// @Produce json
+// @Param lat query number true "Lintang titik (derajat desimal)"
+// @Param lng query number true "Bujur titik (derajat desimal)"
+// @Param radius query number false "Radius dalam meter (default 1000, maks 50000)"
+// @Param limit query int false "Jumlah hasil maksimal (default 20, maks 100)"
+// @Success 200 {array} models.RuteTerdekat "Rute terdekat"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/rutes/nearby [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+func (h *Handler) GetNearbyRute(c *fiber.Ctx) error {
+	lat, lng, radius, limit, msg := nearbyQuery(c)
+	if msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	haltes, err := h.Store.Halte.Nearby(ctx, lat, lng, radius, nearbyHalteRute)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	ids := make([]primitive.ObjectID, len(haltes))
+	byID := make(map[primitive.ObjectID]models.HalteTerdekat, len(haltes))
+	for i, halte := range haltes {
+		ids[i] = halte.ID
+		byID[halte.ID] = halte
+	}
+
+	rutes, err := h.Store.Rute.FindByHalte(ctx, ids)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	result := []models.RuteTerdekat{}
+	for _, rute := range rutes {
+		var nearest *models.HalteTerdekat
+		for _, stop := range rute.Halte {
+			if halte, ok := byID[stop.HalteID]; ok && (nearest == nil || halte.JarakM < nearest.JarakM) {
+				nearest = &halte
+			}
+		}
+		if nearest == nil {
+			continue
+		}
+		result = append(result, models.RuteTerdekat{Rute: rute, Halte: nearest.Halte, JarakM: nearest.JarakM})
+	}
+	sort.SliceStable(result, func(a, b int) bool { return result[a].JarakM < result[b].JarakM })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return c.JSON(result)
+}
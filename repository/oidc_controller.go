@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"transport-app/models"
+	"transport-app/oidc"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	oidcFlowCookie = "oidc_flow"
+	oidcFlowType   = "oidc_flow"
+	oidcFlowTTL    = 10 * time.Minute
+)
+
+// OIDCLinkResponse berisi URL halaman login identity provider untuk
+// menghubungkan akun
+type OIDCLinkResponse struct {
+	URL string `json:"url"`
+}
+
+// oidcDefaultRole membaca OIDC_DEFAULT_ROLE, role untuk user yang dibuat
+// saat pertama kali login lewat identity provider
+func oidcDefaultRole() string {
+	if role := os.Getenv("OIDC_DEFAULT_ROLE"); role != "" {
+		return role
+	}
+	return models.RoleUser
+}
+
+// oidcFlow adalah isi cookie oidc_flow. LinkUserID diisi jika flow dimulai
+// dari /api/me/oidc untuk menghubungkan identitas ke akun yang sedang login.
+type oidcFlow struct {
+	oidc.Flow
+	LinkUserID string
+}
+
+// signFlowToken menyimpan state, nonce dan code verifier di cookie yang
+// ditandatangani sehingga server tidak perlu menyimpan apa pun sampai callback
+func (h *Handler) signFlowToken(f oidcFlow) (string, error) {
+	claims := jwt.MapClaims{
+		"typ":      oidcFlowType,
+		"state":    f.State,
+		"nonce":    f.Nonce,
+		"verifier": f.Verifier,
+		"exp":      time.Now().Add(oidcFlowTTL).Unix(),
+	}
+	if f.LinkUserID != "" {
+		claims["link_user_id"] = f.LinkUserID
+	}
+	return h.Keys.Sign(claims)
+}
+
+func (h *Handler) parseFlowToken(raw string) (oidcFlow, error) {
+	token, err := jwt.Parse(raw, h.Keys.KeyFunc)
+	if err != nil {
+		return oidcFlow{}, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != oidcFlowType {
+		return oidcFlow{}, errors.New("bukan token login OIDC")
+	}
+	var f oidcFlow
+	f.State, _ = claims["state"].(string)
+	f.Nonce, _ = claims["nonce"].(string)
+	f.Verifier, _ = claims["verifier"].(string)
+	f.LinkUserID, _ = claims["link_user_id"].(string)
+	return f, nil
+}
+
+// startOIDCFlow membuat flow baru, menyimpannya di cookie oidc_flow dan
+// mengembalikan URL halaman login identity provider, atau status dan pesan
+// error untuk client
+func (h *Handler) startOIDCFlow(c *fiber.Ctx, linkUserID string) (string, int, string) {
+	flow, err := oidc.NewFlow()
+	if err != nil {
+		return "", fiber.StatusInternalServerError, err.Error()
+	}
+	token, err := h.signFlowToken(oidcFlow{Flow: flow, LinkUserID: linkUserID})
+	if err != nil {
+		return "", fiber.StatusInternalServerError, "Gagal membuat token autentikasi"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	url, err := h.OIDC.AuthCodeURL(ctx, flow)
+	if err != nil {
+		fmt.Println("❌ Gagal menghubungi identity provider:", err)
+		return "", fiber.StatusBadGateway, "Identity provider tidak bisa dihubungi"
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcFlowCookie,
+		Value:    token,
+		Path:     "/api/login/oidc",
+		Expires:  time.Now().Add(oidcFlowTTL),
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return url, 0, ""
+}
+
+// oidcUsername memilih username untuk user baru dari claim ID token dan
+// menambahkan akhiran acak jika sudah dipakai
+func (h *Handler) oidcUsername(ctx context.Context, claims oidc.Claims) (string, error) {
+	base := strings.TrimSpace(claims.PreferredUsername)
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	username := base
+	for i := 0; i < 5; i++ {
+		_, err := h.Store.User.GetByUsername(ctx, username)
+		if err == store.ErrNotFound {
+			return username, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		suffix, err := randomToken(3)
+		if err != nil {
+			return "", err
+		}
+		username = base + "-" + strings.ToLower(suffix)
+	}
+	return "", errors.New("gagal membuat username unik")
+}
+
+// errOIDCEmailTaken dikembalikan jika email dari identity provider sudah
+// dipakai akun lokal. Akun tidak pernah dihubungkan otomatis berdasarkan
+// email karena siapa pun yang menguasai email itu di provider bisa
+// mengambil alih akun; pemiliknya harus menghubungkan lewat /api/me/oidc.
+var errOIDCEmailTaken = errors.New("email sudah terdaftar")
+
+// oidcUser mencari user untuk identitas eksternal. Jika belum ada, user
+// baru dibuat dengan role default tanpa password.
+func (h *Handler) oidcUser(ctx context.Context, claims oidc.Claims) (models.User, error) {
+	identity := models.ExternalIdentity{Issuer: h.OIDC.Issuer(), Subject: claims.Subject}
+
+	user, err := h.Store.User.GetByOIDC(ctx, identity.Issuer, identity.Subject)
+	if err != store.ErrNotFound {
+		return user, err
+	}
+
+	if claims.Email != "" {
+		_, err := h.Store.User.GetByEmail(ctx, claims.Email)
+		if err == nil {
+			return models.User{}, errOIDCEmailTaken
+		}
+		if err != store.ErrNotFound {
+			return models.User{}, err
+		}
+	}
+
+	role := oidcDefaultRole()
+	if _, err := h.Store.Role.GetByName(ctx, role); err != nil {
+		return models.User{}, fmt.Errorf("role default OIDC %q: %w", role, err)
+	}
+	username, err := h.oidcUsername(ctx, claims)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	// Password kosong tidak pernah cocok dengan bcrypt sehingga user ini
+	// hanya bisa login lewat identity provider sampai mengatur password
+	user = models.User{
+		Username:      username,
+		Email:         claims.Email,
+		Role:          role,
+		EmailVerified: claims.Email != "" && claims.EmailVerified,
+		OIDC:          &identity,
+	}
+	err = h.Store.User.Create(ctx, &user)
+	if err == store.ErrDuplicate {
+		// Email dipakai akun yang dibuat bersamaan dengan login ini
+		return models.User{}, errOIDCEmailTaken
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	fmt.Println("✅ User dibuat dari login OIDC:", user.Username)
+	return user, nil
+}
+
+// OIDCLogin godoc
+// @Summary Start an OpenID Connect login
+// @Description Mengarahkan browser ke halaman login identity provider (authorization code flow dengan PKCE). State, nonce dan code verifier disimpan di cookie oidc_flow sampai callback
+// @Tags Auth
+// @Success 302 "Redirect ke identity provider"
+// @Failure 404 {object} models.ErrorResponse "Login OIDC tidak dikonfigurasi"
+// @Failure 502 {object} models.ErrorResponse "Identity provider tidak bisa dihubungi"
+// @Router /api/login/oidc [get]
+func (h *Handler) OIDCLogin(c *fiber.Ctx) error {
+	if h.OIDC == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Login OIDC tidak dikonfigurasi"})
+	}
+
+	url, status, msg := h.startOIDCFlow(c, "")
+	if msg != "" {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	return c.Redirect(url, fiber.StatusFound)
+}
+
+// LinkOIDC godoc
+// @Summary Start linking my account to the identity provider
+// @Description Memulai login ke identity provider untuk menghubungkan identitasnya ke akun yang sedang login. Buka url yang dikembalikan di browser yang sama; callback /api/login/oidc/callback menghubungkan akun lalu login berikutnya lewat identity provider masuk ke akun ini
+// @Tags Profile
+// @Produce json
+// @Success 200 {object} OIDCLinkResponse "URL halaman login identity provider"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Login OIDC tidak dikonfigurasi"
+// @Failure 409 {object} models.ErrorResponse "Akun sudah terhubung dengan identity provider"
+// @Failure 502 {object} models.ErrorResponse "Identity provider tidak bisa dihubungi"
+// @Router /api/me/oidc [post]
+// @Security BearerAuth
+func (h *Handler) LinkOIDC(c *fiber.Ctx) error {
+	if h.OIDC == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Login OIDC tidak dikonfigurasi"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := h.currentUser(ctx, c)
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if user.OIDC != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Akun sudah terhubung dengan identity provider"})
+	}
+
+	url, status, msg := h.startOIDCFlow(c, user.ID.Hex())
+	if msg != "" {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	return c.JSON(OIDCLinkResponse{URL: url})
+}
+
+// linkOIDC menyelesaikan flow dari /api/me/oidc dengan menghubungkan
+// identitas ke user yang memulai flow
+func (h *Handler) linkOIDC(ctx context.Context, c *fiber.Ctx, userID string, claims oidc.Claims) error {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "State tidak valid atau kedaluwarsa, silakan login ulang"})
+	}
+	user, err := h.Store.User.Get(ctx, id)
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if user.Disabled {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Akun dinonaktifkan"})
+	}
+	if user.OIDC != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Akun sudah terhubung dengan identity provider"})
+	}
+
+	identity := models.ExternalIdentity{Issuer: h.OIDC.Issuer(), Subject: claims.Subject}
+	err = h.Store.User.SetOIDC(ctx, user.ID, identity)
+	if err == store.ErrDuplicate {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Identitas ini sudah terhubung dengan akun lain"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	fmt.Println("✅ Akun dihubungkan dengan identity provider:", user.Username)
+	return c.JSON(fiber.Map{"message": "Akun berhasil dihubungkan dengan identity provider"})
+}
+
+// OIDCCallback godoc
+// @Summary Complete an OpenID Connect login
+// @Description Callback dari identity provider. Authorization code ditukar dengan ID token lalu identitas dipetakan ke user (dibuat saat login pertama dengan role OIDC_DEFAULT_ROLE). Pengecekan akun sama dengan /api/login: user dengan 2FA mendapat challenge token untuk /api/login/2fa, selain itu access token dan refresh token dikembalikan. Akun lokal dengan email yang sama tidak dihubungkan otomatis; hubungkan lewat /api/me/oidc. Jika flow dimulai dari /api/me/oidc, identitas dihubungkan ke akun tersebut
+// @Tags Auth
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State dari /api/login/oidc"
+// @Success 200 {object} LoginResponse "Login berhasil"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Login ditolak identity provider atau state tidak valid"
+// @Failure 403 {object} models.ErrorResponse "Email belum diverifikasi, akun dinonaktifkan atau password harus direset"
+// @Failure 404 {object} models.ErrorResponse "Login OIDC tidak dikonfigurasi"
+// @Failure 409 {object} models.ErrorResponse "Email atau identitas sudah dipakai akun lain"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/login/oidc/callback [get]
+func (h *Handler) OIDCCallback(c *fiber.Ctx) error {
+	if h.OIDC == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Login OIDC tidak dikonfigurasi"})
+	}
+
+	// Cookie hanya dipakai sekali, berhasil atau tidak
+	c.Cookie(&fiber.Cookie{Name: oidcFlowCookie, Path: "/api/login/oidc", Expires: time.Unix(0, 0), HTTPOnly: true})
+
+	if e := c.Query("error"); e != "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Login ditolak identity provider: " + e})
+	}
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "code dan state wajib diisi"})
+	}
+
+	flow, err := h.parseFlowToken(c.Cookies(oidcFlowCookie))
+	if err != nil || subtle.ConstantTimeCompare([]byte(flow.State), []byte(state)) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "State tidak valid atau kedaluwarsa, silakan login ulang"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	claims, err := h.OIDC.Exchange(ctx, code, flow.Flow)
+	if err != nil {
+		fmt.Println("❌ Login OIDC gagal:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Login OIDC gagal"})
+	}
+
+	if flow.LinkUserID != "" {
+		return h.linkOIDC(ctx, c, flow.LinkUserID, claims)
+	}
+
+	user, err := h.oidcUser(ctx, claims)
+	if err == errOIDCEmailTaken {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Email sudah dipakai akun lain, login dengan password lalu hubungkan akun dari profil"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return h.finishLogin(ctx, c, user, claims.MFA())
+}
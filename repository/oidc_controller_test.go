@@ -0,0 +1,317 @@
+package repository_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"transport-app/models"
+	"transport-app/oidc"
+	"transport-app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const oidcClientID = "transport-app"
+
+// stubProvider adalah identity provider lokal dengan discovery document,
+// JWKS dan token endpoint. Halaman login diganti authorize yang langsung
+// menerbitkan code untuk claims yang diminta test.
+type stubProvider struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]stubCode
+}
+
+type stubCode struct {
+	claims    jwt.MapClaims
+	challenge string
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &stubProvider{key: key, codes: map[string]stubCode{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.srv.URL,
+			"authorization_endpoint": p.srv.URL + "/authorize",
+			"token_endpoint":         p.srv.URL + "/token",
+			"jwks_uri":               p.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		pub := p.key.PublicKey
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"kid": "k1",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", p.token)
+	p.srv = httptest.NewServer(mux)
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+func (p *stubProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p.mu.Lock()
+	code, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != code.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, code.claims)
+	token.Header["kid"] = "k1"
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+// authorize meniru user yang login di provider: code diterbitkan untuk
+// subject dan claims tambahan, dengan nonce dan code challenge dari URL
+func (p *stubProvider) authorize(t *testing.T, authURL, subject string, extra jwt.MapClaims) url.Values {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("client_id") != oidcClientID || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("URL login tidak valid: %s", authURL)
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   p.srv.URL,
+		"aud":   oidcClientID,
+		"sub":   subject,
+		"nonce": q.Get("nonce"),
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(5 * time.Minute).Unix(),
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+
+	code := "code-" + subject + "-" + q.Get("state")[:8]
+	p.mu.Lock()
+	p.codes[code] = stubCode{claims: claims, challenge: q.Get("code_challenge")}
+	p.mu.Unlock()
+	return url.Values{"code": {code}, "state": {q.Get("state")}}
+}
+
+func newOIDCTestServer(t *testing.T) (*testServer, *stubProvider) {
+	t.Helper()
+	s := newTestServer(t)
+	p := newStubProvider(t)
+	s.h.OIDC = oidc.New(oidc.Config{
+		Issuer:      p.srv.URL,
+		ClientID:    oidcClientID,
+		RedirectURL: "http://app.test/api/login/oidc/callback",
+		Scopes:      []string{"openid", "email", "profile"},
+	})
+	return s, p
+}
+
+// finishOIDC login di provider untuk flow yang dimulai start (redirect
+// /api/login/oidc atau JSON /api/me/oidc) lalu memanggil callback dengan
+// cookie flow dari start
+func (s *testServer) finishOIDC(t *testing.T, p *stubProvider, start *http.Response, subject string, claims jwt.MapClaims) *http.Response {
+	t.Helper()
+	authURL := start.Header.Get(fiber.HeaderLocation)
+	if authURL == "" {
+		var link repository.OIDCLinkResponse
+		s.expect(t, start, http.StatusOK, &link)
+		authURL = link.URL
+	}
+	var flow *http.Cookie
+	for _, cookie := range start.Cookies() {
+		if cookie.Name == "oidc_flow" {
+			flow = cookie
+		}
+	}
+	if flow == nil {
+		t.Fatal("cookie oidc_flow tidak diset")
+	}
+
+	params := p.authorize(t, authURL, subject, claims)
+	req := httptest.NewRequest(http.MethodGet, "/api/login/oidc/callback?"+params.Encode(), nil)
+	req.AddCookie(flow)
+	res, err := s.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// oidcLogin menjalankan login OIDC lengkap untuk subject
+func (s *testServer) oidcLogin(t *testing.T, p *stubProvider, subject string, claims jwt.MapClaims) *http.Response {
+	t.Helper()
+	start := s.request(t, http.MethodGet, "/api/login/oidc", "", nil)
+	if start.StatusCode != http.StatusFound {
+		s.expect(t, start, http.StatusFound, nil)
+	}
+	return s.finishOIDC(t, p, start, subject, claims)
+}
+
+func (s *testServer) me(t *testing.T, token string) models.PublicUser {
+	t.Helper()
+	var me models.PublicUser
+	s.expect(t, s.request(t, http.MethodGet, "/api/me", token, nil), http.StatusOK, &me)
+	return me
+}
+
+func TestOIDCLogin(t *testing.T) {
+	s, p := newOIDCTestServer(t)
+	claims := jwt.MapClaims{"email": "andi@corp.test", "email_verified": true, "preferred_username": "andi"}
+
+	var first repository.LoginResponse
+	s.expect(t, s.oidcLogin(t, p, "sub-andi", claims), http.StatusOK, &first)
+	me := s.me(t, first.Token)
+	if me.Username != "andi" || me.Role != models.RoleUser || !me.EmailVerified {
+		t.Fatalf("user OIDC baru tidak sesuai: %+v", me)
+	}
+
+	var second repository.LoginResponse
+	s.expect(t, s.oidcLogin(t, p, "sub-andi", claims), http.StatusOK, &second)
+	if again := s.me(t, second.Token); again.ID != me.ID {
+		t.Fatalf("login kedua masuk ke user %s, seharusnya %s", again.ID.Hex(), me.ID.Hex())
+	}
+
+	t.Run("state tidak cocok", func(t *testing.T) {
+		start := s.request(t, http.MethodGet, "/api/login/oidc", "", nil)
+		params := p.authorize(t, start.Header.Get(fiber.HeaderLocation), "sub-andi", claims)
+		req := httptest.NewRequest(http.MethodGet, "/api/login/oidc/callback?code="+params.Get("code")+"&state=palsu", nil)
+		req.AddCookie(start.Cookies()[0])
+		res, err := s.app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.expect(t, res, http.StatusUnauthorized, nil)
+	})
+}
+
+// TestOIDCNoEmailLinking memastikan akun lokal tidak diambil alih hanya
+// karena email di identity provider sama
+func TestOIDCNoEmailLinking(t *testing.T) {
+	s, p := newOIDCTestServer(t)
+	local := s.createUser(t, "budi", models.RoleAdmin)
+
+	res := s.oidcLogin(t, p, "sub-penyerang", jwt.MapClaims{"email": local.Email, "email_verified": true})
+	s.expect(t, res, http.StatusConflict, nil)
+
+	if _, err := s.store.User.GetByOIDC(context.Background(), p.srv.URL, "sub-penyerang"); err == nil {
+		t.Fatal("identitas OIDC dihubungkan ke akun lokal tanpa persetujuan pemiliknya")
+	}
+}
+
+func TestOIDCLink(t *testing.T) {
+	s, p := newOIDCTestServer(t)
+	local := s.createUser(t, "budi", models.RoleOperator)
+	s.createUser(t, "siti", models.RoleUser)
+	token := s.login(t, "budi")
+	claims := jwt.MapClaims{"email": local.Email, "email_verified": true}
+
+	t.Run("tanpa session", func(t *testing.T) {
+		s.expect(t, s.request(t, http.MethodPost, "/api/me/oidc", "", nil), http.StatusBadRequest, nil)
+	})
+
+	start := s.request(t, http.MethodPost, "/api/me/oidc", token, nil)
+	s.expect(t, s.finishOIDC(t, p, start, "sub-budi", claims), http.StatusOK, nil)
+
+	var res repository.LoginResponse
+	s.expect(t, s.oidcLogin(t, p, "sub-budi", claims), http.StatusOK, &res)
+	if me := s.me(t, res.Token); me.ID != local.ID || me.Role != models.RoleOperator {
+		t.Fatalf("login OIDC masuk ke %+v, seharusnya akun budi", me)
+	}
+
+	t.Run("akun sudah terhubung", func(t *testing.T) {
+		s.expect(t, s.request(t, http.MethodPost, "/api/me/oidc", token, nil), http.StatusConflict, nil)
+	})
+
+	t.Run("identitas sudah dipakai akun lain", func(t *testing.T) {
+		start := s.request(t, http.MethodPost, "/api/me/oidc", s.login(t, "siti"), nil)
+		s.expect(t, s.finishOIDC(t, p, start, "sub-budi", claims), http.StatusConflict, nil)
+	})
+}
+
+// TestOIDCLoginChecks memastikan callback OIDC menerapkan pengecekan akun
+// yang sama dengan /api/login
+func TestOIDCLoginChecks(t *testing.T) {
+	s, p := newOIDCTestServer(t)
+	ctx := context.Background()
+
+	link := func(t *testing.T, username, subject string) models.User {
+		t.Helper()
+		user := s.createUser(t, username, models.RoleUser)
+		start := s.request(t, http.MethodPost, "/api/me/oidc", s.login(t, username), nil)
+		s.expect(t, s.finishOIDC(t, p, start, subject, nil), http.StatusOK, nil)
+		return user
+	}
+
+	t.Run("2FA", func(t *testing.T) {
+		user := link(t, "dua-faktor", "sub-2fa")
+		if err := s.store.User.SetTOTP(ctx, user.ID, models.TOTP{Secret: "JBSWY3DPEHPK3PXP", Enabled: true}); err != nil {
+			t.Fatal(err)
+		}
+		var challenge repository.TwoFactorChallengeResponse
+		s.expect(t, s.oidcLogin(t, p, "sub-2fa", nil), http.StatusOK, &challenge)
+		if !challenge.MFARequired || challenge.ChallengeToken == "" {
+			t.Fatalf("login OIDC melewati 2FA: %+v", challenge)
+		}
+	})
+
+	t.Run("password harus direset", func(t *testing.T) {
+		user := link(t, "reset", "sub-reset")
+		if err := s.store.User.SetMustResetPassword(ctx, user.ID, true); err != nil {
+			t.Fatal(err)
+		}
+		s.expect(t, s.oidcLogin(t, p, "sub-reset", nil), http.StatusForbidden, nil)
+	})
+
+	t.Run("akun dinonaktifkan", func(t *testing.T) {
+		user := link(t, "nonaktif", "sub-nonaktif")
+		if err := s.store.User.SetDisabled(ctx, user.ID, true); err != nil {
+			t.Fatal(err)
+		}
+		s.expect(t, s.oidcLogin(t, p, "sub-nonaktif", nil), http.StatusForbidden, nil)
+	})
+
+	t.Run("email belum diverifikasi", func(t *testing.T) {
+		t.Setenv("REQUIRE_EMAIL_VERIFICATION", "login")
+		s.expect(t, s.oidcLogin(t, p, "sub-baru", jwt.MapClaims{"email": "baru@corp.test", "email_verified": false}), http.StatusForbidden, nil)
+	})
+}
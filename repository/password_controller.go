@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"transport-app/mailer"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	passwordResetTTL = time.Hour
+	// forgotPasswordJeda adalah jarak minimal antar email reset ke satu user
+	forgotPasswordJeda = time.Minute
+	// forgotPasswordIPLimit adalah batas permintaan reset dari satu IP
+	// dalam forgotPasswordWindow
+	forgotPasswordIPLimit = 10
+	forgotPasswordWindow  = time.Hour
+)
+
+func forgotPasswordIPKey(ip string) string {
+	return "forgot:ip:" + ip
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token                string `json:"token"`
+	Password             string `json:"password"`
+	PasswordConfirmation string `json:"password_confirmation"`
+}
+
+// appLink membangun link ke frontend (APP_URL) dengan token pada query
+// string. Jika APP_URL kosong, hanya token yang dikembalikan.
+func appLink(path, token string) string {
+	base := strings.TrimRight(os.Getenv("APP_URL"), "/")
+	if base == "" {
+		return token
+	}
+	return base + path + "?token=" + url.QueryEscape(token)
+}
+
+// issueUserToken membuat token sekali pakai baru untuk user dan menghapus
+// token lama dengan tujuan yang sama. Token asli hanya dikembalikan untuk
+// dikirim lewat email.
+func (h *Handler) issueUserToken(ctx context.Context, userID primitive.ObjectID, purpose string, ttl time.Duration) (string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	if err := h.Store.UserToken.DeleteForUser(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	return token, h.Store.UserToken.Create(ctx, &models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Mengirim link reset password ke email user. Response selalu sama walaupun email tidak terdaftar agar tidak bisa dipakai untuk menebak akun
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Email akun"
+// @Success 200 {object} models.SuccessResponse "Link reset dikirim jika email terdaftar"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 429 {object} models.ErrorResponse "Terlalu banyak permintaan reset password dari IP ini"
+// @Router /api/password/forgot [post]
+func (h *Handler) ForgotPassword(c *fiber.Ctx) error {
+	var input ForgotPasswordRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	if !isEmailValid(input.Email) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Format email tidak valid"})
+	}
+
+	response := fiber.Map{"message": "Jika email terdaftar, link reset password sudah dikirim"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Setiap permintaan dihitung per IP, terdaftar atau tidak emailnya,
+	// agar endpoint ini tidak bisa dipakai untuk membanjiri kotak masuk
+	now := time.Now()
+	attempt, err := h.Store.LoginAttempt.RecordFailure(ctx, forgotPasswordIPKey(c.IP()), now, forgotPasswordWindow)
+	if err != nil {
+		fmt.Println("❌ Gagal mencatat permintaan reset password:", err)
+	} else if attempt.Failures > forgotPasswordIPLimit {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(forgotPasswordWindow.Seconds())))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "Terlalu banyak permintaan reset password, coba lagi nanti"})
+	}
+
+	user, err := h.Store.User.GetByEmail(ctx, input.Email)
+	if err == store.ErrNotFound {
+		return c.JSON(response)
+	}
+	if err != nil {
+		fmt.Println("❌ Error saat mencari user:", err)
+		return c.JSON(response)
+	}
+
+	// Token dan email dibuat di latar belakang agar lama response sama untuk
+	// email yang terdaftar dan yang tidak
+	h.goBackground("mengirim email reset password", func(ctx context.Context) error {
+		// Batas per user tidak mengembalikan 429 karena itu akan membocorkan
+		// bahwa email terdaftar; email berikutnya cukup tidak dikirim
+		last, err := h.Store.UserToken.Latest(ctx, user.ID, models.TokenPurposePasswordReset)
+		if err == nil && now.Sub(last.CreatedAt) < forgotPasswordJeda {
+			return nil
+		}
+		return h.sendPasswordResetEmail(ctx, user, "Abaikan email ini jika Anda tidak meminta reset password.")
+	})
+
+	return c.JSON(response)
+}
+
+// sendPasswordResetEmail membuat token reset password baru dan mengirimnya
+// ke email user. note ditambahkan di akhir isi email.
+func (h *Handler) sendPasswordResetEmail(ctx context.Context, user models.User, note string) error {
+	token, err := h.issueUserToken(ctx, user.ID, models.TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return h.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password",
+		Body: fmt.Sprintf("Halo %s,\n\nGunakan link atau token berikut untuk mengatur ulang password Anda. "+
+			"Token berlaku selama 1 jam dan hanya bisa dipakai sekali.\n\n%s\n\n%s\n",
+			user.Username, appLink("/reset-password", token), note),
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset a password
+// @Description Mengganti password memakai token dari email. Token hanya berlaku sekali dan semua session user dicabut
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "Token dan password baru"
+// @Success 200 {object} models.SuccessResponse "Password berhasil diubah"
+// @Failure 400 {object} models.ErrorResponse "Bad Request atau token tidak valid"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/password/reset [post]
+func (h *Handler) ResetPassword(c *fiber.Ctx) error {
+	var input ResetPasswordRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	if input.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Token wajib diisi"})
+	}
+	if msg := validatePassword(input.Password, input.PasswordConfirmation); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": msg})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Token dipakai lebih dulu agar request dengan token palsu tidak sampai
+	// ke bcrypt yang mahal
+	token, err := h.Store.UserToken.Consume(ctx, models.TokenPurposePasswordReset, hashToken(input.Token))
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Token tidak valid atau sudah kedaluwarsa"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	hashedPassword, err := hashPassword(input.Password)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengenkripsi password, minta link reset baru"})
+	}
+
+	if err := h.Store.User.UpdatePassword(ctx, token.UserID, hashedPassword); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengubah password"})
+	}
+
+	// Semua perangkat yang masih login harus login ulang dengan password baru
+	if err := h.Store.Session.RevokeAllForUser(ctx, token.UserID, models.SessionRevokedReset); err != nil {
+		fmt.Println("❌ Gagal mencabut session:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mencabut session"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Password berhasil diubah, silakan login ulang"})
+}
@@ -0,0 +1,120 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"transport-app/mailer"
+	"transport-app/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestResetPassword(t *testing.T) {
+	t.Setenv("APP_URL", "http://app.test")
+	s := newTestServer(t)
+	s.createUser(t, "budi", models.RoleUser)
+	session := s.login(t, "budi")
+
+	s.expect(t, s.request(t, http.MethodPost, "/api/password/forgot", "", fiber.Map{"email": "budi@example.com"}), http.StatusOK, nil)
+	token := s.mailer.lastToken(t)
+
+	baru := "password-baru-123"
+	reset := func(token, password string) *http.Response {
+		return s.request(t, http.MethodPost, "/api/password/reset", "", fiber.Map{"token": token, "password": password, "password_confirmation": password})
+	}
+
+	t.Run("token palsu", func(t *testing.T) {
+		s.expect(t, reset("token-palsu", baru), http.StatusBadRequest, nil)
+	})
+
+	t.Run("password terlalu pendek tidak memakai token", func(t *testing.T) {
+		s.expect(t, reset(token, "pendek"), http.StatusBadRequest, nil)
+	})
+
+	t.Run("berhasil", func(t *testing.T) {
+		s.expect(t, reset(token, baru), http.StatusOK, nil)
+
+		s.expect(t, s.request(t, http.MethodGet, "/api/me", session, nil), http.StatusUnauthorized, nil)
+		s.expect(t, s.request(t, http.MethodPost, "/api/login", "", fiber.Map{"username": "budi", "password": testPassword}), http.StatusUnauthorized, nil)
+		s.expect(t, s.request(t, http.MethodPost, "/api/login", "", fiber.Map{"username": "budi", "password": baru}), http.StatusOK, nil)
+	})
+
+	t.Run("token hanya sekali pakai", func(t *testing.T) {
+		s.expect(t, reset(token, "password-lain-456"), http.StatusBadRequest, nil)
+	})
+}
+
+func TestForgotPasswordThrottle(t *testing.T) {
+	t.Run("per user", func(t *testing.T) {
+		s := newTestServer(t)
+		s.createUser(t, "budi", models.RoleUser)
+
+		for i := 0; i < 3; i++ {
+			s.expect(t, s.request(t, http.MethodPost, "/api/password/forgot", "", fiber.Map{"email": "budi@example.com"}), http.StatusOK, nil)
+		}
+		if n := s.mailer.count(); n != 1 {
+			t.Fatalf("%d email reset dikirim, seharusnya 1", n)
+		}
+	})
+
+	t.Run("per IP", func(t *testing.T) {
+		s := newTestServer(t)
+		for i := 0; i < 10; i++ {
+			email := fmt.Sprintf("user%d@example.com", i)
+			s.expect(t, s.request(t, http.MethodPost, "/api/password/forgot", "", fiber.Map{"email": email}), http.StatusOK, nil)
+		}
+		res := s.request(t, http.MethodPost, "/api/password/forgot", "", fiber.Map{"email": "lain@example.com"})
+		if res.Header.Get(fiber.HeaderRetryAfter) == "" {
+			t.Error("response 429 tanpa Retry-After")
+		}
+		s.expect(t, res, http.StatusTooManyRequests, nil)
+	})
+}
+
+// blockingMailer menahan setiap email sampai release ditutup
+type blockingMailer struct {
+	release chan struct{}
+	sent    int32
+}
+
+func (m *blockingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	<-m.release
+	atomic.AddInt32(&m.sent, 1)
+	return nil
+}
+
+// TestForgotPasswordAsync memastikan response tidak menunggu email dikirim,
+// sehingga lamanya tidak membedakan email yang terdaftar
+func TestForgotPasswordAsync(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "budi", models.RoleUser)
+	m := &blockingMailer{release: make(chan struct{})}
+	s.h.Mailer = m
+
+	done := make(chan int, 1)
+	go func() {
+		res := s.request(t, http.MethodPost, "/api/password/forgot", "", fiber.Map{"email": "budi@example.com"})
+		res.Body.Close()
+		done <- res.StatusCode
+	}()
+	select {
+	case status := <-done:
+		if status != http.StatusOK {
+			t.Fatalf("status %d, seharusnya 200", status)
+		}
+	case <-time.After(5 * time.Second):
+		close(m.release)
+		t.Fatal("response menunggu email terkirim")
+	}
+
+	close(m.release)
+	s.h.Wait()
+	if n := atomic.LoadInt32(&m.sent); n != 1 {
+		t.Fatalf("%d email reset dikirim, seharusnya 1", n)
+	}
+}
@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"transport-app/models"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultMaksTransit      = 2
+	maxMaksTransit          = 4
+	defaultMinTransitMenit  = 15
+	defaultPlannerLimit     = 10
+	maxPlannerLimit         = 50
+	maxPlannerExploredPaths = 20000
+)
+
+type ItineraryLeg struct {
+	JadwalID       string    `json:"jadwal_id"`
+	KodeRute       string    `json:"kode_rute"`
+	Asal           string    `json:"asal"`
+	Tujuan         string    `json:"tujuan"`
+	WaktuBerangkat time.Time `json:"waktu_berangkat"`
+	EstimasiTiba   time.Time `json:"estimasi_tiba"`
+	JarakKM        int       `json:"jarak_km"`
+	TransitMenit   int       `json:"transit_menit"` // Waktu tunggu sebelum leg ini
+}
+
+type Itinerary struct {
+	Legs           []ItineraryLeg `json:"legs"`
+	JumlahTransit  int            `json:"jumlah_transit"`
+	TotalJarakKM   int            `json:"total_jarak_km"`
+	WaktuBerangkat time.Time      `json:"waktu_berangkat"`
+	WaktuTiba      time.Time      `json:"waktu_tiba"`
+	DurasiMenit    int            `json:"durasi_menit"`
+}
+
+type plannerOptions struct {
+	MaksTransit int
+	MinTransit  time.Duration
+	Limit       int
+}
+
+// plannerEdge adalah satu keberangkatan pada graf kota
+type plannerEdge struct {
+	jadwal models.Jadwal
+	rute   models.Rute
+}
+
+func cityKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// planItineraries mencari rangkaian perjalanan dari asal ke tujuan. Kota
+// adalah simpul dan setiap jadwal adalah sisi berarah dari Asal ke Tujuan
+// rutenya. Leg pertama harus berangkat pada tanggal yang diminta (menurut
+// zona waktu rutenya), leg berikutnya harus berangkat paling cepat
+// MinTransit setelah leg sebelumnya tiba. Hasil diurutkan berdasarkan waktu
+// tiba, lalu jumlah transit, lalu keberangkatan paling akhir.
+//
+// Penelusuran dipangkas dua cara: kota yang tidak punya jalan ke tujuan
+// dilewati, dan setelah Limit itinerary ditemukan, leg yang tiba setelah
+// itinerary ke-Limit tidak ditelusuri karena waktu tiba tidak pernah
+// berkurang sepanjang path. truncated bernilai true jika penelusuran tetap
+// berhenti di maxPlannerExploredPaths sehingga hasil mungkin tidak lengkap.
+func planItineraries(rutes []models.Rute, jadwals []models.Jadwal, asal, tujuan, tanggal string, opts plannerOptions) (results []Itinerary, truncated bool) {
+	ruteByID := map[primitive.ObjectID]models.Rute{}
+	for _, r := range rutes {
+		ruteByID[r.ID] = r
+	}
+
+	edges := map[string][]plannerEdge{}
+	for _, j := range jadwals {
+		r, ok := ruteByID[j.RuteID]
+		if !ok {
+			continue
+		}
+		key := cityKey(r.Asal)
+		edges[key] = append(edges[key], plannerEdge{jadwal: j, rute: r})
+	}
+	for key := range edges {
+		list := edges[key]
+		sort.Slice(list, func(a, b int) bool {
+			return list[a].jadwal.WaktuBerangkat.Before(list[b].jadwal.WaktuBerangkat)
+		})
+	}
+
+	target := cityKey(tujuan)
+	reaches := citiesReaching(edges, target)
+	results = []Itinerary{}
+	explored := 0
+
+	// arrivals berisi waktu tiba Limit itinerary tercepat, terurut
+	arrivals := []time.Time{}
+	addArrival := func(t time.Time) {
+		i := sort.Search(len(arrivals), func(i int) bool { return arrivals[i].After(t) })
+		arrivals = append(arrivals, time.Time{})
+		copy(arrivals[i+1:], arrivals[i:])
+		arrivals[i] = t
+		if len(arrivals) > opts.Limit {
+			arrivals = arrivals[:opts.Limit]
+		}
+	}
+
+	var walk func(city string, arrival time.Time, path []plannerEdge, visited map[string]bool)
+	walk = func(city string, arrival time.Time, path []plannerEdge, visited map[string]bool) {
+		if explored >= maxPlannerExploredPaths {
+			truncated = true
+			return
+		}
+		explored++
+
+		if city == target && len(path) > 0 {
+			it := buildItinerary(path)
+			results = append(results, it)
+			addArrival(it.WaktuTiba)
+			return
+		}
+		if len(path) > opts.MaksTransit {
+			return
+		}
+
+		for _, e := range edges[city] {
+			if len(path) == 0 {
+				loc := loadZonaWaktu(e.rute.ZonaWaktu)
+				if e.jadwal.WaktuBerangkat.In(loc).Format("2006-01-02") != tanggal {
+					continue
+				}
+			} else if e.jadwal.WaktuBerangkat.Before(arrival.Add(opts.MinTransit)) {
+				continue
+			}
+
+			if len(arrivals) == opts.Limit && e.jadwal.EstimasiTiba.After(arrivals[len(arrivals)-1]) {
+				continue
+			}
+
+			next := cityKey(e.rute.Tujuan)
+			if visited[next] || !reaches[next] {
+				continue
+			}
+
+			visited[next] = true
+			walk(next, e.jadwal.EstimasiTiba, append(path, e), visited)
+			delete(visited, next)
+		}
+	}
+
+	start := cityKey(asal)
+	walk(start, time.Time{}, nil, map[string]bool{start: true})
+
+	sort.SliceStable(results, func(a, b int) bool {
+		ra, rb := results[a], results[b]
+		if !ra.WaktuTiba.Equal(rb.WaktuTiba) {
+			return ra.WaktuTiba.Before(rb.WaktuTiba)
+		}
+		if ra.JumlahTransit != rb.JumlahTransit {
+			return ra.JumlahTransit < rb.JumlahTransit
+		}
+		return ra.WaktuBerangkat.After(rb.WaktuBerangkat)
+	})
+
+	if len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, truncated
+}
+
+// citiesReaching mengembalikan kota yang punya jalan ke target tanpa
+// memperhatikan waktu, termasuk target sendiri
+func citiesReaching(edges map[string][]plannerEdge, target string) map[string]bool {
+	incoming := map[string][]string{}
+	for from, list := range edges {
+		for _, e := range list {
+			to := cityKey(e.rute.Tujuan)
+			incoming[to] = append(incoming[to], from)
+		}
+	}
+
+	reaches := map[string]bool{target: true}
+	queue := []string{target}
+	for len(queue) > 0 {
+		city := queue[0]
+		queue = queue[1:]
+		for _, from := range incoming[city] {
+			if !reaches[from] {
+				reaches[from] = true
+				queue = append(queue, from)
+			}
+		}
+	}
+	return reaches
+}
+
+func buildItinerary(path []plannerEdge) Itinerary {
+	it := Itinerary{Legs: []ItineraryLeg{}, JumlahTransit: len(path) - 1}
+
+	var prevArrival time.Time
+	for i, e := range path {
+		j := e.jadwal
+		localizeJadwal(&j, loadZonaWaktu(e.rute.ZonaWaktu))
+
+		leg := ItineraryLeg{
+			JadwalID:       j.ID.Hex(),
+			KodeRute:       e.rute.KodeRute,
+			Asal:           e.rute.Asal,
+			Tujuan:         e.rute.Tujuan,
+			WaktuBerangkat: j.WaktuBerangkat,
+			EstimasiTiba:   j.EstimasiTiba,
+			JarakKM:        e.rute.JarakKM,
+		}
+		if i > 0 {
+			leg.TransitMenit = int(j.WaktuBerangkat.Sub(prevArrival).Minutes())
+		}
+		prevArrival = j.EstimasiTiba
+
+		it.Legs = append(it.Legs, leg)
+		it.TotalJarakKM += e.rute.JarakKM
+	}
+
+	it.WaktuBerangkat = it.Legs[0].WaktuBerangkat
+	it.WaktuTiba = it.Legs[len(it.Legs)-1].EstimasiTiba
+	it.DurasiMenit = int(it.WaktuTiba.Sub(it.WaktuBerangkat).Minutes())
+	return it
+}
+
+// PlanJourney godoc
+// @Summary Plan a journey with transfers
+// @Description Mencari rangkaian perjalanan dari kota asal ke tujuan, termasuk yang memerlukan transit, diurutkan berdasarkan waktu tiba. Jika jaringan terlalu besar untuk ditelusuri seluruhnya, header X-Results-Truncated bernilai true dan hasilnya mungkin tidak lengkap
+// @Tags Search
+// @Accept json
+// @Produce json
+// @Param asal query string true "Kota asal"
+// @Param tujuan query string true "Kota tujuan"
+// @Param tanggal query string true "Tanggal keberangkatan (YYYY-MM-DD)"
+// @Param maks_transit query int false "Maksimal jumlah transit (default 2, maks 4)"
+// @Param min_transit_menit query int false "Waktu transit minimal dalam menit (default 15)"
+// @Param limit query int false "Jumlah hasil maksimal (default 10, maks 50)"
+// @Success 200 {array} Itinerary "Daftar itinerary"
+// @Header 200 {boolean} X-Results-Truncated "true jika penelusuran dihentikan sebelum selesai"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/planner [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+func (h *Handler) PlanJourney(c *fiber.Ctx) error {
+	asal := c.Query("asal")
+	tujuan := c.Query("tujuan")
+	tanggal := c.Query("tanggal")
+
+	if asal == "" || tujuan == "" || tanggal == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "asal, tujuan dan tanggal wajib diisi"})
+	}
+	day, err := parseWithLayouts(tanggal, tanggalLayouts, time.UTC)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Format tanggal tidak valid"})
+	}
+
+	opts := plannerOptions{
+		MaksTransit: c.QueryInt("maks_transit", defaultMaksTransit),
+		MinTransit:  time.Duration(c.QueryInt("min_transit_menit", defaultMinTransitMenit)) * time.Minute,
+		Limit:       c.QueryInt("limit", defaultPlannerLimit),
+	}
+	if opts.MaksTransit < 0 || opts.MaksTransit > maxMaksTransit {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("maks_transit harus antara 0 dan %d", maxMaksTransit)})
+	}
+	if opts.MinTransit < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "min_transit_menit tidak boleh negatif"})
+	}
+	if opts.Limit <= 0 || opts.Limit > maxPlannerLimit {
+		opts.Limit = defaultPlannerLimit
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	rutes, err := h.Store.Rute.All(ctx)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Rentang cukup lebar untuk semua zona waktu Indonesia ditambah satu hari
+	// untuk leg lanjutan yang melewati tengah malam
+	from := day.Add(-9 * time.Hour)
+	until := day.Add(48 * time.Hour)
+	jadwals, err := h.Store.Jadwal.FindDepartingBetween(ctx, from, until)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Jadwal yang kursinya sudah habis tidak ikut direncanakan
+	kendaraans, err := h.findKendaraanByIDs(ctx, jadwals)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	available := []models.Jadwal{}
+	for _, j := range jadwals {
+		if k, ok := kendaraans[j.KendaraanID]; ok && j.KursiTerisi < k.Kapasitas {
+			available = append(available, j)
+		}
+	}
+
+	results, truncated := planItineraries(rutes, available, asal, tujuan, day.Format("2006-01-02"), opts)
+	if truncated {
+		c.Set("X-Results-Truncated", "true")
+	}
+	return c.JSON(results)
+}
@@ -0,0 +1,109 @@
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"transport-app/models"
+	"transport-app/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// seedPlannerGraph membuat rute antar setiap pasangan kota dengan keberangkatan
+// tiap jam dari 01:00 sampai 20:00 yang tiba satu menit kemudian, sehingga
+// jumlah rangkaian perjalanan yang mungkin sangat besar
+func seedPlannerGraph(t *testing.T, s *testServer, day time.Time, cities []string) models.Kendaraan {
+	t.Helper()
+	ctx := context.Background()
+	kendaraan := models.Kendaraan{NomorPolisi: "B 1234 CD", Jenis: "Bus", Kapasitas: 40, Status: "aktif"}
+	if err := s.store.Kendaraan.Create(ctx, &kendaraan); err != nil {
+		t.Fatal(err)
+	}
+	for _, asal := range cities {
+		for _, tujuan := range cities {
+			if asal == tujuan {
+				continue
+			}
+			rute := models.Rute{KodeRute: asal + tujuan, NamaRute: asal + " - " + tujuan, Asal: asal, Tujuan: tujuan, JarakKM: 10, ZonaWaktu: "Asia/Jakarta"}
+			if err := s.store.Rute.Create(ctx, &rute); err != nil {
+				t.Fatal(err)
+			}
+			for jam := 1; jam <= 20; jam++ {
+				seedPlannerJadwal(t, s, day, rute, kendaraan, time.Duration(jam)*time.Hour)
+			}
+		}
+	}
+	return kendaraan
+}
+
+func seedPlannerJadwal(t *testing.T, s *testServer, day time.Time, rute models.Rute, kendaraan models.Kendaraan, berangkat time.Duration) {
+	t.Helper()
+	dep := day.Add(berangkat)
+	jadwal := models.Jadwal{
+		ID: primitive.NewObjectID(), Tanggal: day, WaktuBerangkat: dep, EstimasiTiba: dep.Add(time.Minute),
+		RuteID: rute.ID, KendaraanID: kendaraan.ID,
+	}
+	if err := s.store.Jadwal.Create(context.Background(), &jadwal); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlanJourneyTruncated(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	day := time.Date(2030, 1, 7, 0, 0, 0, 0, loc)
+	cities := []string{"A", "B", "C", "D", "E"}
+	const path = "/api/planner?asal=A&tujuan=Z&tanggal=2030-01-07&maks_transit=4&min_transit_menit=0&limit=1"
+
+	t.Run("tujuan tidak tercapai", func(t *testing.T) {
+		s := newTestServer(t)
+		s.createUser(t, "penumpang", models.RoleUser)
+		token := s.login(t, "penumpang")
+		kendaraan := seedPlannerGraph(t, s, day, cities)
+
+		// Z hanya bisa dicapai dari E sebelum semua jadwal lain berangkat,
+		// jadi setiap rangkaian dari A harus ditelusuri sampai batas
+		ez := models.Rute{KodeRute: "EZ", NamaRute: "E - Z", Asal: "E", Tujuan: "Z", JarakKM: 10, ZonaWaktu: "Asia/Jakarta"}
+		if err := s.store.Rute.Create(context.Background(), &ez); err != nil {
+			t.Fatal(err)
+		}
+		seedPlannerJadwal(t, s, day, ez, kendaraan, 30*time.Minute)
+
+		res := s.request(t, http.MethodGet, path, token, nil)
+		if res.Header.Get("X-Results-Truncated") != "true" {
+			t.Errorf("header X-Results-Truncated = %q, seharusnya true", res.Header.Get("X-Results-Truncated"))
+		}
+		var got []repository.Itinerary
+		s.expect(t, res, http.StatusOK, &got)
+		if len(got) != 0 {
+			t.Errorf("dapat %d itinerary, seharusnya tidak ada", len(got))
+		}
+	})
+
+	t.Run("dipangkas oleh waktu tiba terbaik", func(t *testing.T) {
+		s := newTestServer(t)
+		s.createUser(t, "penumpang", models.RoleUser)
+		token := s.login(t, "penumpang")
+		kendaraan := seedPlannerGraph(t, s, day, cities)
+
+		// Perjalanan langsung yang tiba paling awal membuat semua rangkaian
+		// lain tidak perlu ditelusuri
+		az := models.Rute{KodeRute: "AZ", NamaRute: "A - Z", Asal: "A", Tujuan: "Z", JarakKM: 10, ZonaWaktu: "Asia/Jakarta"}
+		if err := s.store.Rute.Create(context.Background(), &az); err != nil {
+			t.Fatal(err)
+		}
+		seedPlannerJadwal(t, s, day, az, kendaraan, 30*time.Minute)
+
+		res := s.request(t, http.MethodGet, path, token, nil)
+		if res.Header.Get("X-Results-Truncated") != "" {
+			t.Errorf("header X-Results-Truncated = %q, seharusnya kosong", res.Header.Get("X-Results-Truncated"))
+		}
+		var got []repository.Itinerary
+		s.expect(t, res, http.StatusOK, &got)
+		if len(got) != 1 || got[0].JumlahTransit != 0 || !got[0].WaktuTiba.Equal(day.Add(31*time.Minute)) {
+			t.Errorf("itinerary = %+v, seharusnya A - Z langsung tiba 00:31", got)
+		}
+	})
+}
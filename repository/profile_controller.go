@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UpdateProfileRequest berisi field profil yang ingin diubah. Field yang
+// tidak dikirim tidak diubah.
+type UpdateProfileRequest struct {
+	Username *string `json:"username"`
+	Email    *string `json:"email"`
+	// CurrentPassword wajib diisi jika email diganti
+	CurrentPassword string `json:"current_password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword      string `json:"current_password"`
+	Password             string `json:"password"`
+	PasswordConfirmation string `json:"password_confirmation"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// currentUser mengambil data user pemilik token. Akun yang sudah dihapus
+// diperlakukan sebagai token tidak valid.
+func (h *Handler) currentUser(ctx context.Context, c *fiber.Ctx) (models.User, error) {
+	userID, err := getUserIDFromToken(c)
+	if err != nil {
+		return models.User{}, store.ErrNotFound
+	}
+	return h.Store.User.Get(ctx, userID)
+}
+
+// GetMe godoc
+// @Summary Get my profile
+// @Description Mengambil data akun user yang sedang login
+// @Tags Profile
+// @Produce json
+// @Success 200 {object} models.PublicUser "Data akun"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/me [get]
+// @Security BearerAuth
+func (h *Handler) GetMe(c *fiber.Ctx) error {
+	user, err := h.currentUser(context.TODO(), c)
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(user.Public())
+}
+
+// UpdateMe godoc
+// @Summary Update my profile
+// @Description Mengganti username dan/atau email. Mengganti email membutuhkan current_password, dan email baru harus diverifikasi ulang lewat link yang dikirim ke email tersebut
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param profile body UpdateProfileRequest true "Field yang diubah"
+// @Success 200 {object} models.PublicUser "Data akun setelah diubah"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized atau password saat ini salah"
+// @Failure 409 {object} models.ErrorResponse "Username atau email sudah terdaftar"
+// @Failure 429 {object} models.ErrorResponse "Terlalu banyak password salah"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/me [patch]
+// @Security BearerAuth
+func (h *Handler) UpdateMe(c *fiber.Ctx) error {
+	var input UpdateProfileRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user, err := h.currentUser(ctx, c)
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	username, email := user.Username, user.Email
+	if input.Username != nil {
+		username = strings.TrimSpace(*input.Username)
+		if username == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Username tidak boleh kosong"})
+		}
+	}
+	if input.Email != nil {
+		email = strings.TrimSpace(*input.Email)
+		if !isEmailValid(email) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Format email tidak valid"})
+		}
+	}
+
+	// Username dan email harus tetap unik, kecuali milik user ini sendiri
+	if username != user.Username {
+		other, err := h.Store.User.GetByUsername(ctx, username)
+		if err == nil && other.ID != user.ID {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Username sudah terdaftar"})
+		}
+		if err != nil && err != store.ErrNotFound {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error saat memeriksa data pengguna"})
+		}
+	}
+	emailChanged := email != user.Email
+	if emailChanged {
+		other, err := h.Store.User.GetByEmail(ctx, email)
+		if err == nil && other.ID != user.ID {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Email sudah terdaftar"})
+		}
+		if err != nil && err != store.ErrNotFound {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error saat memeriksa data pengguna"})
+		}
+	}
+
+	// Email dipakai untuk reset password, jadi mengganti email butuh password
+	// saat ini agar token yang dicuri tidak bisa mengambil alih akun
+	if emailChanged {
+		if wait := h.beginLoginAttempt(ctx, c, user.Username); wait > 0 {
+			return loginLockedResponse(c, wait)
+		}
+		if !checkPasswordHash(input.CurrentPassword, user.Password) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Password saat ini salah"})
+		}
+		h.loginSucceeded(ctx, c, user.Username)
+	}
+
+	verified := user.EmailVerified && !emailChanged
+	err = h.Store.User.UpdateProfile(ctx, user.ID, username, email, verified)
+	if err == store.ErrDuplicate {
+		// Diambil user lain di antara pengecekan di atas dan penyimpanan
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Username atau email sudah terdaftar"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengubah profil"})
+	}
+	user.Username, user.Email, user.EmailVerified = username, email, verified
+
+	if emailChanged {
+		if err := h.sendVerificationEmail(ctx, user); err != nil {
+			fmt.Println("❌ Gagal mengirim email verifikasi:", err)
+		}
+	}
+
+	return c.JSON(user.Public())
+}
+
+// ChangePassword godoc
+// @Summary Change my password
+// @Description Mengganti password dengan memasukkan password saat ini. Semua session lain dicabut dan session baru dikembalikan
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param request body ChangePasswordRequest true "Password saat ini dan password baru"
+// @Success 200 {object} LoginResponse "Password diubah, token baru dikembalikan"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized atau password saat ini salah"
+// @Failure 429 {object} models.ErrorResponse "Terlalu banyak password salah"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/me/password [put]
+// @Security BearerAuth
+func (h *Handler) ChangePassword(c *fiber.Ctx) error {
+	var input ChangePasswordRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	if msg := validatePassword(input.Password, input.PasswordConfirmation); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": msg})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := h.currentUser(ctx, c)
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	// Password yang salah dihitung seperti login gagal agar token yang
+	// dicuri tidak bisa dipakai untuk menebak password
+	if wait := h.beginLoginAttempt(ctx, c, user.Username); wait > 0 {
+		return loginLockedResponse(c, wait)
+	}
+	if !checkPasswordHash(input.CurrentPassword, user.Password) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Password saat ini salah"})
+	}
+	h.loginSucceeded(ctx, c, user.Username)
+
+	hashedPassword, err := hashPassword(input.Password)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengenkripsi password"})
+	}
+	if err := h.Store.User.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mengubah password"})
+	}
+
+	// Perangkat lain harus login ulang; perangkat ini langsung mendapat
+	// session baru agar tidak ikut keluar
+	if err := h.Store.Session.RevokeAllForUser(ctx, user.ID, models.SessionRevokedChange); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal mencabut session"})
+	}
+	res, err := h.issueSession(ctx, user, getMFAFromToken(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membuat token autentikasi"})
+	}
+
+	return c.JSON(res)
+}
+
+// DeleteMe godoc
+// @Summary Delete my account
+// @Description Menghapus akun user yang sedang login setelah konfirmasi password. Semua session dicabut. Admin terakhir tidak bisa menghapus akunnya
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param request body DeleteAccountRequest true "Konfirmasi password"
+// @Success 200 {object} models.SuccessResponse "Akun dihapus"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized atau password salah"
+// @Failure 409 {object} models.ErrorResponse "Admin terakhir tidak bisa dihapus"
+// @Failure 429 {object} models.ErrorResponse "Terlalu banyak password salah"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/me [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteMe(c *fiber.Ctx) error {
+	var input DeleteAccountRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := h.currentUser(ctx, c)
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if wait := h.beginLoginAttempt(ctx, c, user.Username); wait > 0 {
+		return loginLockedResponse(c, wait)
+	}
+	if !checkPasswordHash(input.Password, user.Password) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Password salah"})
+	}
+	h.loginSucceeded(ctx, c, user.Username)
+
+	if user.Role == models.RoleAdmin {
+		count, err := h.Store.User.CountByRole(ctx, models.RoleAdmin)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		if count <= 1 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Admin terakhir tidak bisa menghapus akunnya"})
+		}
+	}
+
+	if err := h.Store.User.Delete(ctx, user.ID); err != nil && err != store.ErrNotFound {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	h.cleanupDeletedUser(ctx, user.ID)
+
+	return c.JSON(fiber.Map{"message": "Akun dihapus"})
+}
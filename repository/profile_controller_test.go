@@ -0,0 +1,91 @@
+package repository_test
+
+import (
+	"net/http"
+	"testing"
+
+	"transport-app/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestUpdateMeDuplicate(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "budi", models.RoleUser)
+	s.createUser(t, "siti", models.RoleUser)
+	token := s.login(t, "siti")
+
+	s.expect(t, s.request(t, http.MethodPatch, "/api/me", token, fiber.Map{"username": "budi"}), http.StatusConflict, nil)
+	s.expect(t, s.request(t, http.MethodPatch, "/api/me", token, fiber.Map{"email": "budi@example.com"}), http.StatusConflict, nil)
+
+	var me models.PublicUser
+	s.expect(t, s.request(t, http.MethodPatch, "/api/me", token, fiber.Map{"username": "siti2"}), http.StatusOK, &me)
+	if me.Username != "siti2" {
+		t.Fatalf("username tidak diubah: %+v", me)
+	}
+}
+
+// TestUpdateMeEmailPassword memastikan email hanya bisa diganti dengan
+// password saat ini, sedangkan username tidak
+func TestUpdateMeEmailPassword(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "budi", models.RoleUser)
+	token := s.login(t, "budi")
+
+	s.expect(t, s.request(t, http.MethodPatch, "/api/me", token, fiber.Map{"email": "baru@example.com"}), http.StatusUnauthorized, nil)
+	s.expect(t, s.request(t, http.MethodPatch, "/api/me", token, fiber.Map{"email": "baru@example.com", "current_password": "salah"}), http.StatusUnauthorized, nil)
+
+	var me models.PublicUser
+	s.expect(t, s.request(t, http.MethodPatch, "/api/me", token, fiber.Map{"username": "budi2"}), http.StatusOK, &me)
+	if me.Email != "budi@example.com" {
+		t.Fatalf("email berubah tanpa password: %+v", me)
+	}
+	s.expect(t, s.request(t, http.MethodPatch, "/api/me", token, fiber.Map{"email": "baru@example.com", "current_password": testPassword}), http.StatusOK, &me)
+	if me.Email != "baru@example.com" || me.EmailVerified {
+		t.Fatalf("email tidak diganti atau tetap terverifikasi: %+v", me)
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "budi", models.RoleUser)
+
+	register := func(username, email string) *http.Response {
+		return s.request(t, http.MethodPost, "/api/register", "", fiber.Map{
+			"username": username, "email": email, "password": testPassword, "password_confirmation": testPassword,
+		})
+	}
+	s.expect(t, register("budi", "lain@example.com"), http.StatusConflict, nil)
+	s.expect(t, register("lain", "budi@example.com"), http.StatusConflict, nil)
+}
+
+// TestProfilePasswordLockout memastikan password salah pada ganti password
+// dan hapus akun ikut dihitung seperti login gagal
+func TestProfilePasswordLockout(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "budi", models.RoleUser)
+	token := s.login(t, "budi")
+
+	changePassword := func(current string) *http.Response {
+		return s.request(t, http.MethodPut, "/api/me/password", token, fiber.Map{
+			"current_password": current, "password": "password-baru", "password_confirmation": "password-baru",
+		})
+	}
+	deleteMe := func(password string) *http.Response {
+		return s.request(t, http.MethodDelete, "/api/me", token, fiber.Map{"password": password})
+	}
+
+	// Tiga kegagalan pertama bebas, kegagalan keempat mulai mengunci
+	for i := 0; i < 2; i++ {
+		s.expect(t, changePassword("salah"), http.StatusUnauthorized, nil)
+		s.expect(t, deleteMe("salah"), http.StatusUnauthorized, nil)
+	}
+
+	res := changePassword(testPassword)
+	if res.Header.Get(fiber.HeaderRetryAfter) == "" {
+		t.Error("response 429 tanpa Retry-After")
+	}
+	s.expect(t, res, http.StatusTooManyRequests, nil)
+	s.expect(t, deleteMe(testPassword), http.StatusTooManyRequests, nil)
+	s.expect(t, s.request(t, http.MethodPost, "/api/login", "", fiber.Map{"username": "budi", "password": testPassword}), http.StatusTooManyRequests, nil)
+}
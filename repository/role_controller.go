@@ -0,0 +1,375 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var roleNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// SeedRoles membuat role bawaan yang belum ada. Role yang sudah ada tidak
+// diubah agar permission yang diatur admin tetap dipakai.
+func (h *Handler) SeedRoles(ctx context.Context) error {
+	for _, role := range models.DefaultRoles() {
+		created, err := h.Store.Role.CreateIfMissing(ctx, role)
+		if err != nil {
+			return err
+		}
+		if created {
+			fmt.Println("✅ Role bawaan dibuat:", role.Name)
+		}
+	}
+	return nil
+}
+
+// callerRole mengambil role user yang sedang login. Role yang sudah dihapus
+// dianggap tidak memiliki permission apa pun.
+func (h *Handler) callerRole(ctx context.Context, c *fiber.Ctx) (models.Role, error) {
+	role, err := h.Store.Role.GetByName(ctx, getRoleFromToken(c))
+	if err == store.ErrNotFound {
+		return models.Role{}, nil
+	}
+	return role, err
+}
+
+// hasPermission memeriksa permission role user yang sedang login
+func (h *Handler) hasPermission(ctx context.Context, c *fiber.Ctx, permission string) (bool, error) {
+	role, err := h.callerRole(ctx, c)
+	if err != nil {
+		return false, err
+	}
+	return role.Has(permission), nil
+}
+
+func validateRolePermissions(permissions []string) string {
+	for _, p := range permissions {
+		if !models.IsPermission(p) {
+			return "Permission tidak dikenal: " + p
+		}
+	}
+	return ""
+}
+
+// ungrantable mengembalikan pesan error jika ada permission yang tidak
+// dimiliki caller. Pemegang role:manage hanya boleh memberikan permission
+// yang ia miliki sendiri, dan * hanya boleh diberikan oleh pemegang *.
+func ungrantable(caller models.Role, permissions []string) string {
+	missing := []string{}
+	for _, p := range permissions {
+		if !caller.Has(p) {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		return "Tidak bisa memberikan permission yang tidak Anda miliki: " + strings.Join(missing, ", ")
+	}
+	return ""
+}
+
+// checkUserTarget menolak aksi admin pada user yang role-nya memiliki * jika
+// pemanggil sendiri tidak memiliki *
+func (h *Handler) checkUserTarget(ctx context.Context, caller models.Role, userID primitive.ObjectID) (int, string) {
+	user, err := h.Store.User.Get(ctx, userID)
+	if err == store.ErrNotFound {
+		return 404, "User not found"
+	}
+	if err != nil {
+		return 500, err.Error()
+	}
+	role, err := h.Store.Role.GetByName(ctx, user.Role)
+	if err == store.ErrNotFound {
+		return 0, ""
+	}
+	if err != nil {
+		return 500, err.Error()
+	}
+	if role.Has(models.PermissionAll) && !caller.Has(models.PermissionAll) {
+		return 403, "Tidak bisa mengelola user dengan role " + role.Name
+	}
+	return 0, ""
+}
+
+// GetPermissions godoc
+// @Summary List permissions
+// @Description Mengambil daftar permission yang bisa diberikan ke role
+// @Tags Role
+// @Produce json
+// @Success 200 {array} string "Daftar permission"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Router /api/admin/permissions [get]
+// @Security BearerAuth
+func (h *Handler) GetPermissions(c *fiber.Ctx) error {
+	return c.JSON(append([]string{models.PermissionAll}, models.AllPermissions...))
+}
+
+// GetAllRoles godoc
+// @Summary List roles
+// @Description Mengambil semua role beserta permission-nya
+// @Tags Role
+// @Produce json
+// @Success 200 {array} models.Role "Daftar role"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/roles [get]
+// @Security BearerAuth
+func (h *Handler) GetAllRoles(c *fiber.Ctx) error {
+	roles, err := h.Store.Role.All(context.TODO())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(roles)
+}
+
+// CreateRole godoc
+// @Summary Create a role
+// @Description Membuat role baru dengan kumpulan permission. Hanya permission yang dimiliki role pemanggil yang bisa diberikan
+// @Tags Role
+// @Accept json
+// @Produce json
+// @Param role body RoleRequest true "Data role"
+// @Success 201 {object} models.Role "Role berhasil dibuat"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden atau permission tidak dimiliki"
+// @Failure 409 {object} models.ErrorResponse "Role sudah ada"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/roles [post]
+// @Security BearerAuth
+func (h *Handler) CreateRole(c *fiber.Ctx) error {
+	var input RoleRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !roleNameRegex.MatchString(input.Name) {
+		return c.Status(400).JSON(fiber.Map{"error": "Nama role harus 2-32 karakter huruf kecil, angka, _ atau -"})
+	}
+	if msg := validateRolePermissions(input.Permissions); msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	caller, err := h.callerRole(ctx, c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if msg := ungrantable(caller, input.Permissions); msg != "" {
+		return c.Status(403).JSON(fiber.Map{"error": msg})
+	}
+
+	role := models.Role{
+		ID:          primitive.NewObjectID(),
+		Name:        input.Name,
+		Description: input.Description,
+		Permissions: input.Permissions,
+	}
+	if role.Permissions == nil {
+		role.Permissions = []string{}
+	}
+
+	created, err := h.Store.Role.CreateIfMissing(ctx, role)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !created {
+		return c.Status(409).JSON(fiber.Map{"error": "Role sudah ada"})
+	}
+
+	return c.Status(201).JSON(role)
+}
+
+// UpdateRole godoc
+// @Summary Update a role
+// @Description Mengubah deskripsi dan permission role. Role admin tidak bisa diubah, dan role pemanggil harus memiliki semua permission lama dan baru
+// @Tags Role
+// @Accept json
+// @Produce json
+// @Param name path string true "Nama role"
+// @Param role body RoleRequest true "Data role"
+// @Success 200 {object} models.Role "Role diupdate"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden atau permission tidak dimiliki"
+// @Failure 404 {object} models.ErrorResponse "Role not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/roles/{name} [put]
+// @Security BearerAuth
+func (h *Handler) UpdateRole(c *fiber.Ctx) error {
+	name := c.Params("name")
+	// Admin selalu memiliki semua akses agar tidak ada yang terkunci
+	if name == models.RoleAdmin {
+		return c.Status(400).JSON(fiber.Map{"error": "Role admin tidak bisa diubah"})
+	}
+
+	var input RoleRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if msg := validateRolePermissions(input.Permissions); msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+	if input.Permissions == nil {
+		input.Permissions = []string{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	existing, err := h.Store.Role.GetByName(ctx, name)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Role not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	// Role yang lebih kuat dari pemanggil juga tidak boleh diubah
+	caller, err := h.callerRole(ctx, c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if msg := ungrantable(caller, append(append([]string{}, existing.Permissions...), input.Permissions...)); msg != "" {
+		return c.Status(403).JSON(fiber.Map{"error": msg})
+	}
+
+	err = h.Store.Role.Update(ctx, models.Role{Name: name, Description: input.Description, Permissions: input.Permissions})
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Role not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	role, err := h.Store.Role.GetByName(ctx, name)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(role)
+}
+
+// DeleteRole godoc
+// @Summary Delete a role
+// @Description Menghapus role buatan admin yang tidak sedang dipakai user. Role bawaan tidak bisa dihapus
+// @Tags Role
+// @Produce json
+// @Param name path string true "Nama role"
+// @Success 200 {object} models.SuccessResponse "Role dihapus"
+// @Failure 400 {object} models.ErrorResponse "Role bawaan"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Role not found"
+// @Failure 409 {object} models.ErrorResponse "Role masih dipakai user"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/roles/{name} [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteRole(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	role, err := h.Store.Role.GetByName(ctx, name)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Role not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if role.BuiltIn {
+		return c.Status(400).JSON(fiber.Map{"error": "Role bawaan tidak bisa dihapus"})
+	}
+
+	count, err := h.Store.User.CountByRole(ctx, name)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if count > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("Role masih dipakai oleh %d user", count)})
+	}
+
+	if err := h.Store.Role.Delete(ctx, name); err != nil && err != store.ErrNotFound {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Role dihapus"})
+}
+
+// AssignUserRole godoc
+// @Summary Assign a role to a user
+// @Description Mengganti role user. Role baru berlaku setelah user login ulang atau memperbarui token. Role pemanggil harus memiliki semua permission role lama dan role baru user
+// @Tags Role
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param role body AssignRoleRequest true "Nama role"
+// @Success 200 {object} models.SuccessResponse "Role user diubah"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden atau permission tidak dimiliki"
+// @Failure 404 {object} models.ErrorResponse "User or Role not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{id}/role [put]
+// @Security BearerAuth
+func (h *Handler) AssignUserRole(c *fiber.Ctx) error {
+	userID, msg := adminTargetUser(c)
+	if msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+
+	var input AssignRoleRequest
+	if err := c.BodyParser(&input); err != nil || input.Role == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "role wajib diisi"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	role, err := h.Store.Role.GetByName(ctx, input.Role)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Role not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	caller, err := h.callerRole(ctx, c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	// Role yang sedang dipakai user juga diperiksa agar admin tidak bisa
+	// diturunkan oleh pemanggil yang lebih lemah
+	if status, msg := h.checkUserTarget(ctx, caller, userID); msg != "" {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	if msg := ungrantable(caller, role.Permissions); msg != "" {
+		return c.Status(403).JSON(fiber.Map{"error": msg})
+	}
+
+	err = h.Store.User.SetRole(ctx, userID, input.Role)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Role user diubah"})
+}
@@ -0,0 +1,49 @@
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"transport-app/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestRoleEscalation memastikan pemegang role:manage tanpa * tidak bisa
+// memberikan permission yang tidak ia miliki, termasuk role admin
+func TestRoleEscalation(t *testing.T) {
+	s := newTestServer(t)
+	manajer := models.Role{Name: "manajer", Permissions: []string{models.PermRoleManage, models.PermRuteRead}}
+	if err := s.store.Role.Create(context.Background(), &manajer); err != nil {
+		t.Fatal(err)
+	}
+	s.createUser(t, "manajer", "manajer")
+	admin := s.createUser(t, "admin", models.RoleAdmin)
+	user := s.createUser(t, "budi", models.RoleUser)
+	token := s.login(t, "manajer")
+
+	role := func(name string, permissions ...string) fiber.Map {
+		return fiber.Map{"name": name, "permissions": permissions}
+	}
+	assign := func(id, role string) *http.Response {
+		return s.request(t, http.MethodPut, "/api/admin/users/"+id+"/role", token, fiber.Map{"role": role})
+	}
+
+	s.expect(t, s.request(t, http.MethodPost, "/api/admin/roles", token, role("super", models.PermissionAll)), http.StatusForbidden, nil)
+	s.expect(t, s.request(t, http.MethodPost, "/api/admin/roles", token, role("penulis", models.PermRuteWrite)), http.StatusForbidden, nil)
+	s.expect(t, s.request(t, http.MethodPost, "/api/admin/roles", token, role("pembaca", models.PermRuteRead)), http.StatusCreated, nil)
+
+	s.expect(t, s.request(t, http.MethodPut, "/api/admin/roles/pembaca", token, role("", models.PermissionAll)), http.StatusForbidden, nil)
+	// Role operator memiliki permission yang tidak dimiliki manajer
+	s.expect(t, s.request(t, http.MethodPut, "/api/admin/roles/operator", token, role("", models.PermRuteRead)), http.StatusForbidden, nil)
+
+	s.expect(t, assign(user.ID.Hex(), models.RoleAdmin), http.StatusForbidden, nil)
+	s.expect(t, assign(admin.ID.Hex(), "pembaca"), http.StatusForbidden, nil)
+	s.expect(t, assign(user.ID.Hex(), "pembaca"), http.StatusOK, nil)
+
+	// Admin tetap bisa memberikan semua permission
+	adminToken := s.login(t, "admin")
+	s.expect(t, s.request(t, http.MethodPost, "/api/admin/roles", adminToken, role("super", models.PermissionAll)), http.StatusCreated, nil)
+	s.expect(t, s.request(t, http.MethodPut, "/api/admin/users/"+user.ID.Hex()+"/role", adminToken, fiber.Map{"role": models.RoleAdmin}), http.StatusOK, nil)
+}
@@ -4,56 +4,58 @@ import (
 	"context"
 	"fmt"
 	"time"
-	"transport-app/config"
 	"transport-app/models"
+	"transport-app/query"
+	"transport-app/store"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func getRuteCollection() *mongo.Collection {
-	return config.GetCollection("rutes")
+var ruteSchema = query.Schema{
+	"kode_rute":  {BSON: "kode_rute", Kind: query.String},
+	"nama_rute":  {BSON: "nama_rute", Kind: query.String},
+	"asal":       {BSON: "asal", Kind: query.String},
+	"tujuan":     {BSON: "tujuan", Kind: query.String},
+	"jarak_km":   {BSON: "jarak_km", Kind: query.Int},
+	"zona_waktu": {BSON: "zona_waktu", Kind: query.String},
 }
 
 // GetAllRute godoc
 // @Summary Get all rutes
-// @Description Mengambil semua data rute
+// @Description Mengambil data rute dengan filter, sort dan pagination. Filter: field=nilai, field[op]=nilai (eq, ne, gt, gte, lt, lte, in). Sort: sort=field,-field
 // @Tags Rute
 // @Accept json
 // @Produce json
-// @Success 200 {array} models.Rute "Daftar semua rute"
+// @Param page query int false "Nomor halaman (default 1)"
+// @Param limit query int false "Jumlah data per halaman (default 20, maks 100)"
+// @Param cursor query string false "Cursor dari next_cursor halaman sebelumnya"
+// @Param sort query string false "Urutan, mis. asal,-jarak_km"
+// @Param asal query string false "Filter kota asal"
+// @Param tujuan query string false "Filter kota tujuan"
+// @Success 200 {object} query.Page[models.Rute] "Daftar rute"
+// @Failure 400 {object} models.ErrorResponse "Query tidak valid"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/rutes [get]
 // @Security BearerAuth
-func GetAllRute(c *fiber.Ctx) error {
-	fmt.Println("🔍 GET /api/rutes called")
-	ruteCollection := getRuteCollection()
-
-	if ruteCollection == nil {
-		fmt.Println("❌ Collection is nil")
-		return c.Status(500).JSON(fiber.Map{"error": "Rute collection is nil"})
+// @Security APIKeyAuth
+func (h *Handler) GetAllRute(c *fiber.Ctx) error {
+	q, err := query.Parse(c, ruteSchema, []query.Sort{{Field: "kode_rute"}})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := ruteCollection.Find(ctx, bson.M{})
+	page, err := h.Store.Rute.List(ctx, q)
 	if err != nil {
-		fmt.Println("❌ Error saat Find:", err.Error())
+		fmt.Println("❌ Error saat mengambil rute:", err.Error())
 		return c.Status(500).JSON(fiber.Map{"error": "Find error: " + err.Error()})
 	}
 
-	var rutes []models.Rute
-	if err := cursor.All(ctx, &rutes); err != nil {
-		fmt.Println("❌ Error parsing cursor:", err.Error())
-		return c.Status(500).JSON(fiber.Map{"error": "Cursor decode error: " + err.Error()})
-	}
-
-	fmt.Println("✅ Data ditemukan:", rutes)
-	return c.JSON(rutes)
+	return c.JSON(page.WithNext(c))
 }
 
 // GetRuteByID godoc
@@ -69,8 +71,8 @@ func GetAllRute(c *fiber.Ctx) error {
 // @Failure 404 {object} models.ErrorResponse "Rute not found"
 // @Router /api/rutes/{id} [get]
 // @Security BearerAuth
-func GetRuteByID(c *fiber.Ctx) error {
-	ruteCollection := getRuteCollection()
+// @Security APIKeyAuth
+func (h *Handler) GetRuteByID(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -79,8 +81,7 @@ func GetRuteByID(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	var rute models.Rute
-	err = ruteCollection.FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&rute)
+	rute, err := h.Store.Rute.Get(context.TODO(), objID)
 	if err != nil {
 		fmt.Println("❌ Rute not found with ID:", id)
 		return c.Status(404).JSON(fiber.Map{"error": "Rute not found"})
@@ -91,7 +92,7 @@ func GetRuteByID(c *fiber.Ctx) error {
 
 // CreateRute godoc
 // @Summary Create a new rute
-// @Description Membuat data rute baru (Admin Only)
+// @Description Membuat data rute baru (Admin Only). Halte opsional, berurutan dari asal ke tujuan dengan jarak kumulatif (halte pertama 0, halte terakhir sama dengan jarak_km) dan dwell dalam menit
 // @Tags Rute
 // @Accept json
 // @Produce json
@@ -103,7 +104,7 @@ func GetRuteByID(c *fiber.Ctx) error {
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/rutes [post]
 // @Security BearerAuth
-func CreateRute(c *fiber.Ctx) error {
+func (h *Handler) CreateRute(c *fiber.Ctx) error {
 	var rute models.Rute
 	if err := c.BodyParser(&rute); err != nil {
 		fmt.Println("❌ Error parsing body:", err)
@@ -117,10 +118,24 @@ func CreateRute(c *fiber.Ctx) error {
 		})
 	}
 
+	zona, err := normalizeZonaWaktu(rute.ZonaWaktu)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	rute.ZonaWaktu = zona
+
+	msg, err := h.validateRuteHalte(context.TODO(), rute)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+
 	// Set ID baru secara manual agar bisa dikembalikan di response
 	rute.ID = primitive.NewObjectID()
 
-	_, err := getRuteCollection().InsertOne(context.TODO(), rute)
+	err = h.Store.Rute.Create(context.TODO(), &rute)
 	if err != nil {
 		fmt.Println("❌ Error saat menyimpan rute:", err)
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
@@ -132,7 +147,7 @@ func CreateRute(c *fiber.Ctx) error {
 
 // UpdateRute godoc
 // @Summary Update an existing rute
-// @Description Memperbarui data rute yang sudah ada berdasarkan ID (Admin Only)
+// @Description Memperbarui data rute yang sudah ada berdasarkan ID (Admin Only). Daftar halte ikut diganti, kirim halte kosong untuk menghapusnya. Untuk rute yang memiliki geometri, jarak rute dan halte dihitung dari geometri
 // @Tags Rute
 // @Accept json
 // @Produce json
@@ -142,11 +157,11 @@ func CreateRute(c *fiber.Ctx) error {
 // @Failure 400 {object} models.ErrorResponse "Invalid ID atau Bad Request"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} models.ErrorResponse "Rute not found"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/rutes/{id} [put]
 // @Security BearerAuth
-func UpdateRute(c *fiber.Ctx) error {
-	ruteCollection := getRuteCollection()
+func (h *Handler) UpdateRute(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -161,6 +176,26 @@ func UpdateRute(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	existing, err := h.Store.Rute.Get(context.TODO(), objID)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Rute not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	// Rute yang memiliki geometri memakai jarak dari geometri, dan halte
+	// barunya harus tetap berada di sepanjang geometri
+	if existing.Geometri != nil {
+		rute.Geometri = existing.Geometri
+		msg, err := h.applyGeometri(context.TODO(), &rute)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if msg != "" {
+			return c.Status(400).JSON(fiber.Map{"error": msg})
+		}
+	}
+
 	if rute.KodeRute == "" || rute.NamaRute == "" || rute.Asal == "" || rute.Tujuan == "" || rute.JarakKM <= 0 {
 		fmt.Println("❌ Field validation failed")
 		return c.Status(400).JSON(fiber.Map{
@@ -168,15 +203,25 @@ func UpdateRute(c *fiber.Ctx) error {
 		})
 	}
 
-	update := bson.M{"$set": bson.M{
-		"kode_rute": rute.KodeRute,
-		"nama_rute": rute.NamaRute,
-		"asal":      rute.Asal,
-		"tujuan":    rute.Tujuan,
-		"jarak_km":  rute.JarakKM,
-	}}
+	zona, err := normalizeZonaWaktu(rute.ZonaWaktu)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	rute.ZonaWaktu = zona
+
+	msg, err := h.validateRuteHalte(context.TODO(), rute)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
 
-	_, err = ruteCollection.UpdateByID(context.TODO(), objID, update)
+	rute.ID = objID
+	err = h.Store.Rute.Update(context.TODO(), rute)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Rute not found"})
+	}
 	if err != nil {
 		fmt.Println("❌ Error saat mengupdate rute:", err)
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
@@ -196,11 +241,11 @@ func UpdateRute(c *fiber.Ctx) error {
 // @Failure 400 {object} models.ErrorResponse "Invalid ID"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 403 {object} models.ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} models.ErrorResponse "Rute not found"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/rutes/{id} [delete]
 // @Security BearerAuth
-func DeleteRute(c *fiber.Ctx) error {
-	ruteCollection := getRuteCollection()
+func (h *Handler) DeleteRute(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -209,7 +254,10 @@ func DeleteRute(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	_, err = ruteCollection.DeleteOne(context.TODO(), bson.M{"_id": objID})
+	err = h.Store.Rute.Delete(context.TODO(), objID)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Rute not found"})
+	}
 	if err != nil {
 		fmt.Println("❌ Error saat menghapus rute:", err)
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"testing"
+
+	"transport-app/models"
+	"transport-app/query"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// brokenRuteStore mensimulasikan database yang tidak bisa dihubungi
+type brokenRuteStore struct {
+	store.RuteStore
+}
+
+func (brokenRuteStore) List(ctx context.Context, q query.ListQuery) (query.Page[models.Rute], error) {
+	return query.Page[models.Rute]{}, errors.New("koneksi terputus")
+}
+
+func TestRuteCRUD(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "operator", models.RoleOperator)
+	token := s.login(t, "operator")
+
+	input := fiber.Map{"kode_rute": "R1", "nama_rute": "Kota - Bandara", "asal": "Kota", "tujuan": "Bandara", "jarak_km": 25, "zona_waktu": "WITA"}
+	var created models.Rute
+	s.expect(t, s.request(t, http.MethodPost, "/api/rutes", token, input), http.StatusCreated, &created)
+	if created.ID.IsZero() || created.ZonaWaktu != "Asia/Makassar" {
+		t.Fatalf("rute dibuat tanpa ID atau zona waktu tidak dinormalisasi: %+v", created)
+	}
+	path := "/api/rutes/" + created.ID.Hex()
+
+	var got models.Rute
+	s.expect(t, s.request(t, http.MethodGet, path, token, nil), http.StatusOK, &got)
+	if got.KodeRute != "R1" || got.JarakKM != 25 {
+		t.Fatalf("rute tidak sesuai: %+v", got)
+	}
+
+	var page query.Page[models.Rute]
+	s.expect(t, s.request(t, http.MethodGet, "/api/rutes", token, nil), http.StatusOK, &page)
+	if page.Total != 1 || len(page.Data) != 1 {
+		t.Fatalf("daftar rute: %+v", page)
+	}
+
+	input["nama_rute"] = "Kota - Bandara Baru"
+	input["jarak_km"] = 30
+	s.expect(t, s.request(t, http.MethodPut, path, token, input), http.StatusOK, nil)
+	s.expect(t, s.request(t, http.MethodGet, path, token, nil), http.StatusOK, &got)
+	if got.NamaRute != "Kota - Bandara Baru" || got.JarakKM != 30 {
+		t.Fatalf("rute tidak diperbarui: %+v", got)
+	}
+
+	s.expect(t, s.request(t, http.MethodDelete, path, token, nil), http.StatusOK, nil)
+	s.expect(t, s.request(t, http.MethodGet, path, token, nil), http.StatusNotFound, nil)
+}
+
+// cursorOf membuat cursor seperti yang dikirim klien untuk nilai sort values
+func cursorOf(t *testing.T, values ...interface{}) string {
+	t.Helper()
+	b, err := bson.Marshal(bson.M{"v": values})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestRuteErrors(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "operator", models.RoleOperator)
+	token := s.login(t, "operator")
+	missing := "/api/rutes/" + primitive.NewObjectID().Hex()
+	valid := fiber.Map{"kode_rute": "R1", "nama_rute": "Kota", "asal": "A", "tujuan": "B", "jarak_km": 10}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{"ID tidak valid", http.MethodGet, "/api/rutes/bukan-id", nil, http.StatusBadRequest},
+		{"update ID tidak valid", http.MethodPut, "/api/rutes/bukan-id", valid, http.StatusBadRequest},
+		{"hapus ID tidak valid", http.MethodDelete, "/api/rutes/bukan-id", nil, http.StatusBadRequest},
+		{"tidak ditemukan", http.MethodGet, missing, nil, http.StatusNotFound},
+		{"update tidak ditemukan", http.MethodPut, missing, valid, http.StatusNotFound},
+		{"hapus tidak ditemukan", http.MethodDelete, missing, nil, http.StatusNotFound},
+		{"JSON rusak", http.MethodPost, "/api/rutes", "{", http.StatusBadRequest},
+		{"field wajib kosong", http.MethodPost, "/api/rutes", fiber.Map{"kode_rute": "R2"}, http.StatusBadRequest},
+		{"jarak tidak positif", http.MethodPost, "/api/rutes", fiber.Map{"kode_rute": "R2", "nama_rute": "Kota", "asal": "A", "tujuan": "B", "jarak_km": 0}, http.StatusBadRequest},
+		{"zona waktu tidak dikenal", http.MethodPost, "/api/rutes", fiber.Map{"kode_rute": "R2", "nama_rute": "Kota", "asal": "A", "tujuan": "B", "jarak_km": 5, "zona_waktu": "Mars/Olympus"}, http.StatusBadRequest},
+		{"query tidak valid", http.MethodGet, "/api/rutes?sort=password", nil, http.StatusBadRequest},
+		{"cursor berisi operator", http.MethodGet, "/api/rutes?cursor=" + cursorOf(t, bson.M{"$ne": nil}, primitive.NewObjectID()), nil, http.StatusBadRequest},
+		{"cursor salah tipe", http.MethodGet, "/api/rutes?cursor=" + cursorOf(t, 5, primitive.NewObjectID()), nil, http.StatusBadRequest},
+		{"cursor id bukan ObjectID", http.MethodGet, "/api/rutes?cursor=" + cursorOf(t, "R1", "bukan-id"), nil, http.StatusBadRequest},
+		{"cursor valid", http.MethodGet, "/api/rutes?cursor=" + cursorOf(t, "R1", primitive.NewObjectID()), nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.expect(t, s.request(t, tt.method, tt.path, token, tt.body), tt.status, nil)
+		})
+	}
+
+	t.Run("store gagal", func(t *testing.T) {
+		s.store.Rute = brokenRuteStore{s.store.Rute}
+		s.expect(t, s.request(t, http.MethodGet, "/api/rutes", token, nil), http.StatusInternalServerError, nil)
+	})
+}
@@ -0,0 +1,363 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"transport-app/geo"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// geometriToleransiM adalah jarak maksimal halte dari geometri rute
+	geometriToleransiM = 500
+	maxGeometriTitik   = 20000
+)
+
+// RuteGeometriRequest berisi geometri rute dalam salah satu format: GeoJSON
+// (LineString, Feature, atau FeatureCollection dengan satu LineString) atau
+// encoded polyline
+type RuteGeometriRequest struct {
+	GeoJSON   json.RawMessage `json:"geojson,omitempty" swaggertype:"object"`
+	Polyline  string          `json:"polyline,omitempty"`
+	Precision int             `json:"precision,omitempty"` // Digit desimal polyline, 5 (default) atau 6
+}
+
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Coordinates [][]float64     `json:"coordinates"`
+	Geometry    json.RawMessage `json:"geometry"`
+	Features    []geoJSONObject `json:"features"`
+}
+
+var errGeoJSON = errors.New("GeoJSON harus berupa LineString, Feature LineString, atau FeatureCollection dengan satu LineString")
+
+// lineFromGeoJSON mengambil titik LineString dari objek GeoJSON
+func lineFromGeoJSON(raw json.RawMessage) ([]geo.Point, error) {
+	var obj geoJSONObject
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, errGeoJSON
+	}
+	switch obj.Type {
+	case "LineString":
+		line := models.LineString{Type: obj.Type, Coordinates: obj.Coordinates}
+		for _, c := range obj.Coordinates {
+			if len(c) < 2 {
+				return nil, errGeoJSON
+			}
+		}
+		return line.Points(), nil
+	case "Feature":
+		if len(obj.Geometry) == 0 {
+			return nil, errGeoJSON
+		}
+		return lineFromGeoJSON(obj.Geometry)
+	case "FeatureCollection":
+		if len(obj.Features) != 1 || obj.Features[0].Type != "Feature" || len(obj.Features[0].Geometry) == 0 {
+			return nil, errGeoJSON
+		}
+		return lineFromGeoJSON(obj.Features[0].Geometry)
+	}
+	return nil, errGeoJSON
+}
+
+// geometriFromRequest mengembalikan titik geometri, atau pesan error
+func geometriFromRequest(input RuteGeometriRequest) ([]geo.Point, string) {
+	var points []geo.Point
+	switch {
+	case len(input.GeoJSON) > 0 && input.Polyline != "":
+		return nil, "Kirim geojson atau polyline, tidak keduanya"
+	case len(input.GeoJSON) > 0:
+		p, err := lineFromGeoJSON(input.GeoJSON)
+		if err != nil {
+			return nil, err.Error()
+		}
+		points = p
+	case input.Polyline != "":
+		precision := input.Precision
+		if precision == 0 {
+			precision = 5
+		}
+		if precision != 5 && precision != 6 {
+			return nil, "precision harus 5 atau 6"
+		}
+		p, err := geo.DecodePolyline(input.Polyline, precision)
+		if err != nil {
+			return nil, "Encoded polyline tidak valid"
+		}
+		points = p
+	default:
+		return nil, "geojson atau polyline wajib diisi"
+	}
+
+	if len(points) < 2 {
+		return nil, "Geometri minimal memiliki dua titik"
+	}
+	if len(points) > maxGeometriTitik {
+		return nil, fmt.Sprintf("Geometri maksimal memiliki %d titik", maxGeometriTitik)
+	}
+	for _, p := range points {
+		if !geo.ValidLatLng(p.Lat, p.Lng) {
+			return nil, "Koordinat geometri tidak valid"
+		}
+	}
+	return points, ""
+}
+
+// applyGeometri menghitung JarakKM rute dari geometrinya dan jarak
+// kumulatif setiap halte dari posisinya sepanjang geometri. Ujung geometri
+// harus berada di dekat halte pertama dan terakhir, atau di dekat halte di
+// kota asal dan tujuan untuk rute tanpa halte.
+func (h *Handler) applyGeometri(ctx context.Context, rute *models.Rute) (string, error) {
+	if len(rute.Halte) == 0 {
+		points := rute.Geometri.Points()
+		jarak, msg := geometriJarak(points)
+		if msg != "" {
+			return msg, nil
+		}
+		rute.JarakKM = jarak
+		start, end := points[0], points[len(points)-1]
+
+		ok, err := h.nearHalteInKota(ctx, start, rute.Asal)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return fmt.Sprintf("Titik awal geometri harus berada dalam %d m dari halte di kota asal %s", geometriToleransiM, rute.Asal), nil
+		}
+		ok, err = h.nearHalteInKota(ctx, end, rute.Tujuan)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return fmt.Sprintf("Titik akhir geometri harus berada dalam %d m dari halte di kota tujuan %s", geometriToleransiM, rute.Tujuan), nil
+		}
+		return "", nil
+	}
+	if len(rute.Halte) < 2 {
+		// Ditolak oleh validateRuteHalte
+		return "", nil
+	}
+
+	ids := make([]primitive.ObjectID, len(rute.Halte))
+	for i, stop := range rute.Halte {
+		ids[i] = stop.HalteID
+	}
+	haltes, err := h.Store.Halte.GetMany(ctx, ids)
+	if err != nil {
+		return "", err
+	}
+	for i, id := range ids {
+		if _, ok := haltes[id]; !ok {
+			return fmt.Sprintf("Halte ke-%d tidak ditemukan", i+1), nil
+		}
+	}
+	return fitGeometri(rute, haltes), nil
+}
+
+func geometriJarak(points []geo.Point) (int, string) {
+	jarak := int(math.Round(geo.Length(points) / 1000))
+	if jarak <= 0 {
+		return 0, "Geometri rute terlalu pendek, minimal 0,5 km"
+	}
+	return jarak, ""
+}
+
+// fitGeometri menghitung jarak rute dan halte dari geometri untuk rute
+// yang semua halte-nya ada di haltes, dan mengembalikan pesan error jika
+// halte tidak berada di sepanjang geometri
+func fitGeometri(rute *models.Rute, haltes map[primitive.ObjectID]models.Halte) string {
+	points := rute.Geometri.Points()
+	jarak, msg := geometriJarak(points)
+	if msg != "" {
+		return msg
+	}
+	rute.JarakKM = jarak
+	start, end := points[0], points[len(points)-1]
+	// Hanya JarakKM rute yang dibulatkan ke kilometer; halte terakhir memakai
+	// pembulatan 0,1 km yang sama dengan halte lain agar tetap berurutan
+	jarakAkhir := math.Round(geo.Length(points)/100) / 10
+
+	first, last := haltes[rute.Halte[0].HalteID], haltes[rute.Halte[len(rute.Halte)-1].HalteID]
+	if d := geo.Haversine(start.Lat, start.Lng, first.Lokasi.Lat(), first.Lokasi.Lng()); d > geometriToleransiM {
+		return fmt.Sprintf("Titik awal geometri berjarak %.0f m dari halte pertama %s", d, first.Kode)
+	}
+	if d := geo.Haversine(end.Lat, end.Lng, last.Lokasi.Lat(), last.Lokasi.Lng()); d > geometriToleransiM {
+		return fmt.Sprintf("Titik akhir geometri berjarak %.0f m dari halte terakhir %s", d, last.Kode)
+	}
+
+	stops := make([]models.RuteHalte, len(rute.Halte))
+	copy(stops, rute.Halte)
+	for i := range stops {
+		halte := haltes[stops[i].HalteID]
+		along, offset := geo.Locate(points, geo.Point{Lat: halte.Lokasi.Lat(), Lng: halte.Lokasi.Lng()})
+		if offset > geometriToleransiM {
+			return fmt.Sprintf("Halte ke-%d (%s) berjarak %.0f m dari geometri rute", i+1, halte.Kode, offset)
+		}
+		switch i {
+		case 0:
+			stops[i].JarakKM = 0
+		case len(stops) - 1:
+			stops[i].JarakKM = jarakAkhir
+		default:
+			stops[i].JarakKM = math.Round(along/100) / 10
+		}
+		if i > 0 && stops[i].JarakKM <= stops[i-1].JarakKM {
+			return fmt.Sprintf("Halte ke-%d (%s) tidak berurutan sepanjang geometri rute", i+1, halte.Kode)
+		}
+	}
+	rute.Halte = stops
+	return ""
+}
+
+func (h *Handler) nearHalteInKota(ctx context.Context, p geo.Point, kota string) (bool, error) {
+	haltes, err := h.Store.Halte.Nearby(ctx, p.Lat, p.Lng, geometriToleransiM, maxNearbyLimit)
+	if err != nil {
+		return false, err
+	}
+	for _, halte := range haltes {
+		if strings.EqualFold(halte.Kota, strings.TrimSpace(kota)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetRuteGeometri godoc
+// @Summary Set rute geometry
+// @Description Menyimpan jalur rute dari GeoJSON atau encoded polyline (Admin Only). Jarak rute dan jarak setiap halte dihitung ulang dari geometri. Ujung geometri harus berada dalam 500 m dari halte pertama dan terakhir, atau dari halte di kota asal dan tujuan untuk rute tanpa halte
+// @Tags Rute
+// @Accept json
+// @Produce json
+// @Param id path string true "Rute ID"
+// @Param geometri body RuteGeometriRequest true "Geometri rute"
+// @Success 200 {object} models.RuteFeature "Rute dengan geometri baru"
+// @Failure 400 {object} models.ErrorResponse "Geometri tidak valid"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Rute not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/rutes/{id}/geometri [put]
+// @Security BearerAuth
+func (h *Handler) SetRuteGeometri(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var input RuteGeometriRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	points, msg := geometriFromRequest(input)
+	if msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rute, err := h.Store.Rute.Get(ctx, id)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Rute not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	rute.Geometri = models.NewLineString(points)
+	msg, err = h.applyGeometri(ctx, &rute)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if msg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+
+	if err := h.Store.Rute.SetGeometri(ctx, rute); err != nil {
+		fmt.Println("❌ Error saat menyimpan geometri rute:", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(ruteFeature(rute))
+}
+
+// DeleteRuteGeometri godoc
+// @Summary Delete rute geometry
+// @Description Menghapus jalur rute (Admin Only). Jarak rute dan halte tidak berubah
+// @Tags Rute
+// @Produce json
+// @Param id path string true "Rute ID"
+// @Success 200 {object} models.SuccessResponse "Geometri dihapus"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Rute not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/rutes/{id}/geometri [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteRuteGeometri(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	err = h.Store.Rute.SetGeometri(context.TODO(), models.Rute{ID: id})
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "Rute not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Geometri rute dihapus"})
+}
+
+// GetRuteGeoJSON godoc
+// @Summary Get a rute as GeoJSON
+// @Description Mengambil rute sebagai GeoJSON Feature untuk klien peta. Geometry bernilai null jika rute belum memiliki geometri
+// @Tags Rute
+// @Produce json
+// @Param id path string true "Rute ID"
+// @Success 200 {object} models.RuteFeature "GeoJSON Feature"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Rute not found"
+// @Router /api/rutes/{id}/geojson [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+func (h *Handler) GetRuteGeoJSON(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	rute, err := h.Store.Rute.Get(context.TODO(), id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Rute not found"})
+	}
+
+	return c.JSON(ruteFeature(rute), "application/geo+json")
+}
+
+func ruteFeature(rute models.Rute) models.RuteFeature {
+	return models.RuteFeature{
+		Type:     "Feature",
+		ID:       rute.ID.Hex(),
+		Geometry: rute.Geometri,
+		Properties: models.RuteProperties{
+			KodeRute:  rute.KodeRute,
+			NamaRute:  rute.NamaRute,
+			Asal:      rute.Asal,
+			Tujuan:    rute.Tujuan,
+			JarakKM:   rute.JarakKM,
+			ZonaWaktu: rute.ZonaWaktu,
+		},
+	}
+}
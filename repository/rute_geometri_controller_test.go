@@ -0,0 +1,51 @@
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"transport-app/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestSetRuteGeometriJarakHalte memastikan halte terakhir memakai jarak
+// geometri dalam 0,1 km, bukan jarak rute yang dibulatkan ke kilometer.
+// Geometri 2,4 km dibulatkan menjadi 2 km sehingga halte terakhir akan
+// berada sebelum halte kedua (2,2 km) jika ikut dibulatkan.
+func TestSetRuteGeometriJarakHalte(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser(t, "operator", models.RoleOperator)
+	token := s.login(t, "operator")
+
+	ctx := context.Background()
+	haltes := []models.Halte{
+		{Kode: "A", Nama: "Halte A", Kota: "Jakarta", Lokasi: models.NewGeoPoint(-6.2, 106.8)},
+		{Kode: "B", Nama: "Halte B", Kota: "Jakarta", Lokasi: models.NewGeoPoint(-6.18, 106.8)},
+		{Kode: "C", Nama: "Halte C", Kota: "Jakarta", Lokasi: models.NewGeoPoint(-6.1785, 106.8)},
+	}
+	for i := range haltes {
+		if err := s.store.Halte.Create(ctx, &haltes[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rute := models.Rute{
+		KodeRute: "R1", NamaRute: "A - C", Asal: "Jakarta", Tujuan: "Jakarta", JarakKM: 3, ZonaWaktu: "Asia/Jakarta",
+		Halte: []models.RuteHalte{{HalteID: haltes[0].ID}, {HalteID: haltes[1].ID, JarakKM: 2}, {HalteID: haltes[2].ID, JarakKM: 3}},
+	}
+	if err := s.store.Rute.Create(ctx, &rute); err != nil {
+		t.Fatal(err)
+	}
+
+	geojson := fiber.Map{"type": "LineString", "coordinates": [][]float64{{106.8, -6.2}, {106.8, -6.1785}}}
+	s.expect(t, s.request(t, http.MethodPut, "/api/rutes/"+rute.ID.Hex()+"/geometri", token, fiber.Map{"geojson": geojson}), http.StatusOK, nil)
+
+	got, err := s.store.Rute.Get(ctx, rute.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.JarakKM != 2 || got.Halte[1].JarakKM != 2.2 || got.Halte[2].JarakKM != 2.4 {
+		t.Fatalf("jarak rute %d km, halte %+v; seharusnya 2 km dengan halte 2,2 dan 2,4 km", got.JarakKM, got.Halte)
+	}
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchResult adalah satu keberangkatan yang cocok dengan pencarian
+type SearchResult struct {
+	JadwalID       string    `json:"jadwal_id"`
+	KodeRute       string    `json:"kode_rute"`
+	NamaRute       string    `json:"nama_rute"`
+	Asal           string    `json:"asal"`
+	Tujuan         string    `json:"tujuan"`
+	JarakKM        int       `json:"jarak_km"`
+	WaktuBerangkat time.Time `json:"waktu_berangkat"`
+	EstimasiTiba   time.Time `json:"estimasi_tiba"`
+	DurasiMenit    int       `json:"durasi_menit"`
+	JenisKendaraan string    `json:"jenis_kendaraan"`
+	NomorPolisi    string    `json:"nomor_polisi"`
+	Kapasitas      int       `json:"kapasitas"`
+	SisaKursi      int       `json:"sisa_kursi"`
+}
+
+// findJadwalsOnDate mengambil jadwal pada tanggal tertentu untuk rute-rute
+// yang diberikan. Tanggal dihitung per rute karena zona waktunya bisa berbeda.
+func (h *Handler) findJadwalsOnDate(ctx context.Context, rutes []models.Rute, tanggal string) ([]models.Jadwal, error) {
+	keys := []store.RuteTanggal{}
+	for _, r := range rutes {
+		day, err := parseWithLayouts(tanggal, tanggalLayouts, loadZonaWaktu(r.ZonaWaktu))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, store.RuteTanggal{RuteID: r.ID, Tanggal: day})
+	}
+	if len(keys) == 0 {
+		return []models.Jadwal{}, nil
+	}
+	return h.Store.Jadwal.FindByRuteTanggal(ctx, keys)
+}
+
+// findKendaraanByIDs mengambil kendaraan sekaligus dalam satu query
+func (h *Handler) findKendaraanByIDs(ctx context.Context, jadwals []models.Jadwal) (map[primitive.ObjectID]models.Kendaraan, error) {
+	ids := []primitive.ObjectID{}
+	for _, j := range jadwals {
+		ids = append(ids, j.KendaraanID)
+	}
+
+	if len(ids) == 0 {
+		return map[primitive.ObjectID]models.Kendaraan{}, nil
+	}
+	return h.Store.Kendaraan.GetMany(ctx, ids)
+}
+
+// SearchJadwal godoc
+// @Summary Search journeys
+// @Description Mencari keberangkatan berdasarkan kota asal, tujuan dan tanggal beserta sisa kursi dan jenis kendaraan
+// @Tags Search
+// @Accept json
+// @Produce json
+// @Param asal query string true "Kota asal"
+// @Param tujuan query string true "Kota tujuan"
+// @Param tanggal query string true "Tanggal keberangkatan (YYYY-MM-DD)"
+// @Param sort query string false "Urutan: berangkat (default) atau durasi"
+// @Success 200 {array} SearchResult "Daftar keberangkatan"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/search [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+func (h *Handler) SearchJadwal(c *fiber.Ctx) error {
+	asal := c.Query("asal")
+	tujuan := c.Query("tujuan")
+	tanggal := c.Query("tanggal")
+	sortBy := c.Query("sort", "berangkat")
+
+	if asal == "" || tujuan == "" || tanggal == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "asal, tujuan dan tanggal wajib diisi"})
+	}
+	if sortBy != "berangkat" && sortBy != "durasi" {
+		return c.Status(400).JSON(fiber.Map{"error": "sort harus berangkat atau durasi"})
+	}
+	if _, err := parseWithLayouts(tanggal, tanggalLayouts, time.UTC); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Format tanggal tidak valid"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rutes, err := h.Store.Rute.FindByAsalTujuan(ctx, asal, tujuan)
+	if err != nil {
+		fmt.Println("❌ Error saat mencari rute:", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	jadwals, err := h.findJadwalsOnDate(ctx, rutes, tanggal)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	kendaraans, err := h.findKendaraanByIDs(ctx, jadwals)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ruteByID := map[primitive.ObjectID]models.Rute{}
+	for _, r := range rutes {
+		ruteByID[r.ID] = r
+	}
+
+	results := []SearchResult{}
+	for _, j := range jadwals {
+		rute := ruteByID[j.RuteID]
+		kendaraan := kendaraans[j.KendaraanID]
+		localizeJadwal(&j, loadZonaWaktu(rute.ZonaWaktu))
+
+		sisa := kendaraan.Kapasitas - j.KursiTerisi
+		if sisa < 0 {
+			sisa = 0
+		}
+
+		results = append(results, SearchResult{
+			JadwalID:       j.ID.Hex(),
+			KodeRute:       rute.KodeRute,
+			NamaRute:       rute.NamaRute,
+			Asal:           rute.Asal,
+			Tujuan:         rute.Tujuan,
+			JarakKM:        rute.JarakKM,
+			WaktuBerangkat: j.WaktuBerangkat,
+			EstimasiTiba:   j.EstimasiTiba,
+			DurasiMenit:    int(j.EstimasiTiba.Sub(j.WaktuBerangkat).Minutes()),
+			JenisKendaraan: kendaraan.Jenis,
+			NomorPolisi:    kendaraan.NomorPolisi,
+			Kapasitas:      kendaraan.Kapasitas,
+			SisaKursi:      sisa,
+		})
+	}
+
+	sort.SliceStable(results, func(a, b int) bool {
+		if sortBy == "durasi" && results[a].DurasiMenit != results[b].DurasiMenit {
+			return results[a].DurasiMenit < results[b].DurasiMenit
+		}
+		return results[a].WaktuBerangkat.Before(results[b].WaktuBerangkat)
+	})
+
+	return c.JSON(results)
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// randomToken menghasilkan string acak yang aman dipakai di URL
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRefreshToken membuat refresh token berformat "<session id>.<rahasia>"
+// beserta hash rahasianya yang disimpan di session
+func newRefreshToken(sessionID primitive.ObjectID) (string, string, error) {
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return sessionID.Hex() + "." + secret, hashToken(secret), nil
+}
+
+func splitRefreshToken(token string) (primitive.ObjectID, string, error) {
+	id, secret, ok := strings.Cut(token, ".")
+	if !ok || secret == "" {
+		return primitive.NilObjectID, "", errors.New("refresh token tidak valid")
+	}
+	sessionID, err := primitive.ObjectIDFromHex(id)
+	return sessionID, secret, err
+}
+
+func (h *Handler) signAccessToken(user models.User, session models.Session) (string, error) {
+	claims := jwt.MapClaims{
+		"username": user.Username,
+		"email":    user.Email,
+		"user_id":  user.ID.Hex(),
+		"role":     user.Role,
+		"sid":      session.ID.Hex(),
+		"mfa":      session.MFA,
+		"exp":      time.Now().Add(accessTokenTTL).Unix(),
+	}
+
+	return h.Keys.Sign(claims)
+}
+
+// issueSession membuat session baru untuk user yang berhasil login dan
+// mengembalikan access token serta refresh token-nya. mfa menandai login
+// yang sudah diverifikasi dengan kode TOTP atau MFA identity provider.
+func (h *Handler) issueSession(ctx context.Context, user models.User, mfa bool) (LoginResponse, error) {
+	now := time.Now()
+	session := models.Session{
+		ID:         primitive.NewObjectID(),
+		UserID:     user.ID,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(refreshTokenTTL),
+		MFA:        mfa,
+	}
+
+	refresh, hash, err := newRefreshToken(session.ID)
+	if err != nil {
+		return LoginResponse{}, err
+	}
+	session.RefreshHash = hash
+
+	if err := h.Store.Session.Create(ctx, &session); err != nil {
+		return LoginResponse{}, err
+	}
+
+	access, err := h.signAccessToken(user, session)
+	if err != nil {
+		return LoginResponse{}, err
+	}
+	return LoginResponse{Token: access, RefreshToken: refresh, Role: user.Role}, nil
+}
+
+// Mengambil session id dari token JWT yang sudah divalidasi middleware.Protected
+func getSessionIDFromToken(c *fiber.Ctx) (primitive.ObjectID, error) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return primitive.NilObjectID, errors.New("token tidak ditemukan")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return primitive.NilObjectID, errors.New("claims tidak valid")
+	}
+	sid, _ := claims["sid"].(string)
+	return primitive.ObjectIDFromHex(sid)
+}
+
+// Mengambil claim mfa dari token JWT, false jika tidak ada
+func getMFAFromToken(c *fiber.Ctx) bool {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	mfa, _ := claims["mfa"].(bool)
+	return mfa
+}
+
+// RefreshToken godoc
+// @Summary Refresh the access token
+// @Description Menukar refresh token dengan access token dan refresh token baru. Refresh token lama tidak bisa dipakai lagi; jika dipakai ulang, session dicabut
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param token body RefreshRequest true "Refresh token"
+// @Success 200 {object} LoginResponse "Token baru"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Refresh token tidak valid, kedaluwarsa atau sudah dicabut"
+// @Failure 403 {object} models.ErrorResponse "Akun dinonaktifkan"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/token/refresh [post]
+func (h *Handler) RefreshToken(c *fiber.Ctx) error {
+	var input RefreshRequest
+	if err := c.BodyParser(&input); err != nil || input.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "refresh_token wajib diisi"})
+	}
+
+	sessionID, secret, err := splitRefreshToken(input.RefreshToken)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Refresh token tidak valid"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := h.Store.Session.Get(ctx, sessionID)
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Refresh token tidak valid"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !session.Active(time.Now()) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Session sudah berakhir, silakan login ulang"})
+	}
+
+	oldHash := hashToken(secret)
+	newToken, newHash, err := newRefreshToken(session.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Hash yang tidak cocok berarti token lama dipakai ulang, kemungkinan
+	// karena bocor. Session dicabut agar pemegang token yang baru juga
+	// harus login ulang.
+	err = store.ErrNotFound
+	if subtle.ConstantTimeCompare([]byte(oldHash), []byte(session.RefreshHash)) == 1 {
+		err = h.Store.Session.Rotate(ctx, session.ID, oldHash, newHash, time.Now().Add(refreshTokenTTL))
+	}
+	if err == store.ErrNotFound {
+		fmt.Println("⚠️ Refresh token dipakai ulang, session dicabut:", session.ID.Hex())
+		if err := h.Store.Session.Revoke(ctx, session.ID, models.SessionRevokedReuse); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Refresh token sudah pernah dipakai, silakan login ulang"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Data user diambil ulang agar perubahan role ikut masuk ke token baru
+	user, err := h.Store.User.Get(ctx, session.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if user.Disabled {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Akun dinonaktifkan"})
+	}
+
+	access, err := h.signAccessToken(user, session)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membuat token autentikasi"})
+	}
+
+	return c.JSON(LoginResponse{Token: access, RefreshToken: newToken, Role: user.Role})
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Mencabut session token yang sedang dipakai. Access token dan refresh token session ini langsung tidak berlaku
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.SuccessResponse "Logout berhasil"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/logout [post]
+// @Security BearerAuth
+func (h *Handler) Logout(c *fiber.Ctx) error {
+	sessionID, err := getSessionIDFromToken(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Token tidak valid"})
+	}
+
+	if err := h.Store.Session.Revoke(context.TODO(), sessionID, models.SessionRevokedLogout); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Logout berhasil"})
+}
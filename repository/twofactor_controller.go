@@ -0,0 +1,453 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"transport-app/models"
+	"transport-app/store"
+	"transport-app/totp"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	mfaChallengeTTL   = 5 * time.Minute
+	mfaChallengeType  = "mfa_challenge"
+	recoveryCodeCount = 10
+	totpSkew          = 1 // Toleransi selisih jam satu periode (30 detik)
+)
+
+type TwoFactorChallengeResponse struct {
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int    `json:"expires_in"` // detik
+}
+
+// TwoFactorLoginRequest diisi dengan Code dari aplikasi authenticator atau
+// salah satu RecoveryCode
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"` // Dijadikan QR code oleh frontend
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password     string `json:"password"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorEnableResponse berisi recovery code (hanya ditampilkan sekali)
+// dan session baru yang sudah terverifikasi 2FA
+type TwoFactorEnableResponse struct {
+	LoginResponse
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Transport App"
+}
+
+// signChallengeToken membuat token langkah kedua login. Token ini tidak
+// punya claim sid sehingga ditolak middleware.Protected.
+func (h *Handler) signChallengeToken(user models.User) (string, error) {
+	return h.Keys.Sign(jwt.MapClaims{
+		"typ":     mfaChallengeType,
+		"user_id": user.ID.Hex(),
+		"exp":     time.Now().Add(mfaChallengeTTL).Unix(),
+	})
+}
+
+func (h *Handler) parseChallengeToken(raw string) (primitive.ObjectID, error) {
+	token, err := jwt.Parse(raw, h.Keys.KeyFunc)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != mfaChallengeType {
+		return primitive.NilObjectID, errors.New("bukan challenge token")
+	}
+	id, _ := claims["user_id"].(string)
+	return primitive.ObjectIDFromHex(id)
+}
+
+// normalizeRecoveryCode membuat recovery code tidak peka huruf besar/kecil,
+// spasi dan tanda hubung
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// newRecoveryCodes membuat recovery code baru beserta hash yang disimpan
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		secret, err := totp.GenerateSecret()
+		if err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(secret[:5] + "-" + secret[5:10])
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+// verifySecondFactor memeriksa kode TOTP atau recovery code user. Kode
+// TOTP yang sudah pernah dipakai dan recovery code yang sudah dipakai ditolak.
+func (h *Handler) verifySecondFactor(ctx context.Context, user models.User, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		err := h.Store.User.UseRecoveryCode(ctx, user.ID, hashToken(normalizeRecoveryCode(recoveryCode)))
+		if err == store.ErrNotFound {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	step, ok := totp.Validate(user.TOTP.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+	err := h.Store.User.UseTOTPStep(ctx, user.ID, step)
+	if err == store.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// LoginTwoFactor godoc
+// @Summary Complete a two-factor login
+// @Description Langkah kedua login untuk user dengan 2FA. Tukar challenge_token dari /api/login dan kode TOTP (atau recovery code) dengan access token dan refresh token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorLoginRequest true "Challenge token dan kode"
+// @Success 200 {object} LoginResponse "Login berhasil"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Challenge token atau kode tidak valid"
+// @Failure 429 {object} models.ErrorResponse "Terlalu banyak percobaan login gagal"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/login/2fa [post]
+func (h *Handler) LoginTwoFactor(c *fiber.Ctx) error {
+	var input TwoFactorLoginRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	if input.ChallengeToken == "" || (input.Code == "" && input.RecoveryCode == "") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "challenge_token dan code atau recovery_code wajib diisi"})
+	}
+
+	userID, err := h.parseChallengeToken(input.ChallengeToken)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Challenge token tidak valid atau kedaluwarsa, silakan login ulang"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := h.Store.User.Get(ctx, userID)
+	if err != nil || !user.TOTP.Enabled || user.Disabled {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Challenge token tidak valid atau kedaluwarsa, silakan login ulang"})
+	}
+
+	// Kode 6 digit mudah ditebak, jadi kegagalan dihitung bersama login password
+	if wait := h.beginLoginAttempt(ctx, c, user.Username); wait > 0 {
+		return loginLockedResponse(c, wait)
+	}
+	ok, err := h.verifySecondFactor(ctx, user, input.Code, input.RecoveryCode)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Kode tidak valid"})
+	}
+	h.loginSucceeded(ctx, c, user.Username)
+
+	res, err := h.issueSession(ctx, user, true)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membuat token autentikasi"})
+	}
+	return c.JSON(res)
+}
+
+// SetupTwoFactor godoc
+// @Summary Start two-factor enrolment
+// @Description Membuat secret TOTP baru dan URI otpauth:// untuk QR code. 2FA baru aktif setelah dikonfirmasi lewat /api/me/2fa/enable
+// @Tags Profile
+// @Produce json
+// @Success 200 {object} TwoFactorSetupResponse "Secret dan URI provisioning"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 409 {object} models.ErrorResponse "2FA sudah aktif"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/me/2fa/setup [post]
+// @Security BearerAuth
+func (h *Handler) SetupTwoFactor(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := h.currentUser(ctx, c)
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if user.TOTP.Enabled {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "2FA sudah aktif"})
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.Store.User.SetTOTP(ctx, user.ID, models.TOTP{Secret: secret}); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURL: totp.ProvisioningURI(totpIssuer(), user.Email, secret),
+	})
+}
+
+// EnableTwoFactor godoc
+// @Summary Confirm two-factor enrolment
+// @Description Mengaktifkan 2FA dengan kode pertama dari aplikasi authenticator. Recovery code hanya ditampilkan sekali, dan session baru yang sudah terverifikasi 2FA dikembalikan
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeRequest true "Kode TOTP"
+// @Success 200 {object} TwoFactorEnableResponse "2FA aktif"
+// @Failure 400 {object} models.ErrorResponse "Setup belum dilakukan atau kode tidak valid"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 409 {object} models.ErrorResponse "2FA sudah aktif"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/me/2fa/enable [post]
+// @Security BearerAuth
+func (h *Handler) EnableTwoFactor(c *fiber.Ctx) error {
+	var input TwoFactorCodeRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := h.currentUser(ctx, c)
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if user.TOTP.Enabled {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "2FA sudah aktif"})
+	}
+	if user.TOTP.Secret == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Jalankan /api/me/2fa/setup terlebih dahulu"})
+	}
+
+	step, ok := totp.Validate(user.TOTP.Secret, input.Code, time.Now(), totpSkew)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Kode tidak valid"})
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	user.TOTP = models.TOTP{Secret: user.TOTP.Secret, Enabled: true, RecoveryCodes: hashes, LastStep: step}
+	if err := h.Store.User.SetTOTP(ctx, user.ID, user.TOTP); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Session lama dibuat tanpa 2FA, jadi diganti dengan session baru
+	if sid, err := getSessionIDFromToken(c); err == nil {
+		if err := h.Store.Session.Revoke(ctx, sid, models.SessionRevokedLogout); err != nil {
+			fmt.Println("❌ Gagal mencabut session:", err)
+		}
+	}
+	res, err := h.issueSession(ctx, user, true)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membuat token autentikasi"})
+	}
+
+	return c.JSON(TwoFactorEnableResponse{LoginResponse: res, RecoveryCodes: codes})
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable two-factor authentication
+// @Description Menonaktifkan 2FA dengan konfirmasi password dan kode TOTP atau recovery code
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorDisableRequest true "Password dan kode"
+// @Success 200 {object} models.SuccessResponse "2FA dinonaktifkan"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized, password atau kode salah"
+// @Failure 429 {object} models.ErrorResponse "Terlalu banyak percobaan gagal"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/me/2fa/disable [post]
+// @Security BearerAuth
+func (h *Handler) DisableTwoFactor(c *fiber.Ctx) error {
+	var input TwoFactorDisableRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := h.currentUser(ctx, c)
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !user.TOTP.Enabled {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "2FA belum aktif"})
+	}
+	// Password dan kode yang salah dihitung seperti login gagal agar token
+	// yang dicuri tidak bisa dipakai untuk menebaknya
+	if wait := h.beginLoginAttempt(ctx, c, user.Username); wait > 0 {
+		return loginLockedResponse(c, wait)
+	}
+	if !checkPasswordHash(input.Password, user.Password) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Password salah"})
+	}
+	ok, err := h.verifySecondFactor(ctx, user, input.Code, input.RecoveryCode)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Kode tidak valid"})
+	}
+	h.loginSucceeded(ctx, c, user.Username)
+
+	if err := h.Store.User.SetTOTP(ctx, user.ID, models.TOTP{}); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "2FA dinonaktifkan"})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Membuat recovery code baru dengan konfirmasi kode TOTP. Recovery code lama tidak berlaku lagi
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeRequest true "Kode TOTP"
+// @Success 200 {object} RecoveryCodesResponse "Recovery code baru"
+// @Failure 400 {object} models.ErrorResponse "2FA belum aktif"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized atau kode salah"
+// @Failure 429 {object} models.ErrorResponse "Terlalu banyak percobaan gagal"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/me/2fa/recovery-codes [post]
+// @Security BearerAuth
+func (h *Handler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var input TwoFactorCodeRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := h.currentUser(ctx, c)
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !user.TOTP.Enabled {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "2FA belum aktif"})
+	}
+	if wait := h.beginLoginAttempt(ctx, c, user.Username); wait > 0 {
+		return loginLockedResponse(c, wait)
+	}
+	ok, err := h.verifySecondFactor(ctx, user, input.Code, "")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Kode tidak valid"})
+	}
+	h.loginSucceeded(ctx, c, user.Username)
+
+	// Data user dibaca ulang karena LastStep baru saja diubah verifySecondFactor
+	user, err = h.Store.User.Get(ctx, user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	user.TOTP.RecoveryCodes = hashes
+	if err := h.Store.User.SetTOTP(ctx, user.ID, user.TOTP); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// ResetUserTwoFactor godoc
+// @Summary Reset a user's two-factor authentication
+// @Description Menonaktifkan 2FA user yang kehilangan perangkat dan recovery code-nya. Semua session user dicabut
+// @Tags User
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.SuccessResponse "2FA user direset"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID atau akun sendiri"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden atau user dengan role *"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{id}/2fa [delete]
+// @Security BearerAuth
+func (h *Handler) ResetUserTwoFactor(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id, status, msg := h.manageableUser(ctx, c)
+	if msg != "" {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	err := h.Store.User.SetTOTP(ctx, id, models.TOTP{})
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.Store.Session.RevokeAllForUser(ctx, id, models.SessionRevokedReset); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencabut session"})
+	}
+	return c.JSON(fiber.Map{"message": "2FA user direset"})
+}
@@ -0,0 +1,33 @@
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"transport-app/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestDisableTwoFactorLockout memastikan kode yang salah saat menonaktifkan
+// 2FA dihitung seperti login gagal, sehingga token yang dicuri tidak bisa
+// dipakai untuk menebak kode 6 digit
+func TestDisableTwoFactorLockout(t *testing.T) {
+	s := newTestServer(t)
+	user := s.createUser(t, "budi", models.RoleUser)
+	token := s.login(t, "budi")
+	if err := s.store.User.SetTOTP(context.Background(), user.ID, models.TOTP{Secret: "JBSWY3DPEHPK3PXP", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	input := fiber.Map{"password": testPassword, "code": "000000"}
+	for i := 0; i < 4; i++ {
+		s.expect(t, s.request(t, http.MethodPost, "/api/me/2fa/disable", token, input), http.StatusUnauthorized, nil)
+	}
+	res := s.request(t, http.MethodPost, "/api/me/2fa/disable", token, input)
+	if res.Header.Get(fiber.HeaderRetryAfter) == "" {
+		t.Fatal("respons terkunci tanpa Retry-After")
+	}
+	s.expect(t, res, http.StatusTooManyRequests, nil)
+}
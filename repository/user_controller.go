@@ -0,0 +1,403 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"transport-app/models"
+	"transport-app/query"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var userSchema = query.Schema{
+	"username":            {BSON: "username", Kind: query.String},
+	"email":               {BSON: "email", Kind: query.String},
+	"role":                {BSON: "role", Kind: query.String},
+	"email_verified":      {BSON: "email_verified", Kind: query.Bool},
+	"disabled":            {BSON: "disabled", Kind: query.Bool},
+	"must_reset_password": {BSON: "must_reset_password", Kind: query.Bool},
+}
+
+// ErrAdminExists dikembalikan BootstrapAdmin jika sudah ada user admin
+var ErrAdminExists = errors.New("sudah ada user dengan role admin")
+
+// publicPage mengganti isi halaman user dengan data tanpa hash password
+func publicPage(page query.Page[models.User]) query.Page[models.PublicUser] {
+	result := query.Page[models.PublicUser]{
+		Data:       []models.PublicUser{},
+		Total:      page.Total,
+		Page:       page.Page,
+		Limit:      page.Limit,
+		NextCursor: page.NextCursor,
+	}
+	for _, user := range page.Data {
+		result.Data = append(result.Data, user.Public())
+	}
+	return result
+}
+
+// adminTargetUser membaca :id dan menolak jika yang dituju adalah admin
+// yang sedang login, agar admin tidak mengunci akunnya sendiri
+func adminTargetUser(c *fiber.Ctx) (primitive.ObjectID, string) {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return id, "Invalid ID"
+	}
+	if self, err := getUserIDFromToken(c); err == nil && self == id {
+		return id, "Tidak bisa melakukan aksi ini pada akun sendiri"
+	}
+	return id, ""
+}
+
+// manageableUser memeriksa target aksi admin: bukan akun sendiri, dan user
+// dengan role * hanya bisa dikelola oleh pemegang * lainnya
+func (h *Handler) manageableUser(ctx context.Context, c *fiber.Ctx) (primitive.ObjectID, int, string) {
+	id, msg := adminTargetUser(c)
+	if msg != "" {
+		return id, 400, msg
+	}
+	caller, err := h.callerRole(ctx, c)
+	if err != nil {
+		return id, 500, err.Error()
+	}
+	status, msg := h.checkUserTarget(ctx, caller, id)
+	return id, status, msg
+}
+
+// GetAllUsers godoc
+// @Summary List users
+// @Description Mengambil data user dengan pencarian, filter, sort dan pagination. Hash password tidak pernah dikembalikan
+// @Tags User
+// @Produce json
+// @Param q query string false "Cari pada username atau email"
+// @Param page query int false "Nomor halaman (default 1)"
+// @Param limit query int false "Jumlah data per halaman (default 20, maks 100)"
+// @Param cursor query string false "Cursor dari next_cursor halaman sebelumnya"
+// @Param sort query string false "Urutan, mis. role,-username"
+// @Param role query string false "Filter role"
+// @Param disabled query bool false "Filter akun nonaktif"
+// @Success 200 {object} query.Page[models.PublicUser] "Daftar user"
+// @Failure 400 {object} models.ErrorResponse "Query tidak valid"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users [get]
+// @Security BearerAuth
+func (h *Handler) GetAllUsers(c *fiber.Ctx) error {
+	q, err := query.Parse(c, userSchema, []query.Sort{{Field: "username"}})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	page, err := h.Store.User.List(ctx, q)
+	if err != nil {
+		fmt.Println("❌ Error saat mengambil user:", err.Error())
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(publicPage(page).WithNext(c))
+}
+
+// GetUserByID godoc
+// @Summary Get a user by ID
+// @Description Mengambil data satu user tanpa hash password
+// @Tags User
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.PublicUser "Data user"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Router /api/admin/users/{id} [get]
+// @Security BearerAuth
+func (h *Handler) GetUserByID(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	user, err := h.Store.User.Get(context.TODO(), id)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(user.Public())
+}
+
+// DisableUser godoc
+// @Summary Disable a user
+// @Description Menonaktifkan akun user. Semua session user langsung dicabut dan user tidak bisa login sampai diaktifkan lagi
+// @Tags User
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.SuccessResponse "User dinonaktifkan"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID atau akun sendiri"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden atau user dengan role *"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{id}/disable [post]
+// @Security BearerAuth
+func (h *Handler) DisableUser(c *fiber.Ctx) error {
+	return h.setUserDisabled(c, true)
+}
+
+// EnableUser godoc
+// @Summary Enable a user
+// @Description Mengaktifkan kembali akun user yang dinonaktifkan
+// @Tags User
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.SuccessResponse "User diaktifkan"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID atau akun sendiri"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden atau user dengan role *"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{id}/enable [post]
+// @Security BearerAuth
+func (h *Handler) EnableUser(c *fiber.Ctx) error {
+	return h.setUserDisabled(c, false)
+}
+
+func (h *Handler) setUserDisabled(c *fiber.Ctx, disabled bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id, status, msg := h.manageableUser(ctx, c)
+	if msg != "" {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	err := h.Store.User.SetDisabled(ctx, id, disabled)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if !disabled {
+		return c.JSON(fiber.Map{"message": "User diaktifkan"})
+	}
+	if err := h.Store.Session.RevokeAllForUser(ctx, id, models.SessionRevokedDisabled); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencabut session"})
+	}
+	return c.JSON(fiber.Map{"message": "User dinonaktifkan"})
+}
+
+// ForceResetPassword godoc
+// @Summary Force a password reset
+// @Description Memaksa user mengganti password. Semua session user dicabut, login ditolak sampai password direset, dan link reset dikirim ke email user
+// @Tags User
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.SuccessResponse "Reset password dipaksa"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID atau akun sendiri"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden atau user dengan role *"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{id}/reset-password [post]
+// @Security BearerAuth
+func (h *Handler) ForceResetPassword(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	id, status, msg := h.manageableUser(ctx, c)
+	if msg != "" {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	user, err := h.Store.User.Get(ctx, id)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.Store.User.SetMustResetPassword(ctx, id, true); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.Store.Session.RevokeAllForUser(ctx, id, models.SessionRevokedReset); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencabut session"})
+	}
+
+	// Jika email gagal terkirim user masih bisa meminta link baru lewat
+	// /api/password/forgot
+	if err := h.sendPasswordResetEmail(ctx, user, "Admin meminta Anda mengganti password sebelum bisa login lagi."); err != nil {
+		fmt.Println("❌ Gagal mengirim email reset password:", err)
+		return c.JSON(fiber.Map{"message": "Reset password dipaksa, tetapi email gagal dikirim"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Reset password dipaksa, link reset dikirim ke email user"})
+}
+
+// DeleteUser godoc
+// @Summary Delete a user
+// @Description Menghapus user beserta session dan token miliknya. Booking user tetap disimpan
+// @Tags User
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.SuccessResponse "User dihapus"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID atau akun sendiri"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden atau user dengan role *"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteUser(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id, status, msg := h.manageableUser(ctx, c)
+	if msg != "" {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	err := h.Store.User.Delete(ctx, id)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	h.cleanupDeletedUser(ctx, id)
+
+	return c.JSON(fiber.Map{"message": "User dihapus"})
+}
+
+// cleanupDeletedUser mencabut session dan menghapus token milik user yang
+// sudah dihapus. Kegagalan hanya dicatat karena user sudah tidak ada.
+func (h *Handler) cleanupDeletedUser(ctx context.Context, id primitive.ObjectID) {
+	if err := h.Store.Session.RevokeAllForUser(ctx, id, models.SessionRevokedDeleted); err != nil {
+		fmt.Println("❌ Gagal mencabut session:", err)
+	}
+	for _, purpose := range []string{models.TokenPurposePasswordReset, models.TokenPurposeEmailVerification} {
+		if err := h.Store.UserToken.DeleteForUser(ctx, id, purpose); err != nil {
+			fmt.Println("❌ Gagal menghapus token user:", err)
+		}
+	}
+}
+
+// UnlockUser godoc
+// @Summary Unlock a user's login
+// @Description Menghapus hitungan login gagal dan kunci login untuk username user
+// @Tags User
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.SuccessResponse "Kunci login dibuka"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{id}/unlock [post]
+// @Security BearerAuth
+func (h *Handler) UnlockUser(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := h.Store.User.Get(ctx, id)
+	if err == store.ErrNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.Store.LoginAttempt.Reset(ctx, accountAttemptKey(user.Username)); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Kunci login dibuka"})
+}
+
+// UnlockIP godoc
+// @Summary Unlock an IP address
+// @Description Menghapus hitungan login gagal dan kunci login untuk satu alamat IP
+// @Tags User
+// @Produce json
+// @Param ip path string true "Alamat IP"
+// @Success 200 {object} models.SuccessResponse "Kunci login dibuka"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/admin/lockouts/ip/{ip} [delete]
+// @Security BearerAuth
+func (h *Handler) UnlockIP(c *fiber.Ctx) error {
+	if err := h.Store.LoginAttempt.Reset(context.TODO(), ipAttemptKey(c.Params("ip"))); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Kunci login dibuka"})
+}
+
+// BootstrapAdmin membuat admin pertama. Jika username sudah terdaftar, user
+// tersebut dinaikkan menjadi admin dan password-nya tidak diubah. Ditolak
+// dengan ErrAdminExists jika sudah ada admin.
+func (h *Handler) BootstrapAdmin(ctx context.Context, username, email, password string) (models.User, error) {
+	count, err := h.Store.User.CountByRole(ctx, models.RoleAdmin)
+	if err != nil {
+		return models.User{}, err
+	}
+	if count > 0 {
+		return models.User{}, ErrAdminExists
+	}
+
+	user, err := h.Store.User.GetByUsername(ctx, username)
+	if err == nil {
+		if err := h.Store.User.SetRole(ctx, user.ID, models.RoleAdmin); err != nil {
+			return user, err
+		}
+		user.Role = models.RoleAdmin
+		return user, nil
+	}
+	if err != store.ErrNotFound {
+		return user, err
+	}
+
+	if username == "" || !isEmailValid(email) {
+		return user, errors.New("username dan email yang valid wajib diisi")
+	}
+	if msg := validatePassword(password, password); msg != "" {
+		return user, errors.New(msg)
+	}
+	exists, err := h.Store.User.ExistsByUsernameOrEmail(ctx, username, email)
+	if err != nil {
+		return user, err
+	}
+	if exists {
+		return user, errors.New("email sudah dipakai user lain")
+	}
+
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return user, err
+	}
+	user = models.User{
+		Username:      username,
+		Email:         email,
+		Password:      hashedPassword,
+		Role:          models.RoleAdmin,
+		EmailVerified: true,
+	}
+	return user, h.Store.User.Create(ctx, &user)
+}
@@ -0,0 +1,43 @@
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"transport-app/models"
+)
+
+// TestManageAdminUser memastikan pemegang user:manage tanpa * tidak bisa
+// menonaktifkan, menghapus atau mereset akun admin
+func TestManageAdminUser(t *testing.T) {
+	s := newTestServer(t)
+	pengelola := models.Role{Name: "pengelola", Permissions: []string{models.PermUserManage}}
+	if err := s.store.Role.Create(context.Background(), &pengelola); err != nil {
+		t.Fatal(err)
+	}
+	s.createUser(t, "pengelola", "pengelola")
+	admin := s.createUser(t, "admin", models.RoleAdmin)
+	admin2 := s.createUser(t, "admin2", models.RoleAdmin)
+	user := s.createUser(t, "budi", models.RoleUser)
+	token := s.login(t, "pengelola")
+
+	for _, tt := range []struct{ method, path string }{
+		{http.MethodPost, "/disable"},
+		{http.MethodPost, "/enable"},
+		{http.MethodPost, "/reset-password"},
+		{http.MethodDelete, "/2fa"},
+		{http.MethodDelete, ""},
+	} {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			s.expect(t, s.request(t, tt.method, "/api/admin/users/"+admin.ID.Hex()+tt.path, token, nil), http.StatusForbidden, nil)
+		})
+	}
+	if _, err := s.store.User.Get(context.Background(), admin.ID); err != nil {
+		t.Fatalf("admin terhapus: %v", err)
+	}
+
+	s.expect(t, s.request(t, http.MethodPost, "/api/admin/users/"+user.ID.Hex()+"/disable", token, nil), http.StatusOK, nil)
+	// Sesama pemegang * tetap bisa saling mengelola
+	s.expect(t, s.request(t, http.MethodPost, "/api/admin/users/"+admin2.ID.Hex()+"/disable", s.login(t, "admin"), nil), http.StatusOK, nil)
+}
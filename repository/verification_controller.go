@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"transport-app/mailer"
+	"transport-app/models"
+	"transport-app/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	// resendVerifikasiJeda adalah jarak minimal antar email verifikasi ke
+	// satu user
+	resendVerifikasiJeda = time.Minute
+	// resendVerifikasiIPLimit adalah batas permintaan kirim ulang dari satu
+	// IP dalam resendVerifikasiWindow
+	resendVerifikasiIPLimit = 10
+	resendVerifikasiWindow  = time.Hour
+)
+
+func resendVerifikasiIPKey(ip string) string {
+	return "resend:ip:" + ip
+}
+
+// Nilai REQUIRE_EMAIL_VERIFICATION: kosong berarti akun langsung bisa
+// dipakai, "login" menolak login, "booking" hanya menolak pemesanan kursi
+// sampai email diverifikasi
+const (
+	verifikasiUntukLogin   = "login"
+	verifikasiUntukBooking = "booking"
+)
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+func emailVerificationMode() string {
+	return os.Getenv("REQUIRE_EMAIL_VERIFICATION")
+}
+
+// sendVerificationEmail membuat token verifikasi baru dan mengirimkannya
+func (h *Handler) sendVerificationEmail(ctx context.Context, user models.User) error {
+	token, err := h.issueUserToken(ctx, user.ID, models.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return h.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email",
+		Body: fmt.Sprintf("Halo %s,\n\nGunakan link atau token berikut untuk memverifikasi email Anda. "+
+			"Token berlaku selama 24 jam.\n\n%s\n",
+			user.Username, appLink("/verify-email", token)),
+	})
+}
+
+// VerifyEmail godoc
+// @Summary Verify an email address
+// @Description Menandai email user sebagai terverifikasi memakai token dari email pendaftaran
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "Token verifikasi"
+// @Success 200 {object} models.SuccessResponse "Email terverifikasi"
+// @Failure 400 {object} models.ErrorResponse "Token tidak valid atau kedaluwarsa"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/email/verify [post]
+func (h *Handler) VerifyEmail(c *fiber.Ctx) error {
+	var input VerifyEmailRequest
+	if err := c.BodyParser(&input); err != nil || input.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Token wajib diisi"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token, err := h.Store.UserToken.Consume(ctx, models.TokenPurposeEmailVerification, hashToken(input.Token))
+	if err == store.ErrNotFound {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Token tidak valid atau sudah kedaluwarsa"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.Store.User.SetEmailVerified(ctx, token.UserID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal memverifikasi email"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Email berhasil diverifikasi"})
+}
+
+// ResendVerification godoc
+// @Summary Resend the verification email
+// @Description Mengirim ulang email verifikasi. Response selalu sama, terdaftar atau tidak emailnya. Email hanya dikirim sekali per menit untuk setiap akun, dan permintaan dibatasi per IP
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body ResendVerificationRequest true "Email akun"
+// @Success 200 {object} models.SuccessResponse "Email verifikasi dikirim jika akun belum terverifikasi"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 429 {object} models.ErrorResponse "Terlalu banyak permintaan email verifikasi dari IP ini"
+// @Router /api/email/resend [post]
+func (h *Handler) ResendVerification(c *fiber.Ctx) error {
+	var input ResendVerificationRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	if !isEmailValid(input.Email) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Format email tidak valid"})
+	}
+
+	response := fiber.Map{"message": "Jika akun belum terverifikasi, email verifikasi sudah dikirim"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Seperti lupa password, setiap permintaan dihitung per IP, terdaftar
+	// atau tidak emailnya, sehingga 429 tidak membedakan akun yang ada
+	now := time.Now()
+	attempt, err := h.Store.LoginAttempt.RecordFailure(ctx, resendVerifikasiIPKey(c.IP()), now, resendVerifikasiWindow)
+	if err != nil {
+		fmt.Println("❌ Gagal mencatat permintaan email verifikasi:", err)
+	} else if attempt.Failures > resendVerifikasiIPLimit {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(resendVerifikasiWindow.Seconds())))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "Terlalu banyak permintaan email verifikasi, coba lagi nanti"})
+	}
+
+	user, err := h.Store.User.GetByEmail(ctx, input.Email)
+	if err != nil || user.EmailVerified {
+		return c.JSON(response)
+	}
+
+	// Seperti lupa password, email dikirim di latar belakang agar lama
+	// response tidak membedakan akun yang belum diverifikasi
+	h.goBackground("mengirim email verifikasi", func(ctx context.Context) error {
+		// Batas per akun tidak mengembalikan 429 karena itu akan membocorkan
+		// bahwa email terdaftar dan belum diverifikasi
+		last, err := h.Store.UserToken.Latest(ctx, user.ID, models.TokenPurposeEmailVerification)
+		if err == nil && now.Sub(last.CreatedAt) < resendVerifikasiJeda {
+			return nil
+		}
+		return h.sendVerificationEmail(ctx, user)
+	})
+
+	return c.JSON(response)
+}
+
+// requireVerifiedEmail dipakai sebelum aksi booking jika
+// REQUIRE_EMAIL_VERIFICATION=booking
+func (h *Handler) requireVerifiedEmail(ctx context.Context, c *fiber.Ctx) (bool, error) {
+	if emailVerificationMode() != verifikasiUntukBooking {
+		return true, nil
+	}
+	userID, err := getUserIDFromToken(c)
+	if err != nil {
+		return false, nil
+	}
+	user, err := h.Store.User.Get(ctx, userID)
+	if err == store.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return user.EmailVerified, nil
+}
@@ -0,0 +1,49 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"transport-app/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestResendVerificationThrottle memastikan batas kirim ulang tidak
+// membedakan email yang terdaftar dan belum diverifikasi dari email lain
+func TestResendVerificationThrottle(t *testing.T) {
+	t.Run("per akun tanpa 429", func(t *testing.T) {
+		s := newTestServer(t)
+		user := models.User{Username: "budi", Email: "budi@example.com", Role: models.RoleUser}
+		if err := s.store.User.Create(context.Background(), &user); err != nil {
+			t.Fatal(err)
+		}
+
+		var belum, lain fiber.Map
+		for i := 0; i < 3; i++ {
+			s.expect(t, s.request(t, http.MethodPost, "/api/email/resend", "", fiber.Map{"email": "budi@example.com"}), http.StatusOK, &belum)
+		}
+		s.expect(t, s.request(t, http.MethodPost, "/api/email/resend", "", fiber.Map{"email": "lain@example.com"}), http.StatusOK, &lain)
+		if belum["message"] != lain["message"] {
+			t.Fatalf("response berbeda untuk akun terdaftar: %v dan %v", belum, lain)
+		}
+		if n := s.mailer.count(); n != 1 {
+			t.Fatalf("%d email verifikasi dikirim, seharusnya 1", n)
+		}
+	})
+
+	t.Run("per IP", func(t *testing.T) {
+		s := newTestServer(t)
+		for i := 0; i < 10; i++ {
+			email := fmt.Sprintf("user%d@example.com", i)
+			s.expect(t, s.request(t, http.MethodPost, "/api/email/resend", "", fiber.Map{"email": email}), http.StatusOK, nil)
+		}
+		res := s.request(t, http.MethodPost, "/api/email/resend", "", fiber.Map{"email": "lain@example.com"})
+		if res.Header.Get(fiber.HeaderRetryAfter) == "" {
+			t.Error("response 429 tanpa Retry-After")
+		}
+		s.expect(t, res, http.StatusTooManyRequests, nil)
+	})
+}
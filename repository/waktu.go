@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"transport-app/models"
+
+	// Database zona waktu ikut di-embed agar tetap jalan di container tanpa tzdata
+	_ "time/tzdata"
+)
+
+const defaultZonaWaktu = "Asia/Jakarta"
+
+// Singkatan zona waktu Indonesia yang diterima sebagai input
+var zonaWaktuAlias = map[string]string{
+	"WIB":  "Asia/Jakarta",
+	"WITA": "Asia/Makassar",
+	"WIT":  "Asia/Jayapura",
+}
+
+var tanggalLayouts = []string{"2006-01-02", "02-01-2006", "02/01/2006"}
+var waktuLayouts = []string{"15:04", "15.04", "15:04:05"}
+
+// normalizeZonaWaktu mengubah WIB/WITA/WIT atau nama IANA menjadi nama IANA.
+// Zona kosong dianggap WIB.
+func normalizeZonaWaktu(zona string) (string, error) {
+	zona = strings.TrimSpace(zona)
+	if zona == "" {
+		return defaultZonaWaktu, nil
+	}
+	if iana, ok := zonaWaktuAlias[strings.ToUpper(zona)]; ok {
+		return iana, nil
+	}
+	if _, err := time.LoadLocation(zona); err != nil {
+		return "", errors.New("zona waktu tidak dikenali: " + zona)
+	}
+	return zona, nil
+}
+
+// loadZonaWaktu mengembalikan lokasi untuk zona rute, WIB jika tidak valid
+func loadZonaWaktu(zona string) *time.Location {
+	iana, err := normalizeZonaWaktu(zona)
+	if err != nil {
+		iana = defaultZonaWaktu
+	}
+	loc, err := time.LoadLocation(iana)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func parseWithLayouts(value string, layouts []string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("format tidak dikenali: " + value)
+}
+
+// errTibaSebelumBerangkat dikembalikan jika estimasi tiba berupa timestamp
+// lengkap yang tidak lebih besar dari waktu berangkat
+var errTibaSebelumBerangkat = errors.New("estimasi tiba harus setelah waktu berangkat")
+
+// parseJadwalWaktu mengubah input tanggal, waktu berangkat dan estimasi tiba
+// menjadi timestamp pada zona waktu rute. Selain RFC 3339, format lama
+// seperti "2025-07-20" dan "08:30" tetap diterima. Estimasi tiba berupa jam
+// saja yang tidak lebih besar dari waktu berangkat dianggap tiba keesokan
+// harinya; timestamp lengkap dipakai apa adanya dan ditolak dengan
+// errTibaSebelumBerangkat.
+func parseJadwalWaktu(tanggal, berangkat, tiba string, loc *time.Location) (time.Time, time.Time, time.Time, error) {
+	var day time.Time
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(tanggal)); err == nil {
+		t = t.In(loc)
+		day = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	} else {
+		day, err = parseWithLayouts(tanggal, tanggalLayouts, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, time.Time{}, err
+		}
+	}
+
+	dep, err := parseJamPadaTanggal(berangkat, day, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, err
+	}
+	arr, err := parseJamPadaTanggal(tiba, day, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, err
+	}
+	if !arr.After(dep) {
+		if isRFC3339(tiba) {
+			return time.Time{}, time.Time{}, time.Time{}, errTibaSebelumBerangkat
+		}
+		arr = arr.AddDate(0, 0, 1)
+	}
+	return day, dep, arr, nil
+}
+
+// parseJamPadaTanggal menerima timestamp RFC 3339 atau jam saja yang
+// kemudian digabung dengan tanggal jadwal.
+func isRFC3339(value string) bool {
+	_, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	return err == nil
+}
+
+func parseJamPadaTanggal(value string, day time.Time, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(value)); err == nil {
+		return t, nil
+	}
+	clock, err := parseWithLayouts(value, waktuLayouts, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, loc), nil
+}
+
+// localizeJadwal menampilkan waktu jadwal pada zona waktu rutenya
+func localizeJadwal(j *models.Jadwal, loc *time.Location) {
+	j.Tanggal = j.Tanggal.In(loc)
+	j.WaktuBerangkat = j.WaktuBerangkat.In(loc)
+	j.EstimasiTiba = j.EstimasiTiba.In(loc)
+}
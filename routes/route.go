@@ -2,44 +2,140 @@ package routes
 
 import (
 	"transport-app/middleware"
+	"transport-app/models"
 	"transport-app/repository"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupRoutes(app *fiber.App) {
+func SetupRoutes(app *fiber.App, h *repository.Handler) {
+	// Kunci publik untuk memverifikasi token di service lain
+	app.Get("/.well-known/jwks.json", h.GetJWKS)
+
 	api := app.Group("/api")
+	protected := middleware.Protected(h.Keys.KeyFunc, h.Store.Session)
+	// Endpoint baca juga menerima API key untuk agen tiket dan kios
+	readable := middleware.ProtectedOrAPIKey(protected, h.Store.APIKey)
 
 	// Auth --- Rute Publik ---
-	api.Post("/register", repository.Register)
-	api.Post("/login", repository.Login)
+	api.Post("/register", h.Register)
+	api.Post("/login", h.Login)
+	api.Post("/login/2fa", h.LoginTwoFactor)
+	api.Get("/login/oidc", h.OIDCLogin)
+	api.Get("/login/oidc/callback", h.OIDCCallback)
+	api.Post("/token/refresh", h.RefreshToken)
+	api.Post("/logout", protected, h.Logout)
+	api.Post("/password/forgot", h.ForgotPassword)
+	api.Post("/password/reset", h.ResetPassword)
+	api.Post("/email/verify", h.VerifyEmail)
+	api.Post("/email/resend", h.ResendVerification)
 
-	// --- Rute untuk Semua User (user & admin) ---
-	// Endpoint GET All bisa diakses oleh semua yang sudah login
-	api.Get("/rutes", middleware.Protected(), repository.GetAllRute)
-	api.Get("/kendaraans", middleware.Protected(), repository.GetAllKendaraan)
-	api.Get("/jadwals", middleware.Protected(), repository.GetAllJadwal)
+	// --- Rute untuk user yang sudah login ---
+	// Akses ditentukan oleh permission role user (lihat models.DefaultRoles)
+	can := func(permissions ...string) fiber.Handler {
+		return middleware.RequirePermission(h.Store.Role, permissions...)
+	}
 
-	// Endpoint GET by ID juga bisa diakses oleh semua yang sudah login
-	api.Get("/rutes/:id", middleware.Protected(), repository.GetRuteByID)
-	api.Get("/kendaraans/:id", middleware.Protected(), repository.GetKendaraanByID)
-	api.Get("/jadwals/:id", middleware.Protected(), repository.GetJadwalByID)
+	// Profil user yang sedang login
+	api.Get("/me", protected, h.GetMe)
+	api.Patch("/me", protected, h.UpdateMe)
+	api.Put("/me/password", protected, h.ChangePassword)
+	api.Delete("/me", protected, h.DeleteMe)
+	api.Post("/me/2fa/setup", protected, h.SetupTwoFactor)
+	api.Post("/me/2fa/enable", protected, h.EnableTwoFactor)
+	api.Post("/me/2fa/disable", protected, h.DisableTwoFactor)
+	api.Post("/me/2fa/recovery-codes", protected, h.RegenerateRecoveryCodes)
+	api.Post("/me/oidc", protected, h.LinkOIDC)
 
+	// Endpoint GET All
+	api.Get("/rutes", readable, can(models.PermRuteRead), h.GetAllRute)
+	api.Get("/kendaraans", readable, can(models.PermKendaraanRead), h.GetAllKendaraan)
+	api.Get("/jadwals", readable, can(models.PermJadwalRead), h.GetAllJadwal)
+	api.Get("/haltes", readable, can(models.PermRuteRead), h.GetAllHalte)
+
+	// Pencarian lokasi, didaftarkan sebelum /:id
+	api.Get("/haltes/nearby", readable, can(models.PermRuteRead), h.GetNearbyHalte)
+	api.Get("/rutes/nearby", readable, can(models.PermRuteRead), h.GetNearbyRute)
+
+	// Endpoint GET by ID
+	api.Get("/rutes/:id", readable, can(models.PermRuteRead), h.GetRuteByID)
+	api.Get("/kendaraans/:id", readable, can(models.PermKendaraanRead), h.GetKendaraanByID)
+	api.Get("/jadwals/:id", readable, can(models.PermJadwalRead), h.GetJadwalByID)
+	api.Get("/haltes/:id", readable, can(models.PermRuteRead), h.GetHalteByID)
+	api.Get("/rutes/:id/geojson", readable, can(models.PermRuteRead), h.GetRuteGeoJSON)
+	api.Get("/jadwals/:id/stops", readable, can(models.PermJadwalRead), h.GetJadwalStops)
+
+	// Feed GTFS untuk aplikasi journey planner
+	api.Get("/gtfs", readable, can(models.PermRuteRead, models.PermJadwalRead), h.GetGTFS)
+
+	// Pencarian perjalanan
+	api.Get("/search", readable, can(models.PermJadwalRead), h.SearchJadwal)
+	api.Get("/planner", readable, can(models.PermJadwalRead), h.PlanJourney)
 
-	// --- Rute admin ---
 	// Rute
-	api.Post("/rutes",middleware.Protected(), middleware.AdminOnly(), repository.CreateRute)
-	api.Put("/rutes/:id",middleware.Protected(), middleware.AdminOnly(), repository.UpdateRute)
-	api.Delete("/rutes/:id",middleware.Protected(), middleware.AdminOnly(), repository.DeleteRute)
+	api.Post("/rutes", protected, can(models.PermRuteWrite), h.CreateRute)
+	api.Put("/rutes/:id", protected, can(models.PermRuteWrite), h.UpdateRute)
+	api.Delete("/rutes/:id", protected, can(models.PermRuteWrite), h.DeleteRute)
+	api.Put("/rutes/:id/geometri", protected, can(models.PermRuteWrite), h.SetRuteGeometri)
+	api.Delete("/rutes/:id/geometri", protected, can(models.PermRuteWrite), h.DeleteRuteGeometri)
+
+	// Halte dikelola bersama rute
+	api.Post("/haltes", protected, can(models.PermRuteWrite), h.CreateHalte)
+	api.Put("/haltes/:id", protected, can(models.PermRuteWrite), h.UpdateHalte)
+	api.Delete("/haltes/:id", protected, can(models.PermRuteWrite), h.DeleteHalte)
 
 	// Kendaraan
-	api.Post("/kendaraans",middleware.Protected(), middleware.AdminOnly(), repository.CreateKendaraan)
-	api.Put("/kendaraans/:id",middleware.Protected(), middleware.AdminOnly(), repository.UpdateKendaraan)
-	api.Delete("/kendaraans/:id",middleware.Protected(), middleware.AdminOnly(), repository.DeleteKendaraan)
+	api.Post("/kendaraans", protected, can(models.PermKendaraanWrite), h.CreateKendaraan)
+	api.Put("/kendaraans/:id", protected, can(models.PermKendaraanWrite), h.UpdateKendaraan)
+	api.Delete("/kendaraans/:id", protected, can(models.PermKendaraanWrite), h.DeleteKendaraan)
+
+	// Jadwal
+	api.Post("/jadwals", protected, can(models.PermJadwalWrite), h.CreateJadwal)
+	api.Put("/jadwals/:id", protected, can(models.PermJadwalWrite), h.UpdateJadwal)
+	api.Delete("/jadwals/:id", protected, can(models.PermJadwalWrite), h.DeleteJadwal)
+
+	// Impor GTFS membuat halte, rute dan jadwal sekaligus
+	api.Post("/gtfs/import", protected, can(models.PermRuteWrite, models.PermJadwalWrite), h.PostGTFSImport)
+
+	// Template jadwal berulang
+	api.Get("/jadwal-templates", protected, can(models.PermTemplateWrite), h.GetAllJadwalTemplate)
+	api.Post("/jadwal-templates", protected, can(models.PermTemplateWrite), h.CreateJadwalTemplate)
+	api.Post("/jadwal-templates/generate", protected, can(models.PermTemplateWrite), h.GenerateAllJadwal)
+	api.Get("/jadwal-templates/:id", protected, can(models.PermTemplateWrite), h.GetJadwalTemplateByID)
+	api.Put("/jadwal-templates/:id", protected, can(models.PermTemplateWrite), h.UpdateJadwalTemplate)
+	api.Delete("/jadwal-templates/:id", protected, can(models.PermTemplateWrite), h.DeleteJadwalTemplate)
+	api.Post("/jadwal-templates/:id/generate", protected, can(models.PermTemplateWrite), h.GenerateJadwalFromTemplate)
+
+	// Booking kursi. Melihat dan membatalkan booking user lain diperiksa di handler
+	api.Post("/jadwals/:id/bookings", protected, can(models.PermBookingCreate), h.CreateBooking)
+	api.Get("/jadwals/:id/bookings", protected, h.GetBookingsByJadwal)
+	api.Delete("/jadwals/:id/bookings/:bookingId", protected, h.CancelBooking)
+
+	// --- Rute admin ---
+	admin := api.Group("/admin", protected)
+
+	// Role dan permission
+	admin.Get("/permissions", can(models.PermRoleManage), h.GetPermissions)
+	admin.Get("/roles", can(models.PermRoleManage), h.GetAllRoles)
+	admin.Post("/roles", can(models.PermRoleManage), h.CreateRole)
+	admin.Put("/roles/:name", can(models.PermRoleManage), h.UpdateRole)
+	admin.Delete("/roles/:name", can(models.PermRoleManage), h.DeleteRole)
+	admin.Put("/users/:id/role", can(models.PermRoleManage), h.AssignUserRole)
 
-	// import repository jadwal
-	api.Post("/jadwals",middleware.Protected(), middleware.AdminOnly(), repository.CreateJadwal)
-	api.Put("/jadwals/:id",middleware.Protected(), middleware.AdminOnly(), repository.UpdateJadwal)
-	api.Delete("/jadwals/:id",middleware.Protected(), middleware.AdminOnly(), repository.DeleteJadwal)
+	// User
+	admin.Get("/users", can(models.PermUserManage), h.GetAllUsers)
+	admin.Get("/users/:id", can(models.PermUserManage), h.GetUserByID)
+	admin.Post("/users/:id/disable", can(models.PermUserManage), h.DisableUser)
+	admin.Post("/users/:id/enable", can(models.PermUserManage), h.EnableUser)
+	admin.Post("/users/:id/reset-password", can(models.PermUserManage), h.ForceResetPassword)
+	admin.Delete("/users/:id", can(models.PermUserManage), h.DeleteUser)
+	admin.Post("/users/:id/unlock", can(models.PermUserManage), h.UnlockUser)
+	admin.Delete("/users/:id/2fa", can(models.PermUserManage), h.ResetUserTwoFactor)
+	admin.Delete("/lockouts/ip/:ip", can(models.PermUserManage), h.UnlockIP)
 
+	// API key untuk integrasi sistem lain
+	admin.Get("/api-keys/scopes", can(models.PermAPIKeyManage), h.GetAPIKeyScopes)
+	admin.Get("/api-keys", can(models.PermAPIKeyManage), h.GetAllAPIKeys)
+	admin.Post("/api-keys", can(models.PermAPIKeyManage), h.CreateAPIKey)
+	admin.Delete("/api-keys/:id", can(models.PermAPIKeyManage), h.RevokeAPIKey)
 }
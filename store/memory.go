@@ -0,0 +1,98 @@
+package store
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewMemoryStores membuat semua store yang menyimpan data di memori.
+// Dipakai untuk pengujian handler tanpa MongoDB.
+func NewMemoryStores() *Stores {
+	rutes := &memRuteStore{table: newMemTable[models.Rute]()}
+	kendaraans := &memKendaraanStore{table: newMemTable[models.Kendaraan]()}
+	return &Stores{
+		Rute:           rutes,
+		Halte:          &memHalteStore{table: newMemTable[models.Halte]()},
+		Kendaraan:      kendaraans,
+		Jadwal:         &memJadwalStore{table: newMemTable[models.Jadwal](), rutes: rutes, kendaraans: kendaraans, legacy: newMemTable[LegacyJadwal]()},
+		User:           &memUserStore{table: newMemTable[models.User]()},
+		Booking:        &memBookingStore{table: newMemTable[models.Booking]()},
+		JadwalTemplate: &memJadwalTemplateStore{table: newMemTable[models.JadwalTemplate]()},
+		Session:        &memSessionStore{table: newMemTable[models.Session]()},
+		UserToken:      &memUserTokenStore{table: newMemTable[models.UserToken]()},
+		Role:           &memRoleStore{table: newMemTable[models.Role]()},
+		LoginAttempt:   &memLoginAttemptStore{items: map[string]models.LoginAttempt{}},
+		SigningKey:     &memSigningKeyStore{},
+		APIKey:         &memAPIKeyStore{table: newMemTable[models.APIKey]()},
+		Lock:           &memLockStore{items: map[string]memLock{}},
+	}
+}
+
+// memTable adalah map yang aman dipakai bersamaan. Item disimpan sebagai
+// nilai sehingga perubahan di luar store tidak ikut mengubah isi tabel.
+type memTable[T any] struct {
+	mu    sync.RWMutex
+	items map[primitive.ObjectID]T
+}
+
+func newMemTable[T any]() *memTable[T] {
+	return &memTable[T]{items: map[primitive.ObjectID]T{}}
+}
+
+func (t *memTable[T]) get(id primitive.ObjectID) (T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	item, ok := t.items[id]
+	return item, ok
+}
+
+func (t *memTable[T]) put(id primitive.ObjectID, item T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.items[id] = item
+}
+
+func (t *memTable[T]) remove(id primitive.ObjectID) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.items[id]; !ok {
+		return false
+	}
+	delete(t.items, id)
+	return true
+}
+
+// filter mengembalikan item yang cocok, diurutkan berdasarkan ID seperti
+// urutan natural koleksi MongoDB
+func (t *memTable[T]) filter(match func(T) bool) []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	ids := make([]primitive.ObjectID, 0, len(t.items))
+	for id := range t.items {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return bytes.Compare(ids[a][:], ids[b][:]) < 0 })
+
+	result := []T{}
+	for _, id := range ids {
+		if item := t.items[id]; match == nil || match(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// first mengembalikan item pertama yang cocok
+func (t *memTable[T]) first(match func(T) bool) (T, bool) {
+	items := t.filter(match)
+	if len(items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return items[0], true
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memAPIKeyStore struct {
+	table *memTable[models.APIKey]
+}
+
+func (s *memAPIKeyStore) List(ctx context.Context) ([]models.APIKey, error) {
+	keys := s.table.filter(nil)
+	sort.SliceStable(keys, func(a, b int) bool { return keys[a].CreatedAt.After(keys[b].CreatedAt) })
+	return keys, nil
+}
+
+func (s *memAPIKeyStore) Create(ctx context.Context, key *models.APIKey) error {
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+	s.table.put(key.ID, *key)
+	return nil
+}
+
+func (s *memAPIKeyStore) GetByHash(ctx context.Context, hash string) (models.APIKey, error) {
+	key, ok := s.table.first(func(k models.APIKey) bool { return k.KeyHash == hash })
+	if !ok {
+		return key, ErrNotFound
+	}
+	return key, nil
+}
+
+func (s *memAPIKeyStore) Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	key, ok := s.table.items[id]
+	if !ok || key.RevokedAt != nil {
+		return ErrNotFound
+	}
+	key.RevokedAt = &at
+	s.table.items[id] = key
+	return nil
+}
+
+func (s *memAPIKeyStore) TouchLastUsed(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	key, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if key.LastUsedAt == nil || at.After(*key.LastUsedAt) {
+		key.LastUsedAt = &at
+		s.table.items[id] = key
+	}
+	return nil
+}
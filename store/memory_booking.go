@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memBookingStore struct {
+	table *memTable[models.Booking]
+}
+
+func (s *memBookingStore) Create(ctx context.Context, booking *models.Booking) error {
+	if booking.ID.IsZero() {
+		booking.ID = primitive.NewObjectID()
+	}
+	s.table.put(booking.ID, *booking)
+	return nil
+}
+
+func (s *memBookingStore) ListByJadwal(ctx context.Context, jadwalID, userID primitive.ObjectID) ([]models.Booking, error) {
+	return s.table.filter(func(b models.Booking) bool {
+		return b.JadwalID == jadwalID && (userID.IsZero() || b.UserID == userID)
+	}), nil
+}
+
+func (s *memBookingStore) Cancel(ctx context.Context, id, jadwalID, userID primitive.ObjectID) (models.Booking, error) {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	booking, ok := s.table.items[id]
+	if !ok || booking.JadwalID != jadwalID || booking.Status != models.BookingStatusConfirmed ||
+		(!userID.IsZero() && booking.UserID != userID) {
+		return models.Booking{}, ErrNotFound
+	}
+
+	cancelled := booking
+	cancelled.Status = models.BookingStatusCancelled
+	s.table.items[id] = cancelled
+	return booking, nil
+}
+
+func (s *memBookingStore) Restore(ctx context.Context, id primitive.ObjectID) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	booking, ok := s.table.items[id]
+	if !ok || booking.Status != models.BookingStatusCancelled {
+		return ErrNotFound
+	}
+	booking.Status = models.BookingStatusConfirmed
+	s.table.items[id] = booking
+	return nil
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"transport-app/geo"
+	"transport-app/models"
+	"transport-app/query"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memHalteStore struct {
+	table *memTable[models.Halte]
+}
+
+func (s *memHalteStore) List(ctx context.Context, q query.ListQuery) (query.Page[models.Halte], error) {
+	search := strings.ToLower(q.Search)
+	haltes := s.table.filter(func(h models.Halte) bool {
+		return search == "" ||
+			strings.Contains(strings.ToLower(h.Kode), search) ||
+			strings.Contains(strings.ToLower(h.Nama), search)
+	})
+	return query.Slice(haltes, q)
+}
+
+func (s *memHalteStore) Get(ctx context.Context, id primitive.ObjectID) (models.Halte, error) {
+	halte, ok := s.table.get(id)
+	if !ok {
+		return halte, ErrNotFound
+	}
+	return halte, nil
+}
+
+func (s *memHalteStore) GetByKode(ctx context.Context, kode string) (models.Halte, error) {
+	halte, ok := s.table.first(func(h models.Halte) bool { return h.Kode == kode })
+	if !ok {
+		return halte, ErrNotFound
+	}
+	return halte, nil
+}
+
+func (s *memHalteStore) GetMany(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Halte, error) {
+	result := map[primitive.ObjectID]models.Halte{}
+	for _, id := range ids {
+		if h, ok := s.table.get(id); ok {
+			result[id] = h
+		}
+	}
+	return result, nil
+}
+
+func (s *memHalteStore) Nearby(ctx context.Context, lat, lng, radiusM float64, limit int) ([]models.HalteTerdekat, error) {
+	result := []models.HalteTerdekat{}
+	for _, h := range s.table.filter(nil) {
+		d := geo.Haversine(lat, lng, h.Lokasi.Lat(), h.Lokasi.Lng())
+		if d <= radiusM {
+			result = append(result, models.HalteTerdekat{Halte: h, JarakM: d})
+		}
+	}
+	sort.SliceStable(result, func(a, b int) bool { return result[a].JarakM < result[b].JarakM })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (s *memHalteStore) Create(ctx context.Context, halte *models.Halte) error {
+	if halte.ID.IsZero() {
+		halte.ID = primitive.NewObjectID()
+	}
+	s.table.put(halte.ID, *halte)
+	return nil
+}
+
+func (s *memHalteStore) Update(ctx context.Context, halte models.Halte) error {
+	if _, ok := s.table.get(halte.ID); !ok {
+		return ErrNotFound
+	}
+	s.table.put(halte.ID, halte)
+	return nil
+}
+
+func (s *memHalteStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if !s.table.remove(id) {
+		return ErrNotFound
+	}
+	return nil
+}
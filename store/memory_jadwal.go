@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"time"
+	"transport-app/models"
+	"transport-app/query"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memJadwalStore struct {
+	table      *memTable[models.Jadwal]
+	rutes      *memRuteStore
+	kendaraans *memKendaraanStore
+	// legacy berisi jadwal lama yang belum dimigrasi, lihat AddLegacyJadwal
+	legacy *memTable[LegacyJadwal]
+}
+
+func (s *memJadwalStore) List(ctx context.Context, q query.ListQuery) (query.Page[models.JadwalWithRute], error) {
+	page, err := query.Slice(s.table.filter(nil), q)
+	if err != nil {
+		return query.Page[models.JadwalWithRute]{}, err
+	}
+
+	result := query.Page[models.JadwalWithRute]{
+		Data:       []models.JadwalWithRute{},
+		Total:      page.Total,
+		Page:       page.Page,
+		Limit:      page.Limit,
+		NextCursor: page.NextCursor,
+	}
+	for _, j := range page.Data {
+		rute, _ := s.rutes.table.get(j.RuteID)
+		kendaraan, _ := s.kendaraans.table.get(j.KendaraanID)
+		result.Data = append(result.Data, models.JadwalWithRute{
+			ID:             j.ID.Hex(),
+			Tanggal:        j.Tanggal,
+			WaktuBerangkat: j.WaktuBerangkat,
+			EstimasiTiba:   j.EstimasiTiba,
+			RuteID:         j.RuteID.Hex(),
+			KendaraanID:    j.KendaraanID.Hex(),
+			Pengemudi:      j.Pengemudi,
+			KursiTerisi:    j.KursiTerisi,
+			Rute:           rute,
+			Kendaraan:      kendaraan,
+		})
+	}
+	return result, nil
+}
+
+func (s *memJadwalStore) Get(ctx context.Context, id primitive.ObjectID) (models.Jadwal, error) {
+	jadwal, ok := s.table.get(id)
+	if !ok {
+		return jadwal, ErrNotFound
+	}
+	return jadwal, nil
+}
+
+func (s *memJadwalStore) Create(ctx context.Context, jadwal *models.Jadwal) error {
+	if jadwal.ID.IsZero() {
+		jadwal.ID = primitive.NewObjectID()
+	}
+	s.table.put(jadwal.ID, *jadwal)
+	return nil
+}
+
+func (s *memJadwalStore) Update(ctx context.Context, id primitive.ObjectID, update JadwalUpdate) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	jadwal, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	jadwal.Tanggal = update.Tanggal
+	jadwal.WaktuBerangkat = update.WaktuBerangkat
+	jadwal.EstimasiTiba = update.EstimasiTiba
+	jadwal.RuteID = update.RuteID
+	jadwal.Pengemudi = update.Pengemudi
+	if update.GTFS != nil {
+		jadwal.GTFS = update.GTFS
+	}
+	s.table.items[id] = jadwal
+	return nil
+}
+
+func (s *memJadwalStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	jadwal, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if jadwal.KursiTerisi > 0 {
+		return ErrBooked
+	}
+	delete(s.table.items, id)
+	return nil
+}
+
+func (s *memJadwalStore) FindConflicts(ctx context.Context, kendaraanID primitive.ObjectID, pengemudi string, start, end time.Time, excludeID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	ids := []primitive.ObjectID{}
+	for _, j := range s.table.filter(nil) {
+		if j.ID == excludeID {
+			continue
+		}
+		sameResource := j.KendaraanID == kendaraanID || (pengemudi != "" && j.Pengemudi == pengemudi)
+		if sameResource && j.WaktuBerangkat.Before(end) && j.EstimasiTiba.After(start) {
+			ids = append(ids, j.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (s *memJadwalStore) FindOverlapping(ctx context.Context, kendaraanIDs []primitive.ObjectID, pengemudi []string, start, end time.Time) ([]models.Jadwal, error) {
+	return s.table.filter(func(j models.Jadwal) bool {
+		if !j.WaktuBerangkat.Before(end) || !j.EstimasiTiba.After(start) {
+			return false
+		}
+		for _, id := range kendaraanIDs {
+			if j.KendaraanID == id {
+				return true
+			}
+		}
+		for _, p := range pengemudi {
+			if j.Pengemudi == p {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (s *memJadwalStore) FindByRuteTanggal(ctx context.Context, keys []RuteTanggal) ([]models.Jadwal, error) {
+	return s.table.filter(func(j models.Jadwal) bool {
+		for _, k := range keys {
+			if j.RuteID == k.RuteID && j.Tanggal.Equal(k.Tanggal) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (s *memJadwalStore) FindDepartingBetween(ctx context.Context, from, until time.Time) ([]models.Jadwal, error) {
+	return s.table.filter(func(j models.Jadwal) bool {
+		return !j.WaktuBerangkat.Before(from) && j.WaktuBerangkat.Before(until)
+	}), nil
+}
+
+func (s *memJadwalStore) ReserveSeats(ctx context.Context, id primitive.ObjectID, jumlah, kapasitas int) (bool, error) {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	jadwal, ok := s.table.items[id]
+	if !ok || jadwal.KursiTerisi+jumlah > kapasitas {
+		return false, nil
+	}
+	jadwal.KursiTerisi += jumlah
+	s.table.items[id] = jadwal
+	return true, nil
+}
+
+func (s *memJadwalStore) ReleaseSeats(ctx context.Context, id primitive.ObjectID, jumlah int) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	if jadwal, ok := s.table.items[id]; ok {
+		jadwal.KursiTerisi -= jumlah
+		s.table.items[id] = jadwal
+	}
+	return nil
+}
+
+func (s *memJadwalStore) CreateFromTemplate(ctx context.Context, jadwal *models.Jadwal) (bool, error) {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	for _, j := range s.table.items {
+		if sameTemplateDay(j, jadwal.TemplateID, jadwal.Tanggal) {
+			return false, nil
+		}
+	}
+	if jadwal.ID.IsZero() {
+		jadwal.ID = primitive.NewObjectID()
+	}
+	s.table.items[jadwal.ID] = *jadwal
+	return true, nil
+}
+
+func (s *memJadwalStore) ExistsForTemplate(ctx context.Context, templateID primitive.ObjectID, tanggal time.Time) (bool, error) {
+	_, ok := s.table.first(func(j models.Jadwal) bool { return sameTemplateDay(j, &templateID, tanggal) })
+	return ok, nil
+}
+
+func (s *memJadwalStore) FindLegacyWaktu(ctx context.Context) ([]LegacyJadwal, error) {
+	return s.legacy.filter(nil), nil
+}
+
+// SetWaktu pada jadwal lama memindahkannya menjadi jadwal biasa
+func (s *memJadwalStore) SetWaktu(ctx context.Context, id primitive.ObjectID, tanggal, berangkat, tiba time.Time) error {
+	if old, ok := s.legacy.get(id); ok {
+		s.legacy.remove(id)
+		s.table.put(id, models.Jadwal{ID: id, RuteID: old.RuteID, Tanggal: tanggal, WaktuBerangkat: berangkat, EstimasiTiba: tiba})
+		return nil
+	}
+
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	jadwal, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	jadwal.Tanggal = tanggal
+	jadwal.WaktuBerangkat = berangkat
+	jadwal.EstimasiTiba = tiba
+	s.table.items[id] = jadwal
+	return nil
+}
+
+// AddLegacyJadwal menyimpan jadwal berformat lama ke store in-memory agar
+// migrasi bisa diuji tanpa MongoDB. s harus dibuat dengan NewMemoryStores.
+func AddLegacyJadwal(s *Stores, jadwal LegacyJadwal) {
+	mem := s.Jadwal.(*memJadwalStore)
+	mem.legacy.put(jadwal.ID, jadwal)
+}
+
+func sameTemplateDay(j models.Jadwal, templateID *primitive.ObjectID, tanggal time.Time) bool {
+	return j.TemplateID != nil && templateID != nil && *j.TemplateID == *templateID && j.Tanggal.Equal(tanggal)
+}
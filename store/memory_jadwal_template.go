@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memJadwalTemplateStore struct {
+	table *memTable[models.JadwalTemplate]
+}
+
+func (s *memJadwalTemplateStore) All(ctx context.Context) ([]models.JadwalTemplate, error) {
+	return s.table.filter(nil), nil
+}
+
+func (s *memJadwalTemplateStore) ListActive(ctx context.Context) ([]models.JadwalTemplate, error) {
+	return s.table.filter(func(t models.JadwalTemplate) bool { return t.Aktif }), nil
+}
+
+func (s *memJadwalTemplateStore) Get(ctx context.Context, id primitive.ObjectID) (models.JadwalTemplate, error) {
+	tpl, ok := s.table.get(id)
+	if !ok {
+		return tpl, ErrNotFound
+	}
+	return tpl, nil
+}
+
+func (s *memJadwalTemplateStore) Create(ctx context.Context, tpl *models.JadwalTemplate) error {
+	if tpl.ID.IsZero() {
+		tpl.ID = primitive.NewObjectID()
+	}
+	s.table.put(tpl.ID, *tpl)
+	return nil
+}
+
+func (s *memJadwalTemplateStore) Replace(ctx context.Context, tpl models.JadwalTemplate) error {
+	if _, ok := s.table.get(tpl.ID); !ok {
+		return ErrNotFound
+	}
+	s.table.put(tpl.ID, tpl)
+	return nil
+}
+
+func (s *memJadwalTemplateStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if !s.table.remove(id) {
+		return ErrNotFound
+	}
+	return nil
+}
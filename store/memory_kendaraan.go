@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"transport-app/models"
+	"transport-app/query"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memKendaraanStore struct {
+	table *memTable[models.Kendaraan]
+}
+
+func (s *memKendaraanStore) List(ctx context.Context, q query.ListQuery) (query.Page[models.Kendaraan], error) {
+	return query.Slice(s.table.filter(nil), q)
+}
+
+func (s *memKendaraanStore) Get(ctx context.Context, id primitive.ObjectID) (models.Kendaraan, error) {
+	kendaraan, ok := s.table.get(id)
+	if !ok {
+		return kendaraan, ErrNotFound
+	}
+	return kendaraan, nil
+}
+
+func (s *memKendaraanStore) GetByNomorPolisi(ctx context.Context, nomorPolisi string) (models.Kendaraan, error) {
+	kendaraan, ok := s.table.first(func(k models.Kendaraan) bool { return k.NomorPolisi == nomorPolisi })
+	if !ok {
+		return kendaraan, ErrNotFound
+	}
+	return kendaraan, nil
+}
+
+func (s *memKendaraanStore) GetMany(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Kendaraan, error) {
+	result := map[primitive.ObjectID]models.Kendaraan{}
+	for _, id := range ids {
+		if k, ok := s.table.get(id); ok {
+			result[id] = k
+		}
+	}
+	return result, nil
+}
+
+func (s *memKendaraanStore) Create(ctx context.Context, kendaraan *models.Kendaraan) error {
+	if kendaraan.ID.IsZero() {
+		kendaraan.ID = primitive.NewObjectID()
+	}
+	s.table.put(kendaraan.ID, *kendaraan)
+	return nil
+}
+
+func (s *memKendaraanStore) Update(ctx context.Context, kendaraan models.Kendaraan) error {
+	if _, ok := s.table.get(kendaraan.ID); !ok {
+		return ErrNotFound
+	}
+	s.table.put(kendaraan.ID, kendaraan)
+	return nil
+}
+
+func (s *memKendaraanStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if !s.table.remove(id) {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memLock struct {
+	owner     string
+	expiresAt time.Time
+}
+
+type memLockStore struct {
+	mu    sync.Mutex
+	items map[string]memLock
+}
+
+func (s *memLockStore) Acquire(ctx context.Context, key, owner string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lock, ok := s.items[key]; ok && lock.owner != owner && time.Now().Before(lock.expiresAt) {
+		return ErrLocked
+	}
+	s.items[key] = memLock{owner: owner, expiresAt: until}
+	return nil
+}
+
+func (s *memLockStore) Release(ctx context.Context, key, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lock, ok := s.items[key]; ok && lock.owner == owner {
+		delete(s.items, key)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+	"transport-app/models"
+)
+
+type memLoginAttemptStore struct {
+	mu    sync.Mutex
+	items map[string]models.LoginAttempt
+}
+
+func (s *memLoginAttemptStore) Get(ctx context.Context, key string) (models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.items[key]
+	if !ok || !time.Now().Before(attempt.ExpiresAt) {
+		return models.LoginAttempt{}, ErrNotFound
+	}
+	return attempt, nil
+}
+
+func (s *memLoginAttemptStore) RecordFailure(ctx context.Context, key string, now time.Time, ttl time.Duration) (models.LoginAttempt, error) {
+	return s.Attempt(ctx, key, now, ttl, func(int) time.Duration { return 0 })
+}
+
+func (s *memLoginAttemptStore) Attempt(ctx context.Context, key string, now time.Time, ttl time.Duration, lockFor func(failures int) time.Duration) (models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.items[key]
+	if !ok || !now.Before(current.ExpiresAt) {
+		current = models.LoginAttempt{}
+	}
+	if current.Locked(now) {
+		return current, ErrLocked
+	}
+	next := nextAttempt(current, key, now, ttl, lockFor)
+	s.items[key] = next
+	return next, nil
+}
+
+func (s *memLoginAttemptStore) Forgive(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempt, ok := s.items[key]; ok && attempt.Failures > 0 {
+		attempt.Failures--
+		s.items[key] = attempt
+	}
+	return nil
+}
+
+func (s *memLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	return nil
+}
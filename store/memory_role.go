@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memRoleStore struct {
+	table *memTable[models.Role]
+}
+
+func (s *memRoleStore) All(ctx context.Context) ([]models.Role, error) {
+	roles := s.table.filter(nil)
+	sort.Slice(roles, func(a, b int) bool { return roles[a].Name < roles[b].Name })
+	return roles, nil
+}
+
+func (s *memRoleStore) GetByName(ctx context.Context, name string) (models.Role, error) {
+	role, ok := s.table.first(func(r models.Role) bool { return r.Name == name })
+	if !ok {
+		return role, ErrNotFound
+	}
+	return role, nil
+}
+
+func (s *memRoleStore) Create(ctx context.Context, role *models.Role) error {
+	if role.ID.IsZero() {
+		role.ID = primitive.NewObjectID()
+	}
+	s.table.put(role.ID, *role)
+	return nil
+}
+
+func (s *memRoleStore) CreateIfMissing(ctx context.Context, role models.Role) (bool, error) {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	for _, r := range s.table.items {
+		if r.Name == role.Name {
+			return false, nil
+		}
+	}
+	if role.ID.IsZero() {
+		role.ID = primitive.NewObjectID()
+	}
+	s.table.items[role.ID] = role
+	return true, nil
+}
+
+func (s *memRoleStore) Update(ctx context.Context, role models.Role) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	for id, r := range s.table.items {
+		if r.Name == role.Name {
+			r.Description = role.Description
+			r.Permissions = role.Permissions
+			s.table.items[id] = r
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *memRoleStore) Delete(ctx context.Context, name string) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	for id, r := range s.table.items {
+		if r.Name == name {
+			delete(s.table.items, id)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
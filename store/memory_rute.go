@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"transport-app/models"
+	"transport-app/query"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memRuteStore struct {
+	table *memTable[models.Rute]
+}
+
+func (s *memRuteStore) List(ctx context.Context, q query.ListQuery) (query.Page[models.Rute], error) {
+	return query.Slice(s.table.filter(nil), q)
+}
+
+func (s *memRuteStore) All(ctx context.Context) ([]models.Rute, error) {
+	return s.table.filter(nil), nil
+}
+
+func (s *memRuteStore) Get(ctx context.Context, id primitive.ObjectID) (models.Rute, error) {
+	rute, ok := s.table.get(id)
+	if !ok {
+		return rute, ErrNotFound
+	}
+	return rute, nil
+}
+
+func (s *memRuteStore) GetByKode(ctx context.Context, kode string) (models.Rute, error) {
+	rute, ok := s.table.first(func(r models.Rute) bool { return r.KodeRute == kode })
+	if !ok {
+		return rute, ErrNotFound
+	}
+	return rute, nil
+}
+
+func (s *memRuteStore) FindByAsalTujuan(ctx context.Context, asal, tujuan string) ([]models.Rute, error) {
+	asal, tujuan = strings.TrimSpace(asal), strings.TrimSpace(tujuan)
+	return s.table.filter(func(r models.Rute) bool {
+		return strings.EqualFold(r.Asal, asal) && strings.EqualFold(r.Tujuan, tujuan)
+	}), nil
+}
+
+func (s *memRuteStore) Create(ctx context.Context, rute *models.Rute) error {
+	if rute.ID.IsZero() {
+		rute.ID = primitive.NewObjectID()
+	}
+	s.table.put(rute.ID, *rute)
+	return nil
+}
+
+func (s *memRuteStore) Update(ctx context.Context, rute models.Rute) error {
+	old, ok := s.table.get(rute.ID)
+	if !ok {
+		return ErrNotFound
+	}
+	rute.Geometri = old.Geometri
+	s.table.put(rute.ID, rute)
+	return nil
+}
+
+func (s *memRuteStore) SetGeometri(ctx context.Context, rute models.Rute) error {
+	old, ok := s.table.get(rute.ID)
+	if !ok {
+		return ErrNotFound
+	}
+	old.Geometri = rute.Geometri
+	if rute.Geometri != nil {
+		old.JarakKM = rute.JarakKM
+		old.Halte = rute.Halte
+	}
+	s.table.put(old.ID, old)
+	return nil
+}
+
+func (s *memRuteStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if !s.table.remove(id) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *memRuteStore) UsesHalte(ctx context.Context, halteID primitive.ObjectID) (bool, error) {
+	_, ok := s.table.first(func(r models.Rute) bool {
+		for _, h := range r.Halte {
+			if h.HalteID == halteID {
+				return true
+			}
+		}
+		return false
+	})
+	return ok, nil
+}
+
+func (s *memRuteStore) FindByHalte(ctx context.Context, halteIDs []primitive.ObjectID) ([]models.Rute, error) {
+	wanted := map[primitive.ObjectID]bool{}
+	for _, id := range halteIDs {
+		wanted[id] = true
+	}
+	return s.table.filter(func(r models.Rute) bool {
+		for _, h := range r.Halte {
+			if wanted[h.HalteID] {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"time"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memSessionStore struct {
+	table *memTable[models.Session]
+}
+
+func (s *memSessionStore) Create(ctx context.Context, session *models.Session) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	s.table.put(session.ID, *session)
+	return nil
+}
+
+func (s *memSessionStore) Get(ctx context.Context, id primitive.ObjectID) (models.Session, error) {
+	session, ok := s.table.get(id)
+	if !ok {
+		return session, ErrNotFound
+	}
+	return session, nil
+}
+
+func (s *memSessionStore) Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, expiresAt time.Time) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	now := time.Now()
+	session, ok := s.table.items[id]
+	if !ok || session.RefreshHash != oldHash || !session.Active(now) {
+		return ErrNotFound
+	}
+	session.RefreshHash = newHash
+	session.LastUsedAt = now
+	session.ExpiresAt = expiresAt
+	s.table.items[id] = session
+	return nil
+}
+
+func (s *memSessionStore) Revoke(ctx context.Context, id primitive.ObjectID, reason string) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	if session, ok := s.table.items[id]; ok && session.RevokedAt == nil {
+		now := time.Now()
+		session.RevokedAt = &now
+		session.RevokedReason = reason
+		s.table.items[id] = session
+	}
+	return nil
+}
+
+func (s *memSessionStore) RevokeAllForUser(ctx context.Context, userID primitive.ObjectID, reason string) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	now := time.Now()
+	for id, session := range s.table.items {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			session.RevokedReason = reason
+			s.table.items[id] = session
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"transport-app/models"
+)
+
+type memSigningKeyStore struct {
+	mu   sync.Mutex
+	keys []models.SigningKey
+}
+
+func (s *memSigningKeyStore) List(ctx context.Context, now time.Time) ([]models.SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := []models.SigningKey{}
+	for _, key := range s.keys {
+		if now.Before(key.RetireAt) {
+			keys = append(keys, key)
+		}
+	}
+	sort.SliceStable(keys, func(a, b int) bool { return keys[a].ActiveFrom.Before(keys[b].ActiveFrom) })
+	return keys, nil
+}
+
+func (s *memSigningKeyStore) Create(ctx context.Context, key *models.SigningKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append(s.keys, *key)
+	return nil
+}
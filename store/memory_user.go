@@ -0,0 +1,246 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"transport-app/models"
+	"transport-app/query"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memUserStore struct {
+	table *memTable[models.User]
+}
+
+func (s *memUserStore) List(ctx context.Context, q query.ListQuery) (query.Page[models.User], error) {
+	search := strings.ToLower(q.Search)
+	users := s.table.filter(func(u models.User) bool {
+		return search == "" ||
+			strings.Contains(strings.ToLower(u.Username), search) ||
+			strings.Contains(strings.ToLower(u.Email), search)
+	})
+	return query.Slice(users, q)
+}
+
+func (s *memUserStore) Get(ctx context.Context, id primitive.ObjectID) (models.User, error) {
+	user, ok := s.table.get(id)
+	if !ok {
+		return user, ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *memUserStore) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	user, ok := s.table.first(func(u models.User) bool { return u.Username == username })
+	if !ok {
+		return user, ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *memUserStore) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	user, ok := s.table.first(func(u models.User) bool { return u.Email == email })
+	if !ok {
+		return user, ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *memUserStore) ExistsByUsernameOrEmail(ctx context.Context, username, email string) (bool, error) {
+	_, ok := s.table.first(func(u models.User) bool { return u.Username == username || u.Email == email })
+	return ok, nil
+}
+
+// taken meniru index unik username dan email. Pemanggil memegang s.table.mu.
+func (s *memUserStore) taken(id primitive.ObjectID, username, email string) bool {
+	for _, u := range s.table.items {
+		if u.ID != id && (u.Username == username || (email != "" && u.Email == email)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memUserStore) Create(ctx context.Context, user *models.User) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	if s.taken(user.ID, user.Username, user.Email) {
+		return ErrDuplicate
+	}
+	s.table.items[user.ID] = *user
+	return nil
+}
+
+func (s *memUserStore) UpdatePassword(ctx context.Context, id primitive.ObjectID, hash string) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	user, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.Password = hash
+	user.MustResetPassword = false
+	s.table.items[id] = user
+	return nil
+}
+
+func (s *memUserStore) SetEmailVerified(ctx context.Context, id primitive.ObjectID) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	user, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.EmailVerified = true
+	s.table.items[id] = user
+	return nil
+}
+
+func (s *memUserStore) UpdateProfile(ctx context.Context, id primitive.ObjectID, username, email string, emailVerified bool) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	user, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if s.taken(id, username, email) {
+		return ErrDuplicate
+	}
+	user.Username = username
+	user.Email = email
+	user.EmailVerified = emailVerified
+	s.table.items[id] = user
+	return nil
+}
+
+func (s *memUserStore) SetRole(ctx context.Context, id primitive.ObjectID, role string) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	user, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.Role = role
+	s.table.items[id] = user
+	return nil
+}
+
+func (s *memUserStore) CountByRole(ctx context.Context, role string) (int64, error) {
+	return int64(len(s.table.filter(func(u models.User) bool { return u.Role == role }))), nil
+}
+
+func (s *memUserStore) SetDisabled(ctx context.Context, id primitive.ObjectID, disabled bool) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	user, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.Disabled = disabled
+	s.table.items[id] = user
+	return nil
+}
+
+func (s *memUserStore) SetMustResetPassword(ctx context.Context, id primitive.ObjectID, must bool) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	user, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.MustResetPassword = must
+	s.table.items[id] = user
+	return nil
+}
+
+func (s *memUserStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if !s.table.remove(id) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *memUserStore) SetTOTP(ctx context.Context, id primitive.ObjectID, totp models.TOTP) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	user, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	totp.RecoveryCodes = append([]string(nil), totp.RecoveryCodes...)
+	user.TOTP = totp
+	s.table.items[id] = user
+	return nil
+}
+
+func (s *memUserStore) UseTOTPStep(ctx context.Context, id primitive.ObjectID, step int64) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	user, ok := s.table.items[id]
+	if !ok || user.TOTP.LastStep >= step {
+		return ErrNotFound
+	}
+	user.TOTP.LastStep = step
+	s.table.items[id] = user
+	return nil
+}
+
+func (s *memUserStore) UseRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	user, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	for i, code := range user.TOTP.RecoveryCodes {
+		if code == hash {
+			codes := append([]string{}, user.TOTP.RecoveryCodes[:i]...)
+			user.TOTP.RecoveryCodes = append(codes, user.TOTP.RecoveryCodes[i+1:]...)
+			s.table.items[id] = user
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *memUserStore) GetByOIDC(ctx context.Context, issuer, subject string) (models.User, error) {
+	user, ok := s.table.first(func(u models.User) bool {
+		return u.OIDC != nil && u.OIDC.Issuer == issuer && u.OIDC.Subject == subject
+	})
+	if !ok {
+		return user, ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *memUserStore) SetOIDC(ctx context.Context, id primitive.ObjectID, identity models.ExternalIdentity) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	user, ok := s.table.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	for _, u := range s.table.items {
+		if u.ID != id && u.OIDC != nil && *u.OIDC == identity {
+			return ErrDuplicate
+		}
+	}
+	user.OIDC = &identity
+	s.table.items[id] = user
+	return nil
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"time"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memUserTokenStore struct {
+	table *memTable[models.UserToken]
+}
+
+func (s *memUserTokenStore) Create(ctx context.Context, token *models.UserToken) error {
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	s.table.put(token.ID, *token)
+	return nil
+}
+
+func (s *memUserTokenStore) Consume(ctx context.Context, purpose, hash string) (models.UserToken, error) {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range s.table.items {
+		if token.TokenHash != hash || token.Purpose != purpose || token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+			continue
+		}
+		used := token
+		used.UsedAt = &now
+		s.table.items[id] = used
+		return token, nil
+	}
+	return models.UserToken{}, ErrNotFound
+}
+
+func (s *memUserTokenStore) DeleteForUser(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	for id, token := range s.table.items {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			delete(s.table.items, id)
+		}
+	}
+	return nil
+}
+
+func (s *memUserTokenStore) Latest(ctx context.Context, userID primitive.ObjectID, purpose string) (models.UserToken, error) {
+	var latest models.UserToken
+	found := false
+	for _, token := range s.table.filter(func(t models.UserToken) bool { return t.UserID == userID && t.Purpose == purpose }) {
+		if !found || token.CreatedAt.After(latest.CreatedAt) {
+			latest = token
+			found = true
+		}
+	}
+	if !found {
+		return latest, ErrNotFound
+	}
+	return latest, nil
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// NewMongoStores membuat semua store yang memakai database MongoDB
+func NewMongoStores(db *mongo.Database) *Stores {
+	return &Stores{
+		Rute:           &mongoRuteStore{coll: db.Collection("rutes")},
+		Halte:          &mongoHalteStore{coll: db.Collection("halte")},
+		Kendaraan:      &mongoKendaraanStore{coll: db.Collection("kendaraan")},
+		Jadwal:         &mongoJadwalStore{coll: db.Collection("jadwal")},
+		User:           &mongoUserStore{coll: db.Collection("users")},
+		Booking:        &mongoBookingStore{coll: db.Collection("booking")},
+		JadwalTemplate: &mongoJadwalTemplateStore{coll: db.Collection("jadwal_template")},
+		Session:        &mongoSessionStore{coll: db.Collection("sessions")},
+		UserToken:      &mongoUserTokenStore{coll: db.Collection("user_tokens")},
+		Role:           &mongoRoleStore{coll: db.Collection("roles")},
+		LoginAttempt:   &mongoLoginAttemptStore{coll: db.Collection("login_attempts")},
+		SigningKey:     &mongoSigningKeyStore{coll: db.Collection("signing_keys")},
+		APIKey:         &mongoAPIKeyStore{coll: db.Collection("api_keys")},
+		Lock:           &mongoLockStore{coll: db.Collection("locks")},
+	}
+}
+
+// notFound menerjemahkan error "tidak ada dokumen" dari driver ke ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// duplicate mengubah pelanggaran index unik menjadi ErrDuplicate
+func duplicate(err error) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func matched(res *mongo.UpdateResult, err error) error {
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func deleted(res *mongo.DeleteResult, err error) error {
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"time"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoAPIKeyStore struct {
+	coll *mongo.Collection
+}
+
+// EnsureIndexes membuat index unik pada hash key yang dipakai di setiap
+// request
+func (s *mongoAPIKeyStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "key_hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (s *mongoAPIKeyStore) List(ctx context.Context) ([]models.APIKey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := s.coll.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *mongoAPIKeyStore) Create(ctx context.Context, key *models.APIKey) error {
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+	_, err := s.coll.InsertOne(ctx, key)
+	return err
+}
+
+func (s *mongoAPIKeyStore) GetByHash(ctx context.Context, hash string) (models.APIKey, error) {
+	var key models.APIKey
+	err := s.coll.FindOne(ctx, bson.M{"key_hash": hash}).Decode(&key)
+	return key, notFound(err)
+}
+
+func (s *mongoAPIKeyStore) Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	filter := bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}}
+	return matched(s.coll.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": at}}))
+}
+
+func (s *mongoAPIKeyStore) TouchLastUsed(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	return matched(s.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$max": bson.M{"last_used_at": at}}))
+}
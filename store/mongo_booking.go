@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoBookingStore struct {
+	coll *mongo.Collection
+}
+
+func (s *mongoBookingStore) Create(ctx context.Context, booking *models.Booking) error {
+	if booking.ID.IsZero() {
+		booking.ID = primitive.NewObjectID()
+	}
+	_, err := s.coll.InsertOne(ctx, booking)
+	return err
+}
+
+func (s *mongoBookingStore) ListByJadwal(ctx context.Context, jadwalID, userID primitive.ObjectID) ([]models.Booking, error) {
+	filter := bson.M{"jadwal_id": jadwalID}
+	if !userID.IsZero() {
+		filter["user_id"] = userID
+	}
+
+	cursor, err := s.coll.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	bookings := []models.Booking{}
+	err = cursor.All(ctx, &bookings)
+	return bookings, err
+}
+
+func (s *mongoBookingStore) Cancel(ctx context.Context, id, jadwalID, userID primitive.ObjectID) (models.Booking, error) {
+	filter := bson.M{
+		"_id":       id,
+		"jadwal_id": jadwalID,
+		"status":    models.BookingStatusConfirmed,
+	}
+	if !userID.IsZero() {
+		filter["user_id"] = userID
+	}
+
+	// Status diubah secara atomik agar kursi tidak dikembalikan dua kali
+	var booking models.Booking
+	err := s.coll.FindOneAndUpdate(ctx, filter,
+		bson.M{"$set": bson.M{"status": models.BookingStatusCancelled}}).Decode(&booking)
+	return booking, notFound(err)
+}
+
+func (s *mongoBookingStore) Restore(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "status": models.BookingStatusCancelled}
+	return matched(s.coll.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"status": models.BookingStatusConfirmed}}))
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"regexp"
+	"transport-app/models"
+	"transport-app/query"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoHalteStore struct {
+	coll *mongo.Collection
+}
+
+// EnsureIndexes membuat index unik kode halte dan index 2dsphere lokasi
+// untuk pencarian halte terdekat
+func (s *mongoHalteStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "kode", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "lokasi", Value: "2dsphere"}}},
+	})
+	return err
+}
+
+func (s *mongoHalteStore) List(ctx context.Context, q query.ListQuery) (query.Page[models.Halte], error) {
+	var where bson.M
+	if q.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(q.Search), Options: "i"}
+		where = bson.M{"$or": bson.A{
+			bson.M{"kode": pattern},
+			bson.M{"nama": pattern},
+		}}
+	}
+	return query.FindWhere[models.Halte](ctx, s.coll, q, where)
+}
+
+func (s *mongoHalteStore) Get(ctx context.Context, id primitive.ObjectID) (models.Halte, error) {
+	var halte models.Halte
+	err := s.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&halte)
+	return halte, notFound(err)
+}
+
+func (s *mongoHalteStore) GetByKode(ctx context.Context, kode string) (models.Halte, error) {
+	var halte models.Halte
+	err := s.coll.FindOne(ctx, bson.M{"kode": kode}).Decode(&halte)
+	return halte, notFound(err)
+}
+
+func (s *mongoHalteStore) GetMany(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Halte, error) {
+	result := map[primitive.ObjectID]models.Halte{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	cursor, err := s.coll.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var list []models.Halte
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	for _, h := range list {
+		result[h.ID] = h
+	}
+	return result, nil
+}
+
+func (s *mongoHalteStore) Nearby(ctx context.Context, lat, lng, radiusM float64, limit int) ([]models.HalteTerdekat, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.M{
+			"near":          models.NewGeoPoint(lat, lng),
+			"distanceField": "jarak_m",
+			"maxDistance":   radiusM,
+			"spherical":     true,
+			"key":           "lokasi",
+		}}},
+		{{Key: "$limit", Value: limit}},
+	}
+	cursor, err := s.coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	result := []models.HalteTerdekat{}
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *mongoHalteStore) Create(ctx context.Context, halte *models.Halte) error {
+	if halte.ID.IsZero() {
+		halte.ID = primitive.NewObjectID()
+	}
+	_, err := s.coll.InsertOne(ctx, halte)
+	return err
+}
+
+func (s *mongoHalteStore) Update(ctx context.Context, halte models.Halte) error {
+	update := bson.M{"$set": bson.M{
+		"kode":   halte.Kode,
+		"nama":   halte.Nama,
+		"kota":   halte.Kota,
+		"lokasi": halte.Lokasi,
+	}}
+	return matched(s.coll.UpdateByID(ctx, halte.ID, update))
+}
+
+func (s *mongoHalteStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return deleted(s.coll.DeleteOne(ctx, bson.M{"_id": id}))
+}
@@ -0,0 +1,248 @@
+package store
+
+import (
+	"context"
+	"time"
+	"transport-app/models"
+	"transport-app/query"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoJadwalStore struct {
+	coll *mongo.Collection
+}
+
+// jadwalLookupStages menggabungkan rute dan kendaraan ke setiap jadwal di
+// dalam database, sehingga jumlah query tetap satu berapapun banyak jadwalnya.
+// Jadwal yang rute atau kendaraannya sudah dihapus tetap ikut dengan data kosong.
+func jadwalLookupStages() mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.M{"from": "rutes", "localField": "rute_id", "foreignField": "_id", "as": "rute"}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$rute", "preserveNullAndEmptyArrays": true}}},
+		{{Key: "$lookup", Value: bson.M{"from": "kendaraan", "localField": "kendaraan_id", "foreignField": "_id", "as": "kendaraan"}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$kendaraan", "preserveNullAndEmptyArrays": true}}},
+	}
+}
+
+// EnsureIndexes membuat index unik (template_id, tanggal) sebagai pengaman
+// terakhir terhadap duplikasi jadwal hasil generate
+func (s *mongoJadwalStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "template_id", Value: 1}, {Key: "tanggal", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"template_id": bson.M{"$exists": true}}),
+	})
+	return err
+}
+
+func (s *mongoJadwalStore) List(ctx context.Context, q query.ListQuery) (query.Page[models.JadwalWithRute], error) {
+	return query.Aggregate[models.JadwalWithRute](ctx, s.coll, q, jadwalLookupStages())
+}
+
+func (s *mongoJadwalStore) Get(ctx context.Context, id primitive.ObjectID) (models.Jadwal, error) {
+	var jadwal models.Jadwal
+	err := s.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&jadwal)
+	return jadwal, notFound(err)
+}
+
+func (s *mongoJadwalStore) Create(ctx context.Context, jadwal *models.Jadwal) error {
+	if jadwal.ID.IsZero() {
+		jadwal.ID = primitive.NewObjectID()
+	}
+	_, err := s.coll.InsertOne(ctx, jadwal)
+	return err
+}
+
+func (s *mongoJadwalStore) Update(ctx context.Context, id primitive.ObjectID, update JadwalUpdate) error {
+	set := bson.M{
+		"tanggal":         update.Tanggal,
+		"waktu_berangkat": update.WaktuBerangkat,
+		"estimasi_tiba":   update.EstimasiTiba,
+		"rute_id":         update.RuteID,
+		"pengemudi":       update.Pengemudi,
+	}
+	if update.GTFS != nil {
+		set["gtfs"] = update.GTFS
+	}
+	return matched(s.coll.UpdateByID(ctx, id, bson.M{"$set": set}))
+}
+
+func (s *mongoJadwalStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{
+		"_id": id,
+		"$or": []bson.M{
+			{"kursi_terisi": bson.M{"$lte": 0}},
+			{"kursi_terisi": bson.M{"$exists": false}},
+		},
+	}
+	err := deleted(s.coll.DeleteOne(ctx, filter))
+	if err != ErrNotFound {
+		return err
+	}
+
+	// Bedakan jadwal yang tidak ada dengan jadwal yang masih punya booking
+	count, err := s.coll.CountDocuments(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrBooked
+	}
+	return ErrNotFound
+}
+
+func (s *mongoJadwalStore) FindConflicts(ctx context.Context, kendaraanID primitive.ObjectID, pengemudi string, start, end time.Time, excludeID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	or := []bson.M{{"kendaraan_id": kendaraanID}}
+	if pengemudi != "" {
+		or = append(or, bson.M{"pengemudi": pengemudi})
+	}
+	filter := bson.M{
+		"$or":             or,
+		"waktu_berangkat": bson.M{"$lt": end},
+		"estimasi_tiba":   bson.M{"$gt": start},
+	}
+	if !excludeID.IsZero() {
+		filter["_id"] = bson.M{"$ne": excludeID}
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := s.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	ids := []primitive.ObjectID{}
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	return ids, nil
+}
+
+func (s *mongoJadwalStore) FindOverlapping(ctx context.Context, kendaraanIDs []primitive.ObjectID, pengemudi []string, start, end time.Time) ([]models.Jadwal, error) {
+	or := []bson.M{}
+	if len(kendaraanIDs) > 0 {
+		or = append(or, bson.M{"kendaraan_id": bson.M{"$in": kendaraanIDs}})
+	}
+	if len(pengemudi) > 0 {
+		or = append(or, bson.M{"pengemudi": bson.M{"$in": pengemudi}})
+	}
+	if len(or) == 0 {
+		return []models.Jadwal{}, nil
+	}
+	filter := bson.M{
+		"$or":             or,
+		"waktu_berangkat": bson.M{"$lt": end},
+		"estimasi_tiba":   bson.M{"$gt": start},
+	}
+
+	opts := options.Find().SetProjection(bson.M{"kendaraan_id": 1, "pengemudi": 1, "waktu_berangkat": 1, "estimasi_tiba": 1})
+	cursor, err := s.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	jadwals := []models.Jadwal{}
+	err = cursor.All(ctx, &jadwals)
+	return jadwals, err
+}
+
+func (s *mongoJadwalStore) FindByRuteTanggal(ctx context.Context, keys []RuteTanggal) ([]models.Jadwal, error) {
+	if len(keys) == 0 {
+		return []models.Jadwal{}, nil
+	}
+	or := []bson.M{}
+	for _, k := range keys {
+		or = append(or, bson.M{"rute_id": k.RuteID, "tanggal": k.Tanggal})
+	}
+	return s.find(ctx, bson.M{"$or": or})
+}
+
+func (s *mongoJadwalStore) FindDepartingBetween(ctx context.Context, from, until time.Time) ([]models.Jadwal, error) {
+	return s.find(ctx, bson.M{"waktu_berangkat": bson.M{"$gte": from, "$lt": until}})
+}
+
+func (s *mongoJadwalStore) ReserveSeats(ctx context.Context, id primitive.ObjectID, jumlah, kapasitas int) (bool, error) {
+	if jumlah > kapasitas {
+		return false, nil
+	}
+
+	// Update hanya terjadi jika sisa kursi masih cukup, sehingga dua request
+	// yang bersamaan tidak bisa menjual kursi terakhir dua kali
+	filter := bson.M{
+		"_id": id,
+		"$or": []bson.M{
+			{"kursi_terisi": bson.M{"$lte": kapasitas - jumlah}},
+			{"kursi_terisi": bson.M{"$exists": false}},
+		},
+	}
+	res, err := s.coll.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"kursi_terisi": jumlah}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+func (s *mongoJadwalStore) ReleaseSeats(ctx context.Context, id primitive.ObjectID, jumlah int) error {
+	_, err := s.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"kursi_terisi": -jumlah}})
+	return err
+}
+
+func (s *mongoJadwalStore) CreateFromTemplate(ctx context.Context, jadwal *models.Jadwal) (bool, error) {
+	if jadwal.ID.IsZero() {
+		jadwal.ID = primitive.NewObjectID()
+	}
+
+	// Upsert pada (template_id, tanggal) menjaga generator tetap idempoten
+	// walaupun dijalankan bersamaan dari beberapa instance
+	res, err := s.coll.UpdateOne(ctx,
+		bson.M{"template_id": jadwal.TemplateID, "tanggal": jadwal.Tanggal},
+		bson.M{"$setOnInsert": jadwal},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount == 1, nil
+}
+
+func (s *mongoJadwalStore) ExistsForTemplate(ctx context.Context, templateID primitive.ObjectID, tanggal time.Time) (bool, error) {
+	count, err := s.coll.CountDocuments(ctx, bson.M{"template_id": templateID, "tanggal": tanggal})
+	return count > 0, err
+}
+
+func (s *mongoJadwalStore) FindLegacyWaktu(ctx context.Context) ([]LegacyJadwal, error) {
+	cursor, err := s.coll.Find(ctx, bson.M{"tanggal": bson.M{"$type": "string"}})
+	if err != nil {
+		return nil, err
+	}
+	jadwals := []LegacyJadwal{}
+	err = cursor.All(ctx, &jadwals)
+	return jadwals, err
+}
+
+func (s *mongoJadwalStore) SetWaktu(ctx context.Context, id primitive.ObjectID, tanggal, berangkat, tiba time.Time) error {
+	return matched(s.coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{
+		"tanggal":         tanggal,
+		"waktu_berangkat": berangkat,
+		"estimasi_tiba":   tiba,
+	}}))
+}
+
+func (s *mongoJadwalStore) find(ctx context.Context, filter bson.M) ([]models.Jadwal, error) {
+	cursor, err := s.coll.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	jadwals := []models.Jadwal{}
+	err = cursor.All(ctx, &jadwals)
+	return jadwals, err
+}
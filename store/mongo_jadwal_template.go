@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoJadwalTemplateStore struct {
+	coll *mongo.Collection
+}
+
+func (s *mongoJadwalTemplateStore) All(ctx context.Context) ([]models.JadwalTemplate, error) {
+	return s.find(ctx, bson.M{})
+}
+
+func (s *mongoJadwalTemplateStore) ListActive(ctx context.Context) ([]models.JadwalTemplate, error) {
+	return s.find(ctx, bson.M{"aktif": true})
+}
+
+func (s *mongoJadwalTemplateStore) Get(ctx context.Context, id primitive.ObjectID) (models.JadwalTemplate, error) {
+	var tpl models.JadwalTemplate
+	err := s.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&tpl)
+	return tpl, notFound(err)
+}
+
+func (s *mongoJadwalTemplateStore) Create(ctx context.Context, tpl *models.JadwalTemplate) error {
+	if tpl.ID.IsZero() {
+		tpl.ID = primitive.NewObjectID()
+	}
+	_, err := s.coll.InsertOne(ctx, tpl)
+	return err
+}
+
+func (s *mongoJadwalTemplateStore) Replace(ctx context.Context, tpl models.JadwalTemplate) error {
+	res, err := s.coll.ReplaceOne(ctx, bson.M{"_id": tpl.ID}, tpl)
+	return matched(res, err)
+}
+
+func (s *mongoJadwalTemplateStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return deleted(s.coll.DeleteOne(ctx, bson.M{"_id": id}))
+}
+
+func (s *mongoJadwalTemplateStore) find(ctx context.Context, filter bson.M) ([]models.JadwalTemplate, error) {
+	cursor, err := s.coll.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	templates := []models.JadwalTemplate{}
+	err = cursor.All(ctx, &templates)
+	return templates, err
+}
@@ -0,0 +1,198 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+	"transport-app/models"
+	"transport-app/query"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// jadwalDoc adalah hasil aggregate satu jadwal yang rute dan kendaraannya
+// sudah digabung oleh $lookup
+func jadwalDoc(i int) bson.D {
+	return bson.D{
+		{Key: "_id", Value: primitive.NewObjectID()},
+		{Key: "rute_id", Value: primitive.NewObjectID()},
+		{Key: "kendaraan_id", Value: primitive.NewObjectID()},
+		{Key: "rute", Value: bson.D{{Key: "kode_rute", Value: fmt.Sprintf("R%d", i)}}},
+		{Key: "kendaraan", Value: bson.D{{Key: "nomor_polisi", Value: fmt.Sprintf("B %d XY", i)}}},
+	}
+}
+
+// TestJadwalListQueryCount memastikan List tidak melakukan query tambahan
+// per jadwal untuk rute dan kendaraannya
+func TestJadwalListQueryCount(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	for _, n := range []int{1, 10, 100} {
+		mt.Run(fmt.Sprintf("%d jadwal", n), func(mt *mtest.T) {
+			s := NewMongoStores(mt.DB)
+
+			docs := make([]bson.D, n)
+			for i := range docs {
+				docs[i] = jadwalDoc(i)
+			}
+			mt.AddMockResponses(
+				mtest.CreateCursorResponse(0, "test.jadwal", mtest.FirstBatch, docs...),
+				mtest.CreateCursorResponse(0, "test.jadwal", mtest.FirstBatch, bson.D{{Key: "n", Value: n}}),
+			)
+
+			page, err := s.Jadwal.List(context.Background(), query.ListQuery{Page: 1, Limit: 100})
+			if err != nil {
+				mt.Fatal(err)
+			}
+			if len(page.Data) != n || page.Total != int64(n) {
+				mt.Fatalf("dapat %d jadwal dari total %d, seharusnya %d", len(page.Data), page.Total, n)
+			}
+			for i, j := range page.Data {
+				if j.Rute.KodeRute != fmt.Sprintf("R%d", i) || j.Kendaraan.NomorPolisi != fmt.Sprintf("B %d XY", i) {
+					mt.Fatalf("jadwal %d tidak berisi rute dan kendaraan hasil $lookup: %+v", i, j)
+				}
+			}
+
+			// Satu aggregate untuk halaman beserta join, satu untuk total
+			events := mt.GetAllStartedEvents()
+			if len(events) != 2 {
+				names := []string{}
+				for _, e := range events {
+					names = append(names, e.CommandName)
+				}
+				mt.Fatalf("dapat %d query %v untuk %d jadwal, seharusnya 2", len(events), names, n)
+			}
+
+			pipeline, err := events[0].Command.LookupErr("pipeline")
+			if err != nil {
+				mt.Fatal(err)
+			}
+			lookups := map[string]bool{}
+			values, _ := pipeline.Array().Values()
+			for _, v := range values {
+				if from, err := v.Document().LookupErr("$lookup", "from"); err == nil {
+					lookups[from.StringValue()] = true
+				}
+			}
+			if !lookups["rutes"] || !lookups["kendaraan"] {
+				mt.Fatalf("pipeline tidak menggabungkan rute dan kendaraan: %v", pipeline)
+			}
+		})
+	}
+}
+
+// TestJadwalFindOverlapping memastikan semua kendaraan dan pengemudi
+// diperiksa dalam satu query find
+func TestJadwalFindOverlapping(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("satu query", func(mt *mtest.T) {
+		s := NewMongoStores(mt.DB)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.jadwal", mtest.FirstBatch, jadwalDoc(0)))
+
+		ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()}
+		start := time.Date(2030, 7, 22, 0, 0, 0, 0, time.UTC)
+		jadwals, err := s.Jadwal.FindOverlapping(context.Background(), ids, []string{"Budi"}, start, start.AddDate(0, 0, 60))
+		if err != nil {
+			mt.Fatal(err)
+		}
+		if len(jadwals) != 1 {
+			mt.Fatalf("dapat %d jadwal, seharusnya 1", len(jadwals))
+		}
+
+		events := mt.GetAllStartedEvents()
+		if len(events) != 1 || events[0].CommandName != "find" {
+			mt.Fatalf("dapat %d query, seharusnya satu find", len(events))
+		}
+		in, err := events[0].Command.LookupErr("filter", "$or", "0", "kendaraan_id", "$in")
+		if err != nil {
+			mt.Fatal(err)
+		}
+		if values, _ := in.Array().Values(); len(values) != len(ids) {
+			mt.Fatalf("filter memuat %d kendaraan, seharusnya %d", len(values), len(ids))
+		}
+	})
+
+	mt.Run("tanpa kendaraan dan pengemudi", func(mt *mtest.T) {
+		s := NewMongoStores(mt.DB)
+		jadwals, err := s.Jadwal.FindOverlapping(context.Background(), nil, nil, time.Now(), time.Now().Add(time.Hour))
+		if err != nil || len(jadwals) != 0 || len(mt.GetAllStartedEvents()) != 0 {
+			mt.Fatalf("dapat %v %v dengan %d query, seharusnya kosong tanpa query", jadwals, err, len(mt.GetAllStartedEvents()))
+		}
+	})
+}
+
+// BenchmarkJadwalList mengukur List terhadap MongoDB sungguhan dengan jumlah
+// jadwal yang terus bertambah dan melaporkan jumlah query per List, yang
+// harus tetap sama berapa pun jumlah jadwalnya. Mock mtest hanya menerima
+// *testing.T, jadi benchmark ini memakai server di MONGO_URI dan dilewati
+// jika variabel itu kosong:
+//
+//	MONGO_URI=mongodb://localhost:27017 go test ./store -run '^$' -bench JadwalList
+func BenchmarkJadwalList(b *testing.B) {
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		b.Skip("MONGO_URI kosong")
+	}
+
+	var queries int64
+	monitor := &event.CommandMonitor{
+		Started: func(context.Context, *event.CommandStartedEvent) { atomic.AddInt64(&queries, 1) },
+	}
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetMonitor(monitor))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer client.Disconnect(ctx)
+
+	for _, n := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("%d jadwal", n), func(b *testing.B) {
+			db := client.Database(fmt.Sprintf("bench_jadwal_%s", primitive.NewObjectID().Hex()))
+			defer db.Drop(ctx)
+			s := NewMongoStores(db)
+
+			// Setiap jadwal memakai rute dan kendaraan sendiri agar $lookup
+			// tidak diuntungkan oleh dokumen yang sama
+			rutes := make([]interface{}, n)
+			kendaraans := make([]interface{}, n)
+			jadwals := make([]interface{}, n)
+			start := time.Date(2030, 7, 22, 8, 0, 0, 0, time.UTC)
+			for i := 0; i < n; i++ {
+				rute := models.Rute{ID: primitive.NewObjectID(), KodeRute: fmt.Sprintf("R%d", i), Asal: "A", Tujuan: "B", JarakKM: 10}
+				kendaraan := models.Kendaraan{ID: primitive.NewObjectID(), NomorPolisi: fmt.Sprintf("B %d XY", i), Kapasitas: 40}
+				dep := start.Add(time.Duration(i) * time.Minute)
+				rutes[i], kendaraans[i] = rute, kendaraan
+				jadwals[i] = models.Jadwal{
+					ID: primitive.NewObjectID(), Tanggal: start.Truncate(24 * time.Hour), WaktuBerangkat: dep, EstimasiTiba: dep.Add(time.Hour),
+					RuteID: rute.ID, KendaraanID: kendaraan.ID,
+				}
+			}
+			for name, docs := range map[string][]interface{}{"rutes": rutes, "kendaraan": kendaraans, "jadwal": jadwals} {
+				if _, err := db.Collection(name).InsertMany(ctx, docs); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			atomic.StoreInt64(&queries, 0)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.Jadwal.List(ctx, query.ListQuery{Page: 1, Limit: query.MaxLimit, Sorts: []query.Sort{{Field: "_id"}}}); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(atomic.LoadInt64(&queries))/float64(b.N), "queries/op")
+		})
+	}
+}
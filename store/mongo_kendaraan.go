@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"transport-app/models"
+	"transport-app/query"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoKendaraanStore struct {
+	coll *mongo.Collection
+}
+
+func (s *mongoKendaraanStore) List(ctx context.Context, q query.ListQuery) (query.Page[models.Kendaraan], error) {
+	return query.Find[models.Kendaraan](ctx, s.coll, q)
+}
+
+func (s *mongoKendaraanStore) Get(ctx context.Context, id primitive.ObjectID) (models.Kendaraan, error) {
+	var kendaraan models.Kendaraan
+	err := s.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&kendaraan)
+	return kendaraan, notFound(err)
+}
+
+func (s *mongoKendaraanStore) GetByNomorPolisi(ctx context.Context, nomorPolisi string) (models.Kendaraan, error) {
+	var kendaraan models.Kendaraan
+	err := s.coll.FindOne(ctx, bson.M{"nomor_polisi": nomorPolisi}).Decode(&kendaraan)
+	return kendaraan, notFound(err)
+}
+
+func (s *mongoKendaraanStore) GetMany(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Kendaraan, error) {
+	result := map[primitive.ObjectID]models.Kendaraan{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	cursor, err := s.coll.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var list []models.Kendaraan
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	for _, k := range list {
+		result[k.ID] = k
+	}
+	return result, nil
+}
+
+func (s *mongoKendaraanStore) Create(ctx context.Context, kendaraan *models.Kendaraan) error {
+	if kendaraan.ID.IsZero() {
+		kendaraan.ID = primitive.NewObjectID()
+	}
+	_, err := s.coll.InsertOne(ctx, kendaraan)
+	return err
+}
+
+func (s *mongoKendaraanStore) Update(ctx context.Context, kendaraan models.Kendaraan) error {
+	update := bson.M{"$set": bson.M{
+		"nomor_polisi": kendaraan.NomorPolisi,
+		"jenis":        kendaraan.Jenis,
+		"kapasitas":    kendaraan.Kapasitas,
+		"status":       kendaraan.Status,
+	}}
+	return matched(s.coll.UpdateByID(ctx, kendaraan.ID, update))
+}
+
+func (s *mongoKendaraanStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return deleted(s.coll.DeleteOne(ctx, bson.M{"_id": id}))
+}
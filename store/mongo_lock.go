@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoLockStore struct {
+	coll *mongo.Collection
+}
+
+// EnsureIndexes membuat index TTL agar kunci yang tidak dilepas dihapus otomatis
+func (s *mongoLockStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (s *mongoLockStore) Acquire(ctx context.Context, key, owner string, until time.Time) error {
+	// Jika kunci masih dipegang owner lain, filter tidak cocok dan upsert
+	// gagal karena _id yang sama sudah ada
+	filter := bson.M{
+		"_id": key,
+		"$or": []bson.M{
+			{"expires_at": bson.M{"$lte": time.Now()}},
+			{"owner": owner},
+		},
+	}
+	update := bson.M{"$set": bson.M{"owner": owner, "expires_at": until}}
+	_, err := s.coll.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrLocked
+	}
+	return err
+}
+
+func (s *mongoLockStore) Release(ctx context.Context, key, owner string) error {
+	_, err := s.coll.DeleteOne(ctx, bson.M{"_id": key, "owner": owner})
+	return err
+}
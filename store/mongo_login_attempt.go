@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"time"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoLoginAttemptStore struct {
+	coll *mongo.Collection
+}
+
+// EnsureIndexes membuat index TTL agar hitungan yang kedaluwarsa dihapus otomatis
+func (s *mongoLoginAttemptStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (s *mongoLoginAttemptStore) Get(ctx context.Context, key string) (models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	// TTL MongoDB berjalan berkala, jadi dokumen kedaluwarsa bisa masih ada
+	filter := bson.M{"_id": key, "expires_at": bson.M{"$gt": time.Now()}}
+	err := s.coll.FindOne(ctx, filter).Decode(&attempt)
+	return attempt, notFound(err)
+}
+
+func (s *mongoLoginAttemptStore) RecordFailure(ctx context.Context, key string, now time.Time, ttl time.Duration) (models.LoginAttempt, error) {
+	// Hitungan yang sudah kedaluwarsa tapi belum dihapus TTL dibuang dulu
+	if _, err := s.coll.DeleteOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$lte": now}}); err != nil {
+		return models.LoginAttempt{}, err
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"failures": 1},
+		"$set": bson.M{"last_failure": now},
+		"$max": bson.M{"expires_at": now.Add(ttl)},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempt models.LoginAttempt
+	err := s.coll.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&attempt)
+	return attempt, err
+}
+
+// attemptRetries membatasi compare-and-swap Attempt saat banyak percobaan
+// bersamaan; setelah itu percobaan ditolak seperti kunci yang terkunci
+const attemptRetries = 5
+
+func (s *mongoLoginAttemptStore) Attempt(ctx context.Context, key string, now time.Time, ttl time.Duration, lockFor func(failures int) time.Duration) (models.LoginAttempt, error) {
+	current := models.LoginAttempt{Key: key}
+	for i := 0; i < attemptRetries; i++ {
+		var err error
+		current, err = s.Get(ctx, key)
+		if err != nil && err != ErrNotFound {
+			return current, err
+		}
+		exists := err == nil
+		if current.Locked(now) {
+			return current, ErrLocked
+		}
+
+		next := nextAttempt(current, key, now, ttl, lockFor)
+		if !exists {
+			// Hitungan yang sudah kedaluwarsa tapi belum dihapus TTL dibuang dulu
+			if _, err := s.coll.DeleteOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$lte": now}}); err != nil {
+				return current, err
+			}
+			_, err := s.coll.InsertOne(ctx, next)
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+			return next, err
+		}
+
+		// Hanya berhasil jika tidak ada percobaan lain yang tercatat sejak
+		// dokumen dibaca
+		filter := bson.M{"_id": key, "failures": current.Failures, "last_failure": current.LastFailure}
+		res, err := s.coll.ReplaceOne(ctx, filter, next)
+		if err != nil {
+			return current, err
+		}
+		if res.MatchedCount == 1 {
+			return next, nil
+		}
+	}
+	return current, ErrLocked
+}
+
+func (s *mongoLoginAttemptStore) Forgive(ctx context.Context, key string) error {
+	_, err := s.coll.UpdateOne(ctx, bson.M{"_id": key, "failures": bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{"failures": -1}})
+	return err
+}
+
+func (s *mongoLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	_, err := s.coll.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
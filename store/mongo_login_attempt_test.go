@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func attemptDoc(failures int, last, lockedUntil time.Time) bson.D {
+	return bson.D{
+		{Key: "_id", Value: "user:budi"},
+		{Key: "failures", Value: failures},
+		{Key: "last_failure", Value: last},
+		{Key: "locked_until", Value: lockedUntil},
+		{Key: "expires_at", Value: last.Add(time.Hour)},
+	}
+}
+
+// TestLoginAttemptCAS memastikan Attempt hanya menyimpan hitungan jika
+// dokumen tidak diubah percobaan lain sejak dibaca, dan tidak menulis apa
+// pun selama kunci masih berlaku
+func TestLoginAttemptCAS(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	now := time.Now().Truncate(time.Millisecond)
+	lockFor := func(failures int) time.Duration {
+		if failures > 3 {
+			return time.Minute
+		}
+		return 0
+	}
+
+	mt.Run("percobaan lain lebih dulu", func(mt *mtest.T) {
+		s := NewMongoStores(mt.DB)
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "test.login_attempts", mtest.FirstBatch, attemptDoc(2, now.Add(-time.Second), time.Time{})),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}},
+			mtest.CreateCursorResponse(0, "test.login_attempts", mtest.FirstBatch, attemptDoc(3, now.Add(-time.Millisecond), time.Time{})),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+		)
+
+		attempt, err := s.LoginAttempt.Attempt(context.Background(), "user:budi", now, time.Hour, lockFor)
+		if err != nil {
+			mt.Fatal(err)
+		}
+		if attempt.Failures != 4 || !attempt.LockedUntil.Equal(now.Add(time.Minute)) {
+			mt.Fatalf("hitungan %d terkunci sampai %v, seharusnya 4 dan terkunci semenit", attempt.Failures, attempt.LockedUntil)
+		}
+
+		events := mt.GetAllStartedEvents()
+		if len(events) != 4 {
+			mt.Fatalf("%d perintah, seharusnya baca dan tulis dua kali", len(events))
+		}
+		filter := events[3].Command.Lookup("updates", "0", "q")
+		if got := filter.Document().Lookup("failures").AsInt64(); got != 3 {
+			mt.Fatalf("replace memakai failures %d, seharusnya hasil baca terakhir", got)
+		}
+	})
+
+	mt.Run("sedang dikunci", func(mt *mtest.T) {
+		s := NewMongoStores(mt.DB)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.login_attempts", mtest.FirstBatch, attemptDoc(4, now, now.Add(time.Minute))))
+
+		_, err := s.LoginAttempt.Attempt(context.Background(), "user:budi", now, time.Hour, lockFor)
+		if err != ErrLocked {
+			mt.Fatalf("dapat %v, seharusnya ErrLocked", err)
+		}
+		if n := len(mt.GetAllStartedEvents()); n != 1 {
+			mt.Fatalf("%d perintah, seharusnya hanya membaca", n)
+		}
+	})
+}
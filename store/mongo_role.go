@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRoleStore struct {
+	coll *mongo.Collection
+}
+
+// EnsureIndexes membuat index unik pada nama role
+func (s *mongoRoleStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (s *mongoRoleStore) All(ctx context.Context) ([]models.Role, error) {
+	cursor, err := s.coll.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	roles := []models.Role{}
+	err = cursor.All(ctx, &roles)
+	return roles, err
+}
+
+func (s *mongoRoleStore) GetByName(ctx context.Context, name string) (models.Role, error) {
+	var role models.Role
+	err := s.coll.FindOne(ctx, bson.M{"name": name}).Decode(&role)
+	return role, notFound(err)
+}
+
+func (s *mongoRoleStore) Create(ctx context.Context, role *models.Role) error {
+	if role.ID.IsZero() {
+		role.ID = primitive.NewObjectID()
+	}
+	_, err := s.coll.InsertOne(ctx, role)
+	return err
+}
+
+func (s *mongoRoleStore) CreateIfMissing(ctx context.Context, role models.Role) (bool, error) {
+	if role.ID.IsZero() {
+		role.ID = primitive.NewObjectID()
+	}
+	res, err := s.coll.UpdateOne(ctx,
+		bson.M{"name": role.Name},
+		bson.M{"$setOnInsert": role},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount == 1, nil
+}
+
+func (s *mongoRoleStore) Update(ctx context.Context, role models.Role) error {
+	update := bson.M{"$set": bson.M{
+		"description": role.Description,
+		"permissions": role.Permissions,
+	}}
+	return matched(s.coll.UpdateOne(ctx, bson.M{"name": role.Name}, update))
+}
+
+func (s *mongoRoleStore) Delete(ctx context.Context, name string) error {
+	return deleted(s.coll.DeleteOne(ctx, bson.M{"name": name}))
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"transport-app/models"
+	"transport-app/query"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRuteStore struct {
+	coll *mongo.Collection
+}
+
+func (s *mongoRuteStore) List(ctx context.Context, q query.ListQuery) (query.Page[models.Rute], error) {
+	return query.Find[models.Rute](ctx, s.coll, q)
+}
+
+func (s *mongoRuteStore) All(ctx context.Context) ([]models.Rute, error) {
+	return s.find(ctx, bson.M{})
+}
+
+func (s *mongoRuteStore) Get(ctx context.Context, id primitive.ObjectID) (models.Rute, error) {
+	var rute models.Rute
+	err := s.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&rute)
+	return rute, notFound(err)
+}
+
+func (s *mongoRuteStore) GetByKode(ctx context.Context, kode string) (models.Rute, error) {
+	var rute models.Rute
+	err := s.coll.FindOne(ctx, bson.M{"kode_rute": kode}).Decode(&rute)
+	return rute, notFound(err)
+}
+
+func (s *mongoRuteStore) FindByAsalTujuan(ctx context.Context, asal, tujuan string) ([]models.Rute, error) {
+	return s.find(ctx, bson.M{
+		"asal":   exactMatchInsensitive(asal),
+		"tujuan": exactMatchInsensitive(tujuan),
+	})
+}
+
+func (s *mongoRuteStore) Create(ctx context.Context, rute *models.Rute) error {
+	if rute.ID.IsZero() {
+		rute.ID = primitive.NewObjectID()
+	}
+	_, err := s.coll.InsertOne(ctx, rute)
+	return err
+}
+
+func (s *mongoRuteStore) Update(ctx context.Context, rute models.Rute) error {
+	update := bson.M{"$set": bson.M{
+		"kode_rute":  rute.KodeRute,
+		"nama_rute":  rute.NamaRute,
+		"asal":       rute.Asal,
+		"tujuan":     rute.Tujuan,
+		"jarak_km":   rute.JarakKM,
+		"zona_waktu": rute.ZonaWaktu,
+		"halte":      rute.Halte,
+	}}
+	return matched(s.coll.UpdateByID(ctx, rute.ID, update))
+}
+
+func (s *mongoRuteStore) SetGeometri(ctx context.Context, rute models.Rute) error {
+	update := bson.M{"$unset": bson.M{"geometri": ""}}
+	if rute.Geometri != nil {
+		update = bson.M{"$set": bson.M{
+			"geometri": rute.Geometri,
+			"jarak_km": rute.JarakKM,
+			"halte":    rute.Halte,
+		}}
+	}
+	return matched(s.coll.UpdateByID(ctx, rute.ID, update))
+}
+
+func (s *mongoRuteStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return deleted(s.coll.DeleteOne(ctx, bson.M{"_id": id}))
+}
+
+func (s *mongoRuteStore) UsesHalte(ctx context.Context, halteID primitive.ObjectID) (bool, error) {
+	count, err := s.coll.CountDocuments(ctx, bson.M{"halte.halte_id": halteID}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+func (s *mongoRuteStore) FindByHalte(ctx context.Context, halteIDs []primitive.ObjectID) ([]models.Rute, error) {
+	if len(halteIDs) == 0 {
+		return []models.Rute{}, nil
+	}
+	return s.find(ctx, bson.M{"halte.halte_id": bson.M{"$in": halteIDs}})
+}
+
+func (s *mongoRuteStore) find(ctx context.Context, filter bson.M) ([]models.Rute, error) {
+	cursor, err := s.coll.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	rutes := []models.Rute{}
+	err = cursor.All(ctx, &rutes)
+	return rutes, err
+}
+
+// Pencocokan nama kota tidak membedakan huruf besar/kecil
+func exactMatchInsensitive(value string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(strings.TrimSpace(value)) + "$", Options: "i"}
+}
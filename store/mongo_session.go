@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"time"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoSessionStore struct {
+	coll *mongo.Collection
+}
+
+// EnsureIndexes membuat index user_id untuk pencabutan semua session user
+// dan index TTL agar session yang kedaluwarsa dihapus otomatis
+func (s *mongoSessionStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return err
+}
+
+func (s *mongoSessionStore) Create(ctx context.Context, session *models.Session) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	_, err := s.coll.InsertOne(ctx, session)
+	return err
+}
+
+func (s *mongoSessionStore) Get(ctx context.Context, id primitive.ObjectID) (models.Session, error) {
+	var session models.Session
+	err := s.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	return session, notFound(err)
+}
+
+func (s *mongoSessionStore) Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, expiresAt time.Time) error {
+	now := time.Now()
+	filter := bson.M{
+		"_id":          id,
+		"refresh_hash": oldHash,
+		"revoked_at":   bson.M{"$exists": false},
+		"expires_at":   bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{
+		"refresh_hash": newHash,
+		"last_used_at": now,
+		"expires_at":   expiresAt,
+	}}
+	return matched(s.coll.UpdateOne(ctx, filter, update))
+}
+
+func (s *mongoSessionStore) Revoke(ctx context.Context, id primitive.ObjectID, reason string) error {
+	filter := bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now(), "revoked_reason": reason}}
+	_, err := s.coll.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (s *mongoSessionStore) RevokeAllForUser(ctx context.Context, userID primitive.ObjectID, reason string) error {
+	filter := bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now(), "revoked_reason": reason}}
+	_, err := s.coll.UpdateMany(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"context"
+	"time"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoSigningKeyStore struct {
+	coll *mongo.Collection
+}
+
+// EnsureIndexes membuat index TTL agar kunci yang sudah pensiun dihapus otomatis
+func (s *mongoSigningKeyStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "retire_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (s *mongoSigningKeyStore) List(ctx context.Context, now time.Time) ([]models.SigningKey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "active_from", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := s.coll.Find(ctx, bson.M{"retire_at": bson.M{"$gt": now}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	keys := []models.SigningKey{}
+	err = cursor.All(ctx, &keys)
+	return keys, err
+}
+
+func (s *mongoSigningKeyStore) Create(ctx context.Context, key *models.SigningKey) error {
+	_, err := s.coll.InsertOne(ctx, key)
+	return err
+}
@@ -0,0 +1,159 @@
+package store
+
+import (
+	"context"
+	"regexp"
+	"transport-app/models"
+	"transport-app/query"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoUserStore struct {
+	coll *mongo.Collection
+}
+
+// EnsureIndexes membuat index unik username, email dan identitas OIDC.
+// Index email dan OIDC hanya berlaku untuk user yang mengisinya. Index ini
+// yang menjamin keunikan saat dua request mendaftar atau mengubah profil
+// bersamaan; pengecekan di handler hanya untuk pesan error yang jelas.
+func (s *mongoUserStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"email": bson.M{"$gt": ""}}),
+		},
+		{
+			Keys: bson.D{{Key: "oidc.issuer", Value: 1}, {Key: "oidc.subject", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"oidc.subject": bson.M{"$exists": true}}),
+		},
+	})
+	return err
+}
+
+func (s *mongoUserStore) List(ctx context.Context, q query.ListQuery) (query.Page[models.User], error) {
+	var where bson.M
+	if q.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(q.Search), Options: "i"}
+		where = bson.M{"$or": bson.A{
+			bson.M{"username": pattern},
+			bson.M{"email": pattern},
+		}}
+	}
+	return query.FindWhere[models.User](ctx, s.coll, q, where)
+}
+
+func (s *mongoUserStore) Get(ctx context.Context, id primitive.ObjectID) (models.User, error) {
+	var user models.User
+	err := s.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	return user, notFound(err)
+}
+
+func (s *mongoUserStore) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	var user models.User
+	err := s.coll.FindOne(ctx, bson.M{"username": username}).Decode(&user)
+	return user, notFound(err)
+}
+
+func (s *mongoUserStore) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	var user models.User
+	err := s.coll.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	return user, notFound(err)
+}
+
+func (s *mongoUserStore) ExistsByUsernameOrEmail(ctx context.Context, username, email string) (bool, error) {
+	// Menggunakan $or untuk memeriksa keduanya dalam satu query
+	filter := bson.M{
+		"$or": []bson.M{
+			{"username": username},
+			{"email": email},
+		},
+	}
+	count, err := s.coll.CountDocuments(ctx, filter)
+	return count > 0, err
+}
+
+func (s *mongoUserStore) Create(ctx context.Context, user *models.User) error {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	_, err := s.coll.InsertOne(ctx, user)
+	return duplicate(err)
+}
+
+func (s *mongoUserStore) UpdatePassword(ctx context.Context, id primitive.ObjectID, hash string) error {
+	return matched(s.coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"password": hash, "must_reset_password": false}}))
+}
+
+func (s *mongoUserStore) SetEmailVerified(ctx context.Context, id primitive.ObjectID) error {
+	return matched(s.coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"email_verified": true}}))
+}
+
+func (s *mongoUserStore) UpdateProfile(ctx context.Context, id primitive.ObjectID, username, email string, emailVerified bool) error {
+	res, err := s.coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{
+		"username":       username,
+		"email":          email,
+		"email_verified": emailVerified,
+	}})
+	return matched(res, duplicate(err))
+}
+
+func (s *mongoUserStore) SetRole(ctx context.Context, id primitive.ObjectID, role string) error {
+	return matched(s.coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"role": role}}))
+}
+
+func (s *mongoUserStore) CountByRole(ctx context.Context, role string) (int64, error) {
+	return s.coll.CountDocuments(ctx, bson.M{"role": role})
+}
+
+func (s *mongoUserStore) SetDisabled(ctx context.Context, id primitive.ObjectID, disabled bool) error {
+	return matched(s.coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"disabled": disabled}}))
+}
+
+func (s *mongoUserStore) SetMustResetPassword(ctx context.Context, id primitive.ObjectID, must bool) error {
+	return matched(s.coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"must_reset_password": must}}))
+}
+
+func (s *mongoUserStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return deleted(s.coll.DeleteOne(ctx, bson.M{"_id": id}))
+}
+
+func (s *mongoUserStore) SetTOTP(ctx context.Context, id primitive.ObjectID, totp models.TOTP) error {
+	return matched(s.coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"totp": totp}}))
+}
+
+func (s *mongoUserStore) UseTOTPStep(ctx context.Context, id primitive.ObjectID, step int64) error {
+	filter := bson.M{
+		"_id": id,
+		"$or": bson.A{
+			bson.M{"totp.last_step": bson.M{"$exists": false}},
+			bson.M{"totp.last_step": bson.M{"$lt": step}},
+		},
+	}
+	return matched(s.coll.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"totp.last_step": step}}))
+}
+
+func (s *mongoUserStore) UseRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string) error {
+	filter := bson.M{"_id": id, "totp.recovery_codes": hash}
+	return matched(s.coll.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"totp.recovery_codes": hash}}))
+}
+
+func (s *mongoUserStore) GetByOIDC(ctx context.Context, issuer, subject string) (models.User, error) {
+	var user models.User
+	err := s.coll.FindOne(ctx, bson.M{"oidc.issuer": issuer, "oidc.subject": subject}).Decode(&user)
+	return user, notFound(err)
+}
+
+func (s *mongoUserStore) SetOIDC(ctx context.Context, id primitive.ObjectID, identity models.ExternalIdentity) error {
+	res, err := s.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"oidc": identity}})
+	return matched(res, duplicate(err))
+}
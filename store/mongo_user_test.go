@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// TestUserDuplicate memastikan pelanggaran index unik username atau email
+// dikembalikan sebagai ErrDuplicate, termasuk saat dua request bersamaan
+// sama-sama lolos pengecekan di handler
+func TestUserDuplicate(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	duplicateKey := mtest.WriteError{Index: 0, Code: 11000, Message: "E11000 duplicate key error collection: test.users index: username_1"}
+
+	mt.Run("create", func(mt *mtest.T) {
+		s := NewMongoStores(mt.DB)
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(duplicateKey))
+
+		err := s.User.Create(context.Background(), &models.User{Username: "budi", Email: "budi@example.com"})
+		if err != ErrDuplicate {
+			mt.Fatalf("dapat %v, seharusnya ErrDuplicate", err)
+		}
+	})
+
+	mt.Run("update profile", func(mt *mtest.T) {
+		s := NewMongoStores(mt.DB)
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(duplicateKey))
+
+		err := s.User.UpdateProfile(context.Background(), primitive.NewObjectID(), "budi", "budi@example.com", false)
+		if err != ErrDuplicate {
+			mt.Fatalf("dapat %v, seharusnya ErrDuplicate", err)
+		}
+	})
+}
+
+func TestMemoryUserDuplicate(t *testing.T) {
+	s := NewMemoryStores()
+	ctx := context.Background()
+
+	budi := models.User{Username: "budi", Email: "budi@example.com"}
+	if err := s.User.Create(ctx, &budi); err != nil {
+		t.Fatal(err)
+	}
+	siti := models.User{Username: "siti", Email: "siti@example.com"}
+	if err := s.User.Create(ctx, &siti); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.User.Create(ctx, &models.User{Username: "budi", Email: "lain@example.com"}); err != ErrDuplicate {
+		t.Fatalf("username ganda: dapat %v, seharusnya ErrDuplicate", err)
+	}
+	if err := s.User.Create(ctx, &models.User{Username: "lain", Email: "budi@example.com"}); err != ErrDuplicate {
+		t.Fatalf("email ganda: dapat %v, seharusnya ErrDuplicate", err)
+	}
+	if err := s.User.UpdateProfile(ctx, siti.ID, "budi", siti.Email, true); err != ErrDuplicate {
+		t.Fatalf("update ke username user lain: dapat %v, seharusnya ErrDuplicate", err)
+	}
+	if err := s.User.UpdateProfile(ctx, budi.ID, "budi", "budi.baru@example.com", false); err != nil {
+		t.Fatalf("update profil sendiri: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"time"
+	"transport-app/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoUserTokenStore struct {
+	coll *mongo.Collection
+}
+
+// EnsureIndexes membuat index unik pada hash token dan index TTL agar token
+// yang kedaluwarsa dihapus otomatis
+func (s *mongoUserTokenStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return err
+}
+
+func (s *mongoUserTokenStore) Create(ctx context.Context, token *models.UserToken) error {
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	_, err := s.coll.InsertOne(ctx, token)
+	return err
+}
+
+func (s *mongoUserTokenStore) Consume(ctx context.Context, purpose, hash string) (models.UserToken, error) {
+	now := time.Now()
+	filter := bson.M{
+		"token_hash": hash,
+		"purpose":    purpose,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+
+	var token models.UserToken
+	err := s.coll.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"used_at": now}}).Decode(&token)
+	return token, notFound(err)
+}
+
+func (s *mongoUserTokenStore) DeleteForUser(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	_, err := s.coll.DeleteMany(ctx, bson.M{
+		"user_id": userID,
+		"purpose": purpose,
+		"used_at": bson.M{"$exists": false},
+	})
+	return err
+}
+
+func (s *mongoUserTokenStore) Latest(ctx context.Context, userID primitive.ObjectID, purpose string) (models.UserToken, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	var token models.UserToken
+	err := s.coll.FindOne(ctx, bson.M{"user_id": userID, "purpose": purpose}, opts).Decode(&token)
+	return token, notFound(err)
+}
@@ -0,0 +1,338 @@
+// Package store memisahkan akses data dari handler HTTP. Setiap entitas
+// punya interface store dengan implementasi MongoDB untuk produksi dan
+// implementasi in-memory untuk pengujian tanpa database.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+	"transport-app/models"
+	"transport-app/query"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNotFound dikembalikan jika dokumen yang dicari tidak ada
+var ErrNotFound = errors.New("data tidak ditemukan")
+
+// ErrLocked dikembalikan jika kunci masih dipegang pemilik lain
+var ErrLocked = errors.New("kunci sedang dipakai")
+
+// ErrDuplicate dikembalikan jika data melanggar index unik, misalnya
+// username atau email yang sudah dipakai user lain
+var ErrDuplicate = errors.New("data sudah ada")
+
+// ErrBooked dikembalikan jika jadwal yang akan dihapus masih punya kursi
+// terisi dari booking yang belum dibatalkan
+var ErrBooked = errors.New("jadwal masih memiliki booking")
+
+type RuteStore interface {
+	List(ctx context.Context, q query.ListQuery) (query.Page[models.Rute], error)
+	All(ctx context.Context) ([]models.Rute, error)
+	Get(ctx context.Context, id primitive.ObjectID) (models.Rute, error)
+	GetByKode(ctx context.Context, kode string) (models.Rute, error)
+	// FindByAsalTujuan mencocokkan nama kota tanpa membedakan huruf besar/kecil
+	FindByAsalTujuan(ctx context.Context, asal, tujuan string) ([]models.Rute, error)
+	Create(ctx context.Context, rute *models.Rute) error
+	// Update tidak mengubah geometri rute
+	Update(ctx context.Context, rute models.Rute) error
+	// SetGeometri menyimpan geometri beserta jarak rute dan jarak halte yang
+	// dihitung darinya. Geometri nil menghapus geometri rute.
+	SetGeometri(ctx context.Context, rute models.Rute) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// UsesHalte memeriksa apakah ada rute yang melewati halte tersebut
+	UsesHalte(ctx context.Context, halteID primitive.ObjectID) (bool, error)
+	// FindByHalte mengembalikan rute yang melewati salah satu halte
+	FindByHalte(ctx context.Context, halteIDs []primitive.ObjectID) ([]models.Rute, error)
+}
+
+type HalteStore interface {
+	// List mencari Search pada kode dan nama tanpa membedakan huruf
+	// besar/kecil
+	List(ctx context.Context, q query.ListQuery) (query.Page[models.Halte], error)
+	Get(ctx context.Context, id primitive.ObjectID) (models.Halte, error)
+	GetByKode(ctx context.Context, kode string) (models.Halte, error)
+	GetMany(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Halte, error)
+	// Nearby mencari paling banyak limit halte dalam radius meter dari
+	// titik, diurutkan dari yang terdekat
+	Nearby(ctx context.Context, lat, lng, radiusM float64, limit int) ([]models.HalteTerdekat, error)
+	Create(ctx context.Context, halte *models.Halte) error
+	Update(ctx context.Context, halte models.Halte) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type KendaraanStore interface {
+	List(ctx context.Context, q query.ListQuery) (query.Page[models.Kendaraan], error)
+	Get(ctx context.Context, id primitive.ObjectID) (models.Kendaraan, error)
+	GetByNomorPolisi(ctx context.Context, nomorPolisi string) (models.Kendaraan, error)
+	GetMany(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Kendaraan, error)
+	Create(ctx context.Context, kendaraan *models.Kendaraan) error
+	Update(ctx context.Context, kendaraan models.Kendaraan) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// JadwalUpdate berisi field jadwal yang boleh diubah admin. Kursi terisi
+// sengaja tidak ada di sini karena hanya diubah lewat ReserveSeats/ReleaseSeats.
+type JadwalUpdate struct {
+	Tanggal        time.Time
+	WaktuBerangkat time.Time
+	EstimasiTiba   time.Time
+	RuteID         primitive.ObjectID
+	Pengemudi      string
+	// GTFS hanya diubah jika diisi, sehingga update admin tidak melepas
+	// jadwal dari trip GTFS-nya
+	GTFS *models.JadwalGTFS
+}
+
+// RuteTanggal menunjuk jadwal satu rute pada satu tanggal
+type RuteTanggal struct {
+	RuteID  primitive.ObjectID
+	Tanggal time.Time
+}
+
+// LegacyJadwal adalah dokumen jadwal lama yang tanggal dan waktunya masih
+// disimpan sebagai string
+type LegacyJadwal struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	Tanggal        string             `bson:"tanggal"`
+	WaktuBerangkat string             `bson:"waktu_berangkat"`
+	EstimasiTiba   string             `bson:"estimasi_tiba"`
+	RuteID         primitive.ObjectID `bson:"rute_id"`
+}
+
+type JadwalStore interface {
+	// List menggabungkan setiap jadwal dengan rute dan kendaraannya
+	List(ctx context.Context, q query.ListQuery) (query.Page[models.JadwalWithRute], error)
+	Get(ctx context.Context, id primitive.ObjectID) (models.Jadwal, error)
+	Create(ctx context.Context, jadwal *models.Jadwal) error
+	Update(ctx context.Context, id primitive.ObjectID, update JadwalUpdate) error
+	// Delete hanya menghapus jadwal tanpa kursi terisi, diperiksa secara
+	// atomik dengan ReserveSeats. ErrBooked berarti masih ada booking.
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// FindConflicts mencari jadwal lain dengan kendaraan (atau pengemudi,
+	// jika diisi) yang sama dan rentang waktu yang beririsan dengan [start, end)
+	FindConflicts(ctx context.Context, kendaraanID primitive.ObjectID, pengemudi string, start, end time.Time, excludeID primitive.ObjectID) ([]primitive.ObjectID, error)
+	// FindOverlapping mencari sekaligus semua jadwal dengan salah satu
+	// kendaraan atau pengemudi yang beririsan dengan [start, end). Hanya id,
+	// kendaraan, pengemudi dan waktunya yang diisi.
+	FindOverlapping(ctx context.Context, kendaraanIDs []primitive.ObjectID, pengemudi []string, start, end time.Time) ([]models.Jadwal, error)
+	FindByRuteTanggal(ctx context.Context, keys []RuteTanggal) ([]models.Jadwal, error)
+	FindDepartingBetween(ctx context.Context, from, until time.Time) ([]models.Jadwal, error)
+
+	// ReserveSeats menambah kursi terisi secara atomik hanya jika hasilnya
+	// tidak melebihi kapasitas. false berarti kursi tidak mencukupi.
+	ReserveSeats(ctx context.Context, id primitive.ObjectID, jumlah, kapasitas int) (bool, error)
+	ReleaseSeats(ctx context.Context, id primitive.ObjectID, jumlah int) error
+
+	// CreateFromTemplate menyimpan jadwal hasil template kecuali sudah ada
+	// jadwal dengan template dan tanggal yang sama. true berarti jadwal baru dibuat.
+	CreateFromTemplate(ctx context.Context, jadwal *models.Jadwal) (bool, error)
+	ExistsForTemplate(ctx context.Context, templateID primitive.ObjectID, tanggal time.Time) (bool, error)
+
+	// FindLegacyWaktu mengembalikan jadwal yang tanggalnya masih string
+	FindLegacyWaktu(ctx context.Context) ([]LegacyJadwal, error)
+	// SetWaktu menyimpan tanggal dan waktu jadwal sebagai date
+	SetWaktu(ctx context.Context, id primitive.ObjectID, tanggal, berangkat, tiba time.Time) error
+}
+
+type UserStore interface {
+	// List mencari Search pada username dan email tanpa membedakan huruf
+	// besar/kecil
+	List(ctx context.Context, q query.ListQuery) (query.Page[models.User], error)
+	Get(ctx context.Context, id primitive.ObjectID) (models.User, error)
+	GetByUsername(ctx context.Context, username string) (models.User, error)
+	GetByEmail(ctx context.Context, email string) (models.User, error)
+	ExistsByUsernameOrEmail(ctx context.Context, username, email string) (bool, error)
+	// Create dan UpdateProfile mengembalikan ErrDuplicate jika username atau
+	// email sudah dipakai user lain
+	Create(ctx context.Context, user *models.User) error
+	// UpdatePassword juga menghapus tanda MustResetPassword
+	UpdatePassword(ctx context.Context, id primitive.ObjectID, hash string) error
+	SetEmailVerified(ctx context.Context, id primitive.ObjectID) error
+	// UpdateProfile mengganti username dan email. Status verifikasi email
+	// ikut disimpan karena email baru harus diverifikasi ulang.
+	UpdateProfile(ctx context.Context, id primitive.ObjectID, username, email string, emailVerified bool) error
+	SetRole(ctx context.Context, id primitive.ObjectID, role string) error
+	CountByRole(ctx context.Context, role string) (int64, error)
+	SetDisabled(ctx context.Context, id primitive.ObjectID, disabled bool) error
+	SetMustResetPassword(ctx context.Context, id primitive.ObjectID, must bool) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// SetTOTP mengganti seluruh pengaturan two-factor user
+	SetTOTP(ctx context.Context, id primitive.ObjectID, totp models.TOTP) error
+	// UseTOTPStep mencatat periode kode TOTP yang dipakai secara atomik.
+	// ErrNotFound berarti periode tersebut (atau yang lebih baru) sudah dipakai.
+	UseTOTPStep(ctx context.Context, id primitive.ObjectID, step int64) error
+	// UseRecoveryCode menghapus hash recovery code secara atomik.
+	// ErrNotFound berarti kode tidak ada atau sudah dipakai.
+	UseRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string) error
+
+	GetByOIDC(ctx context.Context, issuer, subject string) (models.User, error)
+	// SetOIDC mengembalikan ErrDuplicate jika identitas sudah terhubung ke
+	// user lain
+	SetOIDC(ctx context.Context, id primitive.ObjectID, identity models.ExternalIdentity) error
+}
+
+type BookingStore interface {
+	Create(ctx context.Context, booking *models.Booking) error
+	// ListByJadwal mengembalikan booking milik userID, atau semua booking
+	// jadwal tersebut jika userID kosong
+	ListByJadwal(ctx context.Context, jadwalID, userID primitive.ObjectID) ([]models.Booking, error)
+	// Cancel mengubah booking yang masih confirmed menjadi cancelled secara
+	// atomik dan mengembalikan booking sebelum dibatalkan. userID kosong
+	// berarti booking milik siapa pun boleh dibatalkan.
+	Cancel(ctx context.Context, id, jadwalID, userID primitive.ObjectID) (models.Booking, error)
+	// Restore mengembalikan booking yang dibatalkan menjadi confirmed,
+	// dipakai jika kursinya gagal dikembalikan setelah Cancel
+	Restore(ctx context.Context, id primitive.ObjectID) error
+}
+
+type JadwalTemplateStore interface {
+	All(ctx context.Context) ([]models.JadwalTemplate, error)
+	ListActive(ctx context.Context) ([]models.JadwalTemplate, error)
+	Get(ctx context.Context, id primitive.ObjectID) (models.JadwalTemplate, error)
+	Create(ctx context.Context, tpl *models.JadwalTemplate) error
+	Replace(ctx context.Context, tpl models.JadwalTemplate) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type SessionStore interface {
+	Create(ctx context.Context, session *models.Session) error
+	Get(ctx context.Context, id primitive.ObjectID) (models.Session, error)
+	// Rotate mengganti hash refresh token secara atomik hanya jika hash lama
+	// masih cocok dan session masih aktif. ErrNotFound berarti tidak ada
+	// session yang cocok, misalnya karena token lama sudah pernah dipakai.
+	Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, expiresAt time.Time) error
+	Revoke(ctx context.Context, id primitive.ObjectID, reason string) error
+	RevokeAllForUser(ctx context.Context, userID primitive.ObjectID, reason string) error
+}
+
+type UserTokenStore interface {
+	Create(ctx context.Context, token *models.UserToken) error
+	// Consume menandai token dengan hash dan tujuan tersebut sebagai sudah
+	// dipakai secara atomik. ErrNotFound berarti token tidak ada, sudah
+	// dipakai atau kedaluwarsa.
+	Consume(ctx context.Context, purpose, hash string) (models.UserToken, error)
+	// DeleteForUser menghapus token user yang belum dipakai untuk tujuan
+	// tersebut, dipakai agar hanya token terbaru yang berlaku
+	DeleteForUser(ctx context.Context, userID primitive.ObjectID, purpose string) error
+	// Latest mengembalikan token terbaru user untuk tujuan tersebut
+	Latest(ctx context.Context, userID primitive.ObjectID, purpose string) (models.UserToken, error)
+}
+
+type RoleStore interface {
+	All(ctx context.Context) ([]models.Role, error)
+	GetByName(ctx context.Context, name string) (models.Role, error)
+	Create(ctx context.Context, role *models.Role) error
+	// CreateIfMissing menyimpan role hanya jika belum ada role dengan nama
+	// yang sama. true berarti role baru dibuat.
+	CreateIfMissing(ctx context.Context, role models.Role) (bool, error)
+	Update(ctx context.Context, role models.Role) error
+	Delete(ctx context.Context, name string) error
+}
+
+// LoginAttemptStore menyimpan hitungan login gagal. Implementasi MongoDB
+// dipakai jika aplikasi berjalan di lebih dari satu instance.
+type LoginAttemptStore interface {
+	// Get mengembalikan ErrNotFound jika kunci belum pernah gagal atau
+	// hitungannya sudah kedaluwarsa
+	Get(ctx context.Context, key string) (models.LoginAttempt, error)
+	// RecordFailure menambah hitungan gagal secara atomik dan memperpanjang
+	// masa berlaku hitungan sampai now+ttl
+	RecordFailure(ctx context.Context, key string, now time.Time, ttl time.Duration) (models.LoginAttempt, error)
+	// Attempt menghitung satu percobaan sebagai gagal sebelum hasilnya
+	// diketahui dan sekaligus mengunci kunci selama lockFor(failures), secara
+	// atomik dan hanya jika kunci tidak sedang dikunci, sehingga percobaan
+	// bersamaan tidak bisa sama-sama lolos pengecekan. ErrLocked dikembalikan
+	// bersama data kunci jika sedang dikunci.
+	Attempt(ctx context.Context, key string, now time.Time, ttl time.Duration, lockFor func(failures int) time.Duration) (models.LoginAttempt, error)
+	// Forgive mengurangi hitungan gagal satu kali untuk percobaan yang
+	// ternyata berhasil
+	Forgive(ctx context.Context, key string) error
+	Reset(ctx context.Context, key string) error
+}
+
+type SigningKeyStore interface {
+	// List mengembalikan kunci yang belum melewati RetireAt pada waktu now
+	List(ctx context.Context, now time.Time) ([]models.SigningKey, error)
+	Create(ctx context.Context, key *models.SigningKey) error
+}
+
+// LockStore menyimpan kunci berbatas waktu untuk menyerialkan operasi
+// cek-lalu-tulis antar request dan antar instance aplikasi. Kunci yang
+// kedaluwarsa dianggap lepas sehingga instance yang mati tidak menahannya.
+type LockStore interface {
+	// Acquire mengambil kunci sampai until jika kunci belum dipegang, sudah
+	// kedaluwarsa atau dipegang owner yang sama. ErrLocked berarti kunci
+	// masih dipegang owner lain.
+	Acquire(ctx context.Context, key, owner string, until time.Time) error
+	// Release melepas kunci hanya jika masih dipegang owner
+	Release(ctx context.Context, key, owner string) error
+}
+
+type APIKeyStore interface {
+	// List mengembalikan semua key termasuk yang sudah dicabut, terbaru dulu
+	List(ctx context.Context) ([]models.APIKey, error)
+	Create(ctx context.Context, key *models.APIKey) error
+	GetByHash(ctx context.Context, hash string) (models.APIKey, error)
+	// Revoke mencabut key yang belum dicabut. ErrNotFound berarti key tidak
+	// ada atau sudah dicabut.
+	Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	TouchLastUsed(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+// Stores mengumpulkan semua store yang dibutuhkan handler
+type Stores struct {
+	Rute           RuteStore
+	Halte          HalteStore
+	Kendaraan      KendaraanStore
+	Jadwal         JadwalStore
+	User           UserStore
+	Booking        BookingStore
+	JadwalTemplate JadwalTemplateStore
+	Session        SessionStore
+	UserToken      UserTokenStore
+	Role           RoleStore
+	LoginAttempt   LoginAttemptStore
+	SigningKey     SigningKeyStore
+	APIKey         APIKeyStore
+	Lock           LockStore
+}
+
+// indexer diimplementasikan store yang membutuhkan index di database
+type indexer interface {
+	EnsureIndexes(ctx context.Context) error
+}
+
+// EnsureIndexes membuat index untuk setiap store yang membutuhkannya
+func EnsureIndexes(ctx context.Context, s *Stores) error {
+	for _, candidate := range []interface{}{s.Rute, s.Halte, s.Kendaraan, s.Jadwal, s.User, s.Booking, s.JadwalTemplate, s.Session, s.UserToken, s.Role, s.LoginAttempt, s.SigningKey, s.APIKey, s.Lock} {
+		if idx, ok := candidate.(indexer); ok {
+			if err := idx.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// nextAttempt menambah satu percobaan gagal pada current dan menghitung
+// kuncinya. current kosong berarti hitungan mulai dari nol.
+func nextAttempt(current models.LoginAttempt, key string, now time.Time, ttl time.Duration, lockFor func(failures int) time.Duration) models.LoginAttempt {
+	next := current
+	next.Key = key
+	next.Failures++
+	next.LastFailure = now
+	if expires := now.Add(ttl); expires.After(next.ExpiresAt) {
+		next.ExpiresAt = expires
+	}
+	if d := lockFor(next.Failures); d > 0 {
+		next.LockedUntil = now.Add(d)
+		if next.LockedUntil.After(next.ExpiresAt) {
+			next.ExpiresAt = next.LockedUntil
+		}
+	}
+	return next
+}
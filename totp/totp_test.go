@@ -0,0 +1,57 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret adalah kunci SHA1 "12345678901234567890" dari lampiran B
+// RFC 6238
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// TestCodeRFC6238 memakai vektor uji lampiran B RFC 6238. Vektornya 8 digit,
+// kode 6 digit adalah 6 digit terakhirnya.
+func TestCodeRFC6238(t *testing.T) {
+	for _, tc := range []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	} {
+		want := tc.code[len(tc.code)-Digits:]
+		got, err := Code(rfc6238Secret, Step(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("T=%d: kode %s, seharusnya %s", tc.unix, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, err := Code(rfc6238Secret, Step(now)-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if step, ok := Validate(rfc6238Secret, code, now, 1); !ok || step != Step(now)-1 {
+		t.Fatalf("kode periode sebelumnya ditolak dengan skew 1: %d %v", step, ok)
+	}
+	if _, ok := Validate(rfc6238Secret, code, now, 0); ok {
+		t.Fatal("kode periode sebelumnya diterima tanpa skew")
+	}
+	if _, ok := Validate(rfc6238Secret, code[:3]+" "+code[3:], now, 1); !ok {
+		t.Fatal("kode dengan spasi ditolak")
+	}
+	if _, ok := Validate(rfc6238Secret, "12345", now, 1); ok {
+		t.Fatal("kode yang terlalu pendek diterima")
+	}
+}